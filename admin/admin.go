@@ -0,0 +1,126 @@
+// Package admin is implements the admin and debug http endpoints of runtime insight.
+// # This manifest was generated by ymir. DO NOT EDIT.
+package admin
+
+import (
+	"errors"
+	"net/http"
+	"net/http/pprof"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog"
+
+	"github.com/kubuskotak/asgard/config"
+	"github.com/kubuskotak/asgard/rest"
+)
+
+// ErrBuildInfoNotAvailable is define error when the binary is not built with module support.
+var ErrBuildInfoNotAvailable = errors.New("build info is not available")
+
+// Admin is the admin router, it serves:
+//   - GET /debug/pprof/ and /debug/pprof/{profile}, the pprof profiles;
+//   - GET /debug/runtime, the goroutines, memory and GC stats;
+//   - GET /debug/build, the build info of binary;
+//   - GET /debug/config, the redacted config when WithConfig is given;
+//   - GET and PUT /debug/log-level, the zerolog global level.
+type Admin struct {
+	router  *rest.Router
+	scheme  string
+	auth    func(next http.Handler) http.Handler
+	config  any
+	pprof   bool
+	started time.Time
+}
+
+// New creates the admin router, the authentication is required, e.g. WithBasicAuth.
+func New(opts ...Option) *Admin {
+	a := &Admin{pprof: true, started: time.Now()}
+	for _, opt := range opts {
+		if err := opt(a); err != nil {
+			panic(err)
+		}
+	}
+	if a.auth == nil {
+		panic(errors.New("admin: auth is required"))
+	}
+	a.router = rest.NewRouter(rest.WithAuth(a.scheme, a.auth), rest.WithTags("admin"))
+	a.routes()
+	return a
+}
+
+// NewServer creates the server of admin router on separate port, 6060 by default.
+// The write timeout is 60 seconds for the cpu profile and trace, opts override them.
+func NewServer(a *Admin, opts ...rest.Option) *rest.Server {
+	defaults := []rest.Option{rest.WithPort("6060"), rest.WithWriteTimeout(60)}
+	srv := rest.NewServer(append(defaults, opts...)...)
+	srv.Handler(a)
+	return srv
+}
+
+// ServeHTTP serves the admin endpoints.
+func (a *Admin) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	a.router.ServeHTTP(w, r)
+}
+
+// Routes returns the metadata of admin routes.
+func (a *Admin) Routes() []rest.RouteInfo {
+	return a.router.Routes()
+}
+
+// LogLevel holds the zerolog global level.
+type LogLevel struct {
+	Level string `json:"level" validate:"required"`
+}
+
+// RequestEmpty request of admin endpoints without input.
+type RequestEmpty struct{}
+
+func (a *Admin) routes() {
+	if a.pprof {
+		a.router.Handle(http.MethodGet, "/debug/pprof/", http.HandlerFunc(pprof.Index), rest.WithSummary("pprof index"))
+		a.router.Handle(http.MethodGet, "/debug/pprof/cmdline", http.HandlerFunc(pprof.Cmdline), rest.WithSummary("pprof cmdline"))
+		a.router.Handle(http.MethodGet, "/debug/pprof/profile", http.HandlerFunc(pprof.Profile), rest.WithSummary("pprof cpu profile"))
+		a.router.Handle(http.MethodGet, "/debug/pprof/symbol", http.HandlerFunc(pprof.Symbol), rest.WithSummary("pprof symbol"))
+		a.router.Handle(http.MethodPost, "/debug/pprof/symbol", http.HandlerFunc(pprof.Symbol), rest.WithSummary("pprof symbol"))
+		a.router.Handle(http.MethodGet, "/debug/pprof/trace", http.HandlerFunc(pprof.Trace), rest.WithSummary("pprof trace"))
+		a.router.Handle(http.MethodGet, "/debug/pprof/{profile}", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			pprof.Handler(chi.URLParam(r, "profile")).ServeHTTP(w, r)
+		}), rest.WithSummary("pprof named profile"))
+	}
+	rest.Get[RequestEmpty, RuntimeStats](a.router, "/debug/runtime",
+		func(w http.ResponseWriter, r *http.Request) (RuntimeStats, error) {
+			return ReadRuntimeStats(a.started), nil
+		}, rest.WithSummary("runtime stats"))
+	rest.Get[RequestEmpty, BuildInfo](a.router, "/debug/build",
+		func(w http.ResponseWriter, r *http.Request) (BuildInfo, error) {
+			info, ok := ReadBuildInfo()
+			if !ok {
+				return info, rest.ErrNotFound(w, r, ErrBuildInfoNotAvailable)
+			}
+			return info, nil
+		}, rest.WithSummary("build info"))
+	if a.config != nil {
+		rest.Get[RequestEmpty, any](a.router, "/debug/config",
+			func(w http.ResponseWriter, r *http.Request) (any, error) {
+				return config.Redacted(a.config), nil
+			}, rest.WithSummary("redacted config"))
+	}
+	rest.Get[RequestEmpty, LogLevel](a.router, "/debug/log-level",
+		func(w http.ResponseWriter, r *http.Request) (LogLevel, error) {
+			return LogLevel{Level: zerolog.GlobalLevel().String()}, nil
+		}, rest.WithSummary("log level"))
+	rest.Put[LogLevel, LogLevel](a.router, "/debug/log-level",
+		func(w http.ResponseWriter, r *http.Request) (LogLevel, error) {
+			req, err := rest.GetBind[LogLevel](r)
+			if err != nil {
+				return LogLevel{}, rest.ErrBadRequest(w, r, err)
+			}
+			level, err := zerolog.ParseLevel(req.Level)
+			if err != nil {
+				return LogLevel{}, rest.ErrBadRequest(w, r, err)
+			}
+			zerolog.SetGlobalLevel(level)
+			return LogLevel{Level: level.String()}, nil
+		}, rest.WithSummary("change log level"))
+}
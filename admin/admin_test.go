@@ -0,0 +1,39 @@
+package admin
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kubuskotak/asgard/config"
+	"github.com/kubuskotak/asgard/rest/resttest"
+)
+
+func TestAdmin(t *testing.T) {
+	is := assert.New(t)
+	defer zerolog.SetGlobalLevel(zerolog.GlobalLevel())
+	cfg := struct {
+		Name   string `yaml:"name"`
+		Secret string `yaml:"secret" secret:"true"`
+	}{Name: "laugh-tale", Secret: "sekret"}
+	h := resttest.New(t, New(WithBearerToken("token"), WithConfig(&cfg)))
+
+	h.Get("/debug/runtime").Do().Status(http.StatusUnauthorized)
+
+	stats := resttest.Data[RuntimeStats](h.Get("/debug/runtime").Bearer("token").Do().Status(http.StatusOK))
+	is.Positive(stats.Goroutines)
+
+	conf := resttest.Data[map[string]string](h.Get("/debug/config").Bearer("token").Do().Status(http.StatusOK))
+	is.Equal(map[string]string{"name": "laugh-tale", "secret": config.RedactedValue}, conf)
+
+	level := resttest.Data[LogLevel](h.Put("/debug/log-level").Bearer("token").
+		JSON(LogLevel{Level: "warn"}).Do().Status(http.StatusOK))
+	is.Equal("warn", level.Level)
+	is.Equal(zerolog.WarnLevel, zerolog.GlobalLevel())
+	h.Put("/debug/log-level").Bearer("token").JSON(LogLevel{Level: "loud"}).Do().Status(http.StatusBadRequest)
+
+	h.Get("/debug/pprof/goroutine").Query("debug", "1").Bearer("token").Do().Status(http.StatusOK)
+	is.Panics(func() { New() })
+}
@@ -0,0 +1,95 @@
+// Package admin is implements the admin and debug http endpoints of runtime insight.
+// # This manifest was generated by ymir. DO NOT EDIT.
+package admin
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/kubuskotak/asgard/rest"
+)
+
+// ErrUnauthorized is define error when the admin credential is invalid.
+var ErrUnauthorized = errors.New("admin credential is invalid")
+
+// Option is the option of Admin.
+type Option = func(a *Admin) error
+
+// WithAuth will protect the admin endpoints by authentication middleware, scheme is recorded on route metadata.
+func WithAuth(scheme string, mw func(next http.Handler) http.Handler) Option {
+	return func(a *Admin) error {
+		if mw == nil {
+			return errors.New("admin: auth middleware is required")
+		}
+		a.scheme, a.auth = scheme, mw
+		return nil
+	}
+}
+
+// WithBasicAuth will protect the admin endpoints by basic authentication.
+func WithBasicAuth(username, password string) Option {
+	if username == "" || password == "" {
+		return func(a *Admin) error {
+			return errors.New("admin: basic auth username and password are required")
+		}
+	}
+	return WithAuth("basic", func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, pass, ok := r.BasicAuth()
+			if !ok || !equal(user, username) || !equal(pass, password) {
+				w.Header().Set("WWW-Authenticate", `Basic realm="admin"`)
+				unauthorized(w, r)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	})
+}
+
+// WithBearerToken will protect the admin endpoints by static bearer token.
+func WithBearerToken(token string) Option {
+	if token == "" {
+		return func(a *Admin) error {
+			return errors.New("admin: bearer token is required")
+		}
+	}
+	return WithAuth("bearer", func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got, ok := strings.CutPrefix(r.Header.Get(rest.HeaderAuthorization.String()), "Bearer ")
+			if !ok || !equal(got, token) {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+				unauthorized(w, r)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	})
+}
+
+// WithConfig will expose the config loaded by config.Load, secret fields are redacted, see config.Redacted.
+func WithConfig(cfg any) Option {
+	return func(a *Admin) error {
+		a.config = cfg
+		return nil
+	}
+}
+
+// WithPprof will expose or hide the pprof endpoints, they are exposed by default.
+func WithPprof(enabled bool) Option {
+	return func(a *Admin) error {
+		a.pprof = enabled
+		return nil
+	}
+}
+
+func equal(got, want string) bool {
+	return subtle.ConstantTimeCompare([]byte(got), []byte(want)) == 1
+}
+
+func unauthorized(w http.ResponseWriter, r *http.Request) {
+	rest.HandlerAdapter[rest.RequestNotFound](func(w http.ResponseWriter, r *http.Request) (rest.ResponseNotFound, error) {
+		return rest.ResponseNotFound{}, rest.ErrUnauthorized(w, r, ErrUnauthorized)
+	}).JSON(w, r)
+}
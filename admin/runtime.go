@@ -0,0 +1,119 @@
+// Package admin is implements the admin and debug http endpoints of runtime insight.
+// # This manifest was generated by ymir. DO NOT EDIT.
+package admin
+
+import (
+	"runtime"
+	"runtime/debug"
+	"time"
+)
+
+// RuntimeStats holds the runtime insight of process.
+type RuntimeStats struct {
+	GoVersion  string      `json:"go_version"`
+	Goroutines int         `json:"goroutines"`
+	NumCPU     int         `json:"num_cpu"`
+	GOMAXPROCS int         `json:"gomaxprocs"`
+	Uptime     string      `json:"uptime"`
+	Memory     MemoryStats `json:"memory"`
+	GC         GCStats     `json:"gc"`
+}
+
+// MemoryStats holds the memory stats in bytes.
+type MemoryStats struct {
+	Alloc       uint64 `json:"alloc"`
+	TotalAlloc  uint64 `json:"total_alloc"`
+	Sys         uint64 `json:"sys"`
+	HeapAlloc   uint64 `json:"heap_alloc"`
+	HeapInuse   uint64 `json:"heap_inuse"`
+	HeapObjects uint64 `json:"heap_objects"`
+	StackInuse  uint64 `json:"stack_inuse"`
+	Mallocs     uint64 `json:"mallocs"`
+	Frees       uint64 `json:"frees"`
+}
+
+// GCStats holds the garbage collector stats.
+type GCStats struct {
+	NumGC       uint32    `json:"num_gc"`
+	LastGC      time.Time `json:"last_gc"`
+	NextGC      uint64    `json:"next_gc"`
+	PauseTotal  string    `json:"pause_total"`
+	CPUFraction float64   `json:"cpu_fraction"`
+}
+
+// ReadRuntimeStats returns the runtime stats, uptime is counted from started.
+func ReadRuntimeStats(started time.Time) RuntimeStats {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	stats := RuntimeStats{
+		GoVersion:  runtime.Version(),
+		Goroutines: runtime.NumGoroutine(),
+		NumCPU:     runtime.NumCPU(),
+		GOMAXPROCS: runtime.GOMAXPROCS(0),
+		Uptime:     time.Since(started).Round(time.Second).String(),
+		Memory: MemoryStats{
+			Alloc:       m.Alloc,
+			TotalAlloc:  m.TotalAlloc,
+			Sys:         m.Sys,
+			HeapAlloc:   m.HeapAlloc,
+			HeapInuse:   m.HeapInuse,
+			HeapObjects: m.HeapObjects,
+			StackInuse:  m.StackInuse,
+			Mallocs:     m.Mallocs,
+			Frees:       m.Frees,
+		},
+		GC: GCStats{
+			NumGC:       m.NumGC,
+			NextGC:      m.NextGC,
+			PauseTotal:  time.Duration(m.PauseTotalNs).String(),
+			CPUFraction: m.GCCPUFraction,
+		},
+	}
+	if m.LastGC > 0 {
+		stats.GC.LastGC = time.Unix(0, int64(m.LastGC)).UTC()
+	}
+	return stats
+}
+
+// BuildInfo holds the build info of binary.
+type BuildInfo struct {
+	GoVersion string            `json:"go_version"`
+	Path      string            `json:"path"`
+	Version   string            `json:"version"`
+	Settings  map[string]string `json:"settings"`
+	Deps      []Module          `json:"deps"`
+}
+
+// Module holds the module of build.
+type Module struct {
+	Path    string `json:"path"`
+	Version string `json:"version"`
+	Sum     string `json:"sum,omitempty"`
+	Replace string `json:"replace,omitempty"`
+}
+
+// ReadBuildInfo returns the build info from debug.ReadBuildInfo, e.g. vcs.revision and vcs.time settings.
+func ReadBuildInfo() (BuildInfo, bool) {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return BuildInfo{}, false
+	}
+	info := BuildInfo{
+		GoVersion: bi.GoVersion,
+		Path:      bi.Path,
+		Version:   bi.Main.Version,
+		Settings:  make(map[string]string, len(bi.Settings)),
+		Deps:      make([]Module, 0, len(bi.Deps)),
+	}
+	for _, s := range bi.Settings {
+		info.Settings[s.Key] = s.Value
+	}
+	for _, dep := range bi.Deps {
+		m := Module{Path: dep.Path, Version: dep.Version, Sum: dep.Sum}
+		if dep.Replace != nil {
+			m.Replace = dep.Replace.Path + "@" + dep.Replace.Version
+		}
+		info.Deps = append(info.Deps, m)
+	}
+	return info, true
+}
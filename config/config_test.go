@@ -79,3 +79,33 @@ func TestConfigFunc(t *testing.T) {
 	assert.Error(t, err)
 	assert.Equal(t, val, cfg.DB.DsnMain)
 }
+
+func TestRedacted(t *testing.T) {
+	is := assert.New(t)
+	type database struct {
+		Host     string `yaml:"host"`
+		Password string `yaml:"password" secret:"true"`
+		Token    string `yaml:"token" secret:"true"`
+	}
+	cfg := struct {
+		Name     string        `yaml:"name"`
+		Timeout  time.Duration `yaml:"timeout"`
+		Internal string        `yaml:"-"`
+		DB       *database     `yaml:"db"`
+		Replicas []database    `yaml:"replicas"`
+	}{
+		Name:     "laugh-tale",
+		Timeout:  time.Second,
+		Internal: "hidden",
+		DB:       &database{Host: "localhost", Password: "root123"},
+		Replicas: []database{{Host: "replica", Password: "root456"}},
+	}
+	is.Equal(map[string]any{
+		"name":    "laugh-tale",
+		"timeout": time.Second,
+		"db":      map[string]any{"host": "localhost", "password": RedactedValue, "token": ""},
+		"replicas": []any{
+			map[string]any{"host": "replica", "password": RedactedValue, "token": ""},
+		},
+	}, Redacted(&cfg))
+}
@@ -0,0 +1,116 @@
+// Package config handling setup of environment variables.
+// # This manifest was generated by ymir. DO NOT EDIT.
+package config
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// RedactedValue replaces the value of secret fields.
+const RedactedValue = "******"
+
+var (
+	jsonMarshaler = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshaler = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// Redacted returns the config loaded by Load as tree of maps keyed by `yaml` tag,
+// the non-empty values of fields tagged by `secret:"true"` are replaced with RedactedValue, e.g.
+//
+//	DB struct {
+//		Password string `yaml:"password" env:"DB_PASSWORD" secret:"true"`
+//	}
+func Redacted(cfg any) any {
+	return redact(reflect.ValueOf(cfg))
+}
+
+func redact(v reflect.Value) any {
+	if !v.IsValid() {
+		return nil
+	}
+	if v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		return redact(v.Elem())
+	}
+	if v.Type().Implements(jsonMarshaler) || v.Type().Implements(textMarshaler) {
+		return v.Interface()
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		out := make(map[string]any, v.NumField())
+		redactStruct(v, out)
+		return out
+	case reflect.Map:
+		out := make(map[string]any, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			out[fmt.Sprint(iter.Key().Interface())] = redact(iter.Value())
+		}
+		return out
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		out := make([]any, v.Len())
+		for i := range out {
+			out[i] = redact(v.Index(i))
+		}
+		return out
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return nil
+	default:
+		return v.Interface()
+	}
+}
+
+func redactStruct(v reflect.Value, out map[string]any) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, ok := fieldKey(field)
+		if !ok {
+			continue
+		}
+		value := v.Field(i)
+		switch {
+		case field.Tag.Get("secret") == "true":
+			if value.IsZero() {
+				out[name] = redact(value)
+			} else {
+				out[name] = RedactedValue
+			}
+		case field.Anonymous && name == field.Name && value.Kind() == reflect.Struct:
+			// embedded struct without tag is inlined.
+			redactStruct(value, out)
+		default:
+			out[name] = redact(value)
+		}
+	}
+}
+
+// fieldKey returns the key of field by `yaml` tag, `json` tag or the field name.
+func fieldKey(field reflect.StructField) (string, bool) {
+	for _, key := range []string{"yaml", "json"} {
+		tag, ok := field.Tag.Lookup(key)
+		if !ok {
+			continue
+		}
+		name := strings.SplitN(tag, ",", 2)[0]
+		if name == "-" {
+			return "", false
+		}
+		if name != "" {
+			return name, true
+		}
+	}
+	return field.Name, true
+}
@@ -0,0 +1,186 @@
+// Package grpcsrv is port adapter via gRPC protocol
+// # This manifest was generated by ymir. DO NOT EDIT.
+package grpcsrv
+
+import (
+	"context"
+	"reflect"
+	"runtime/debug"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/kubuskotak/asgard/security"
+	"github.com/kubuskotak/asgard/tracer"
+)
+
+// validator is implemented by messages generated with validation plugins.
+type validator interface {
+	Validate() error
+}
+
+// UnaryTracingInterceptor starts server span of every unary call.
+func UnaryTracingInterceptor() grpc.UnaryServerInterceptor {
+	return otelgrpc.UnaryServerInterceptor()
+}
+
+// StreamTracingInterceptor starts server span of every stream call.
+func StreamTracingInterceptor() grpc.StreamServerInterceptor {
+	return otelgrpc.StreamServerInterceptor()
+}
+
+// UnaryLoggingInterceptor logs every unary call with the trace context,
+// the logger is stored on the context, see zerolog.Ctx.
+func UnaryLoggingInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		logger := log.Hook(tracer.TraceContextHook(ctx))
+		resp, err := handler(logger.WithContext(ctx), req)
+		logCall(&logger, info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+// StreamLoggingInterceptor logs every stream call with the trace context,
+// the logger is stored on the stream context, see zerolog.Ctx.
+func StreamLoggingInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		logger := log.Hook(tracer.TraceContextHook(ss.Context()))
+		err := handler(srv, &wrappedStream{ServerStream: ss, ctx: logger.WithContext(ss.Context())})
+		logCall(&logger, info.FullMethod, start, err)
+		return err
+	}
+}
+
+func logCall(logger *zerolog.Logger, method string, start time.Time, err error) {
+	code := status.Code(err)
+	var event *zerolog.Event
+	switch code {
+	case codes.OK:
+		event = logger.Info()
+	case codes.Internal, codes.Unknown, codes.DataLoss, codes.Unavailable, codes.Unimplemented:
+		event = logger.Error().Err(err)
+	default:
+		event = logger.Warn().Err(err)
+	}
+	event.Str("grpc.method", method).
+		Str("grpc.code", code.String()).
+		Dur("grpc.duration", time.Since(start)).
+		Msg("gRPC call")
+}
+
+// UnaryRecoveryInterceptor recovers the handler panic into codes.Internal.
+func UnaryRecoveryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				err = recovered(ctx, info.FullMethod, p)
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// StreamRecoveryInterceptor recovers the handler panic into codes.Internal.
+func StreamRecoveryInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				err = recovered(ss.Context(), info.FullMethod, p)
+			}
+		}()
+		return handler(srv, ss)
+	}
+}
+
+func recovered(ctx context.Context, method string, p any) error {
+	logger := log.Hook(tracer.TraceContextHook(ctx))
+	logger.Error().
+		Interface("panic", p).
+		Bytes("stack", debug.Stack()).
+		Str("grpc.method", method).
+		Msg("gRPC panic")
+	return status.Error(codes.Internal, "internal error")
+}
+
+// UnaryValidationInterceptor validates the request by Validate method or security.Validate,
+// violations are answered with codes.InvalidArgument.
+func UnaryValidationInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := validate(req); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamValidationInterceptor validates every received message of the stream.
+func StreamValidationInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &validatingStream{ServerStream: ss})
+	}
+}
+
+func validate(req any) error {
+	if v, ok := req.(validator); ok {
+		if err := v.Validate(); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil
+	}
+	rv := reflect.ValueOf(req)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+	errs := security.Validate(req)
+	if len(errs) < 1 {
+		return nil
+	}
+	violations := make([]*errdetails.BadRequest_FieldViolation, 0, len(errs))
+	for _, e := range errs {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{
+			Field:       e.Field,
+			Description: e.Message,
+		})
+	}
+	st, err := status.New(codes.InvalidArgument, errs[0].Message).
+		WithDetails(&errdetails.BadRequest{FieldViolations: violations})
+	if err != nil {
+		return status.Error(codes.InvalidArgument, errs[0].Message)
+	}
+	return st.Err()
+}
+
+// wrappedStream overrides the stream context.
+type wrappedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context returns the overridden context.
+func (w *wrappedStream) Context() context.Context {
+	return w.ctx
+}
+
+// validatingStream validates every received message.
+type validatingStream struct {
+	grpc.ServerStream
+}
+
+// RecvMsg receives the message and validates it.
+func (v *validatingStream) RecvMsg(m any) error {
+	if err := v.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	return validate(m)
+}
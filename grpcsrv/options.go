@@ -0,0 +1,92 @@
+// Package grpcsrv is port adapter via gRPC protocol
+// # This manifest was generated by ymir. DO NOT EDIT.
+package grpcsrv
+
+import (
+	"crypto/tls"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// Option is server type return func.
+type Option = func(s *Server) error
+
+// WithHost will assign to host field server.
+func WithHost(host string) Option {
+	return func(s *Server) error {
+		s.Host = host
+		return nil
+	}
+}
+
+// WithPort will assign to port field server.
+func WithPort(port string) Option {
+	return func(s *Server) error {
+		s.Port = port
+		return nil
+	}
+}
+
+// WithTLS will load the certificate and key files to serve over TLS.
+func WithTLS(certFile, keyFile string) Option {
+	return func(s *Server) error {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return err
+		}
+		s.tlsConfig = &tls.Config{
+			Certificates: []tls.Certificate{cert},
+			MinVersion:   tls.VersionTLS12,
+		}
+		return nil
+	}
+}
+
+// WithTLSConfig will assign the TLS configuration, e.g. to require client certificates.
+func WithTLSConfig(cfg *tls.Config) Option {
+	return func(s *Server) error {
+		s.tlsConfig = cfg
+		return nil
+	}
+}
+
+// WithReflection will enable or disable the reflection service, it is disabled by default.
+func WithReflection(enabled bool) Option {
+	return func(s *Server) error {
+		s.reflection = enabled
+		return nil
+	}
+}
+
+// WithUnaryInterceptor will append the unary interceptors after the default interceptors.
+func WithUnaryInterceptor(interceptors ...grpc.UnaryServerInterceptor) Option {
+	return func(s *Server) error {
+		s.unary = append(s.unary, interceptors...)
+		return nil
+	}
+}
+
+// WithStreamInterceptor will append the stream interceptors after the default interceptors.
+func WithStreamInterceptor(interceptors ...grpc.StreamServerInterceptor) Option {
+	return func(s *Server) error {
+		s.stream = append(s.stream, interceptors...)
+		return nil
+	}
+}
+
+// WithServerOption will assign the additional grpc server options, e.g. keepalive parameters.
+func WithServerOption(opts ...grpc.ServerOption) Option {
+	return func(s *Server) error {
+		s.serverOptions = append(s.serverOptions, opts...)
+		return nil
+	}
+}
+
+// WithShutdownTimeout will assign to shut down timeout field server.
+func WithShutdownTimeout(seconds int) Option {
+	return func(s *Server) error {
+		s.shutdownTimeOut = time.Duration(seconds) * time.Second
+		return nil
+	}
+}
@@ -0,0 +1,177 @@
+// Package grpcsrv is port adapter via gRPC protocol
+// # This manifest was generated by ymir. DO NOT EDIT.
+package grpcsrv
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+var (
+	// ErrServerNotStarted is define error when server not started.
+	ErrServerNotStarted = errors.New("server not started")
+	// ErrServerAlreadyStarted is define error when server already started.
+	ErrServerAlreadyStarted = errors.New("server already started")
+)
+
+// Server is the odin gRPC server.
+type Server struct {
+	errCh chan error
+	Host  string
+	Port  string
+
+	server   *grpc.Server
+	health   *health.Server
+	listener net.Listener
+	started  bool
+	mu       sync.Mutex
+	services []string
+
+	tlsConfig       *tls.Config
+	reflection      bool
+	unary           []grpc.UnaryServerInterceptor
+	stream          []grpc.StreamServerInterceptor
+	serverOptions   []grpc.ServerOption
+	shutdownTimeOut time.Duration
+}
+
+// NewServer creates a server with tracing, logging, recovery and validation interceptors,
+// the health service is registered, the reflection service is registered by WithReflection.
+func NewServer(opts ...Option) *Server {
+	s := &Server{
+		errCh:           make(chan error, 1),
+		Host:            "",
+		Port:            "9090",
+		shutdownTimeOut: 10 * time.Second,
+	}
+
+	for _, opt := range opts {
+		err := opt(s)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	unary := append([]grpc.UnaryServerInterceptor{
+		UnaryTracingInterceptor(),
+		UnaryLoggingInterceptor(),
+		UnaryRecoveryInterceptor(),
+		UnaryValidationInterceptor(),
+	}, s.unary...)
+	stream := append([]grpc.StreamServerInterceptor{
+		StreamTracingInterceptor(),
+		StreamLoggingInterceptor(),
+		StreamRecoveryInterceptor(),
+		StreamValidationInterceptor(),
+	}, s.stream...)
+	serverOptions := append([]grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.ChainStreamInterceptor(stream...),
+	}, s.serverOptions...)
+	if s.tlsConfig != nil {
+		serverOptions = append(serverOptions, grpc.Creds(credentials.NewTLS(s.tlsConfig)))
+	}
+	s.server = grpc.NewServer(serverOptions...)
+	s.health = health.NewServer()
+	healthpb.RegisterHealthServer(s.server, s.health)
+	if s.reflection {
+		reflection.Register(s.server)
+	}
+
+	return s
+}
+
+// RegisterService implements grpc.ServiceRegistrar, the generated RegisterXxxServer funcs accept the server.
+func (s *Server) RegisterService(desc *grpc.ServiceDesc, impl any) {
+	s.server.RegisterService(desc, impl)
+	s.mu.Lock()
+	s.services = append(s.services, desc.ServiceName)
+	s.mu.Unlock()
+}
+
+// SetServingStatus sets the health status of service, empty service is the overall server status.
+func (s *Server) SetServingStatus(service string, status healthpb.HealthCheckResponse_ServingStatus) {
+	s.health.SetServingStatus(service, status)
+}
+
+// Addr returns the listener address, it is nil before the server is started.
+func (s *Server) Addr() net.Addr {
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.Addr()
+}
+
+// ListenAndServe will run the server.
+func (s *Server) ListenAndServe() error {
+	if s.started {
+		return ErrServerAlreadyStarted
+	}
+	lis, err := net.Listen("tcp", fmt.Sprintf("%s:%s", s.Host, s.Port))
+	if err != nil {
+		return err
+	}
+	s.listener = lis
+
+	s.mu.Lock()
+	for _, service := range s.services {
+		s.health.SetServingStatus(service, healthpb.HealthCheckResponse_SERVING)
+	}
+	s.mu.Unlock()
+	s.health.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+
+	go func() {
+		s.errCh <- s.server.Serve(lis)
+	}()
+	s.started = true
+	return nil
+}
+
+// Error is return channel for capture error.
+func (s *Server) Error() chan error {
+	return s.errCh
+}
+
+// Stop will close the server.
+func (s *Server) Stop() {
+	s.health.Shutdown()
+	s.server.Stop()
+}
+
+// Quite will shutdown the server, in-flight calls are finished before the shutdown timeout.
+func (s *Server) Quite(ctx context.Context) error {
+	if !s.started {
+		return ErrServerNotStarted
+	}
+	// Do not make the application hang when it is shutdown.
+	ctxOut, cancel := context.WithTimeout(ctx, s.shutdownTimeOut)
+	defer cancel()
+
+	log.Info().Msg("Stopping gRPC server gracefully")
+	s.health.Shutdown()
+	done := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctxOut.Done():
+		log.Error().Err(ctxOut.Err()).Msg("Wait is over due to error")
+		s.server.Stop()
+	}
+	log.Info().Msgf("Stop gRPC server at %s", s.listener.Addr())
+	return nil
+}
@@ -0,0 +1,189 @@
+package grpcsrv
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// jsonCodec lets the test service use plain structs tagged by `validate` as messages.
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v any) ([]byte, error)   { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(b []byte, v any) error { return json.Unmarshal(b, v) }
+func (jsonCodec) Name() string                    { return "json" }
+
+type echoRequest struct {
+	Name string `json:"name" validate:"required"`
+}
+
+type echoResponse struct {
+	Name string `json:"name"`
+}
+
+// checkedRequest is validated by its Validate method.
+type checkedRequest struct {
+	Name string `json:"name"`
+}
+
+func (r *checkedRequest) Validate() error {
+	if r.Name == "admin" {
+		return errors.New("name is reserved")
+	}
+	return nil
+}
+
+func unaryMethod[Req any](name string, fn func(req *Req) (any, error)) grpc.MethodDesc {
+	return grpc.MethodDesc{
+		MethodName: name,
+		Handler: func(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+			req := new(Req)
+			if err := dec(req); err != nil {
+				return nil, err
+			}
+			info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/test.Echo/" + name}
+			return interceptor(ctx, req, info, func(ctx context.Context, req any) (any, error) {
+				return fn(req.(*Req))
+			})
+		},
+	}
+}
+
+var echoService = grpc.ServiceDesc{
+	ServiceName: "test.Echo",
+	HandlerType: (*any)(nil),
+	Methods: []grpc.MethodDesc{
+		unaryMethod("Echo", func(req *echoRequest) (any, error) {
+			return &echoResponse{Name: req.Name}, nil
+		}),
+		unaryMethod("Checked", func(req *checkedRequest) (any, error) {
+			return &echoResponse{Name: req.Name}, nil
+		}),
+		unaryMethod("Panic", func(req *echoRequest) (any, error) {
+			panic("boom")
+		}),
+	},
+	Streams: []grpc.StreamDesc{{
+		StreamName:    "Stream",
+		ServerStreams: true,
+		ClientStreams: true,
+		Handler: func(srv any, stream grpc.ServerStream) error {
+			for {
+				req := &echoRequest{}
+				if err := stream.RecvMsg(req); errors.Is(err, io.EOF) {
+					return nil
+				} else if err != nil {
+					return err
+				}
+				if req.Name == "panic" {
+					panic("boom")
+				}
+				if err := stream.SendMsg(&echoResponse{Name: req.Name}); err != nil {
+					return err
+				}
+			}
+		},
+	}},
+}
+
+func dial(t *testing.T, s *Server) *grpc.ClientConn {
+	lis := bufconn.Listen(1 << 20)
+	go func() {
+		_ = s.server.Serve(lis)
+	}()
+	t.Cleanup(s.Stop)
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.ForceCodec(jsonCodec{})),
+	)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+	return conn
+}
+
+func TestInterceptors(t *testing.T) {
+	s := NewServer(WithServerOption(grpc.ForceServerCodec(jsonCodec{})))
+	s.RegisterService(&echoService, struct{}{})
+	conn := dial(t, s)
+	ctx := context.Background()
+
+	t.Run("unary", func(t *testing.T) {
+		is := assert.New(t)
+		resp := &echoResponse{}
+		is.NoError(conn.Invoke(ctx, "/test.Echo/Echo", &echoRequest{Name: "book"}, resp))
+		is.Equal("book", resp.Name)
+
+		err := conn.Invoke(ctx, "/test.Echo/Panic", &echoRequest{Name: "book"}, resp)
+		is.Equal(codes.Internal, status.Code(err))
+		is.Equal("internal error", status.Convert(err).Message())
+
+		err = conn.Invoke(ctx, "/test.Echo/Echo", &echoRequest{}, resp)
+		st := status.Convert(err)
+		is.Equal(codes.InvalidArgument, st.Code())
+		if is.Len(st.Details(), 1) {
+			badRequest, ok := st.Details()[0].(*errdetails.BadRequest)
+			is.True(ok)
+			if is.Len(badRequest.GetFieldViolations(), 1) {
+				is.Equal("name", badRequest.GetFieldViolations()[0].GetField())
+				is.Equal(st.Message(), badRequest.GetFieldViolations()[0].GetDescription())
+			}
+		}
+
+		err = conn.Invoke(ctx, "/test.Echo/Checked", &checkedRequest{Name: "admin"}, resp)
+		is.Equal(codes.InvalidArgument, status.Code(err))
+		is.Equal("name is reserved", status.Convert(err).Message())
+		is.NoError(conn.Invoke(ctx, "/test.Echo/Checked", &checkedRequest{Name: "book"}, resp))
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		is := assert.New(t)
+		desc := &grpc.StreamDesc{StreamName: "Stream", ServerStreams: true, ClientStreams: true}
+		open := func() grpc.ClientStream {
+			stream, err := conn.NewStream(ctx, desc, "/test.Echo/Stream")
+			is.NoError(err)
+			return stream
+		}
+
+		stream := open()
+		is.NoError(stream.SendMsg(&echoRequest{Name: "book"}))
+		resp := &echoResponse{}
+		is.NoError(stream.RecvMsg(resp))
+		is.Equal("book", resp.Name)
+		is.NoError(stream.SendMsg(&echoRequest{}))
+		err := stream.RecvMsg(resp)
+		is.Equal(codes.InvalidArgument, status.Code(err))
+		is.Len(status.Convert(err).Details(), 1)
+
+		stream = open()
+		is.NoError(stream.SendMsg(&echoRequest{Name: "panic"}))
+		err = stream.RecvMsg(resp)
+		is.Equal(codes.Internal, status.Code(err))
+	})
+}
+
+func TestReflection(t *testing.T) {
+	is := assert.New(t)
+	const service = "grpc.reflection.v1alpha.ServerReflection"
+	_, ok := NewServer().server.GetServiceInfo()[service]
+	is.False(ok, "reflection is opt-in")
+	_, ok = NewServer(WithReflection(true)).server.GetServiceInfo()[service]
+	is.True(ok)
+}
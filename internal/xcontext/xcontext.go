@@ -0,0 +1,27 @@
+// Package xcontext is implements the context helpers shared by the packages of asgard.
+// # This manifest was generated by ymir. DO NOT EDIT.
+package xcontext
+
+import (
+	"context"
+	"time"
+)
+
+// WithoutCancel returns a copy of parent which keeps its values without its deadline and cancellation,
+// as context.WithoutCancel of go 1.21 does.
+func WithoutCancel(parent context.Context) context.Context {
+	return withoutCancel{parent}
+}
+
+type withoutCancel struct {
+	context.Context
+}
+
+// Deadline returns no deadline.
+func (withoutCancel) Deadline() (time.Time, bool) { return time.Time{}, false }
+
+// Done returns nil channel, the context is never canceled.
+func (withoutCancel) Done() <-chan struct{} { return nil }
+
+// Err returns nil.
+func (withoutCancel) Err() error { return nil }
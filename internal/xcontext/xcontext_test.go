@@ -0,0 +1,24 @@
+package xcontext
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type ctxKey int
+
+func TestWithoutCancel(t *testing.T) {
+	is := assert.New(t)
+	parent, cancel := context.WithTimeout(context.WithValue(context.Background(), ctxKey(0), "tenant"), time.Millisecond)
+	cancel()
+	ctx := WithoutCancel(parent)
+	is.Error(parent.Err())
+	is.NoError(ctx.Err())
+	is.Nil(ctx.Done())
+	_, ok := ctx.Deadline()
+	is.False(ok)
+	is.Equal("tenant", ctx.Value(ctxKey(0)))
+}
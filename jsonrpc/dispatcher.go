@@ -0,0 +1,350 @@
+// Package jsonrpc is port adapter via JSON-RPC 2.0 over http/s protocol
+// # This manifest was generated by ymir. DO NOT EDIT.
+package jsonrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semConv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	otelTrace "go.opentelemetry.io/otel/trace"
+
+	"github.com/kubuskotak/asgard/rest"
+	"github.com/kubuskotak/asgard/security"
+)
+
+const (
+	tracerName = "otelJsonRpc"
+	// reservedPrefix is the method prefix reserved for rpc-internal methods.
+	reservedPrefix = "rpc."
+)
+
+// Option is dispatcher type return func.
+type Option = func(d *Dispatcher) error
+
+// WithMaxBatch will assign the maximum calls of a batch request.
+func WithMaxBatch(n int) Option {
+	return func(d *Dispatcher) error {
+		if n < 1 {
+			return errors.New("jsonrpc: max batch must be at least 1")
+		}
+		d.maxBatch = n
+		return nil
+	}
+}
+
+// WithBatchConcurrency will assign the maximum calls of a batch request running in parallel.
+func WithBatchConcurrency(n int) Option {
+	return func(d *Dispatcher) error {
+		if n < 1 {
+			return errors.New("jsonrpc: batch concurrency must be at least 1")
+		}
+		d.concurrency = n
+		return nil
+	}
+}
+
+// WithMaxBodySize will assign the maximum request body size in bytes.
+func WithMaxBodySize(n int64) Option {
+	return func(d *Dispatcher) error {
+		d.maxBodySize = n
+		return nil
+	}
+}
+
+// method is the typed adapter bound to raw params.
+type method func(w http.ResponseWriter, r *http.Request, params json.RawMessage) (any, error)
+
+// Dispatcher is http handler to dispatch JSON-RPC 2.0 calls into registered methods.
+type Dispatcher struct {
+	mu          sync.RWMutex
+	methods     map[string]method
+	maxBatch    int
+	concurrency int
+	maxBodySize int64
+	tracer      otelTrace.Tracer
+}
+
+// NewDispatcher creates a dispatcher, mount it on chi route e.g. r.Post("/rpc", d.ServeHTTP).
+func NewDispatcher(opts ...Option) *Dispatcher {
+	d := &Dispatcher{
+		methods:     make(map[string]method),
+		maxBatch:    100,
+		concurrency: 8,
+		maxBodySize: 1 << 20, // 1 MB
+		tracer:      otel.Tracer(tracerName, otelTrace.WithInstrumentationVersion("v1.0.0")),
+	}
+	for _, opt := range opts {
+		if err := opt(d); err != nil {
+			panic(err)
+		}
+	}
+	return d
+}
+
+// Register registers the adapter as JSON-RPC method. Params are decoded into Request,
+// validated by security.Validate and stored on the request context, see rest.GetBind.
+// Status code set by rest.ErrX helpers is mapped into JSON-RPC error code, see StatusCode.
+func Register[Request rest.RequestConstraint, Response rest.ResponseConstraint](
+	d *Dispatcher, name string, a rest.Adapter[Request, Response]) {
+	if name == "" || strings.HasPrefix(name, reservedPrefix) {
+		panic(fmt.Sprintf("jsonrpc: method name %q is reserved", name))
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, ok := d.methods[name]; ok {
+		panic(fmt.Sprintf("jsonrpc: method %q is already registered", name))
+	}
+	d.methods[name] = func(w http.ResponseWriter, r *http.Request, params json.RawMessage) (any, error) {
+		var req Request
+		if err := decodeParams(params, &req); err != nil {
+			return nil, NewError(CodeInvalidParams, err.Error(), nil)
+		}
+		if isStruct(req) {
+			if errs := security.Validate(&req); len(errs) > 0 {
+				return nil, NewError(CodeInvalidParams, errs[0].Message, ErrorData{
+					Status: http.StatusBadRequest,
+					Errors: errs,
+				})
+			}
+		}
+		*r = *r.WithContext(context.WithValue(r.Context(), rest.CtxPayloadRequest, req))
+		return a(w, r)
+	}
+}
+
+// decodeParams decodes by-name params into v, by-position params must hold exactly one element.
+func decodeParams(params json.RawMessage, v any) error {
+	params = bytes.TrimSpace(params)
+	if len(params) == 0 || bytes.Equal(params, []byte("null")) {
+		return nil
+	}
+	if params[0] == '[' {
+		var positional []json.RawMessage
+		if err := json.Unmarshal(params, &positional); err != nil {
+			return err
+		}
+		if len(positional) != 1 {
+			return fmt.Errorf("by-position params must hold 1 element, got %d", len(positional))
+		}
+		params = positional[0]
+	}
+	return json.Unmarshal(params, v)
+}
+
+func isStruct(v any) bool {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t != nil && t.Kind() == reflect.Struct
+}
+
+// ServeHTTP implements http.Handler, single and batch calls are answered with status 200,
+// notifications only are answered with status 204.
+func (d *Dispatcher) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, d.maxBodySize+1))
+	if err != nil {
+		writeResponse(w, http.StatusOK, errorResponse(nil, NewError(CodeParseError, err.Error(), nil)))
+		return
+	}
+	if int64(len(body)) > d.maxBodySize {
+		writeResponse(w, http.StatusRequestEntityTooLarge,
+			errorResponse(nil, NewError(CodeInvalidRequest, "request body is too large", nil)))
+		return
+	}
+	body = bytes.TrimSpace(body)
+	// the malformed JSON is parse error, the well-formed body which is not a request is invalid.
+	var raw json.RawMessage
+	if err = json.Unmarshal(body, &raw); err != nil {
+		writeResponse(w, http.StatusOK, errorResponse(nil, NewError(CodeParseError, err.Error(), nil)))
+		return
+	}
+	if body[0] == '[' {
+		var batch []json.RawMessage
+		if err = json.Unmarshal(body, &batch); err != nil {
+			writeResponse(w, http.StatusOK, errorResponse(nil, NewError(CodeParseError, err.Error(), nil)))
+			return
+		}
+		switch {
+		case len(batch) == 0:
+			writeResponse(w, http.StatusOK, errorResponse(nil, NewError(CodeInvalidRequest, "batch is empty", nil)))
+			return
+		case len(batch) > d.maxBatch:
+			writeResponse(w, http.StatusOK, errorResponse(nil,
+				NewError(CodeInvalidRequest, fmt.Sprintf("batch exceeds %d calls", d.maxBatch), nil)))
+			return
+		}
+		responses := d.batch(r, batch)
+		if len(responses) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		writeResponse(w, http.StatusOK, responses)
+		return
+	}
+
+	var req Request
+	if err = json.Unmarshal(body, &req); err != nil {
+		writeResponse(w, http.StatusOK, errorResponse(nil, NewError(CodeInvalidRequest, err.Error(), nil)))
+		return
+	}
+	resp := d.call(r, &req)
+	if resp == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeResponse(w, http.StatusOK, resp)
+}
+
+// batch runs the calls in parallel bounded by the dispatcher concurrency, responses keep the call order.
+func (d *Dispatcher) batch(r *http.Request, batch []json.RawMessage) []*Response {
+	var (
+		wg        sync.WaitGroup
+		sem       = make(chan struct{}, d.concurrency)
+		responses = make([]*Response, len(batch))
+	)
+	for i := range batch {
+		var req Request
+		if err := json.Unmarshal(batch[i], &req); err != nil {
+			responses[i] = errorResponse(nil, NewError(CodeInvalidRequest, err.Error(), nil))
+			continue
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, req *Request) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			responses[i] = d.call(r, req)
+		}(i, &req)
+	}
+	wg.Wait()
+
+	result := responses[:0]
+	for _, resp := range responses {
+		if resp != nil {
+			result = append(result, resp)
+		}
+	}
+	return result
+}
+
+// call runs a single call in its own span, nil is returned for notification.
+func (d *Dispatcher) call(r *http.Request, req *Request) (resp *Response) {
+	ctx, span := d.tracer.Start(r.Context(), "jsonrpc "+req.Method,
+		otelTrace.WithSpanKind(otelTrace.SpanKindServer),
+		otelTrace.WithAttributes(
+			semConv.RPCSystemKey.String("jsonrpc"),
+			semConv.RPCMethod(req.Method),
+			semConv.RPCJsonrpcVersion(req.JSONRPC),
+		),
+	)
+	if req.ID != nil {
+		span.SetAttributes(semConv.RPCJsonrpcRequestID(string(req.ID)))
+	}
+	defer func() {
+		if rvr := recover(); rvr != nil {
+			log.Error().Interface("panic", rvr).Str("method", req.Method).Msg("jsonrpc")
+			resp = d.result(req, nil, NewError(CodeInternalError, "internal error", nil))
+		}
+		if resp != nil && resp.Error != nil {
+			span.SetAttributes(
+				semConv.RPCJsonrpcErrorCode(resp.Error.Code),
+				semConv.RPCJsonrpcErrorMessage(resp.Error.Message),
+			)
+			span.SetStatus(codes.Error, resp.Error.Message)
+		}
+		span.End()
+	}()
+
+	if req.JSONRPC != Version || req.Method == "" {
+		return errorResponse(idOrNull(req.ID), NewError(CodeInvalidRequest, "invalid request", nil))
+	}
+	d.mu.RLock()
+	m, ok := d.methods[req.Method]
+	d.mu.RUnlock()
+	if !ok {
+		return d.result(req, nil, NewError(CodeMethodNotFound, fmt.Sprintf("method %q is not found", req.Method), nil))
+	}
+
+	dw := &discardWriter{header: http.Header{}}
+	cr := r.Clone(ctx)
+	cr.Body = http.NoBody
+	payload, err := m(dw, cr, req.Params)
+	if err != nil {
+		status, _ := cr.Context().Value(rest.CtxStatusCode).(int)
+		if status == 0 {
+			status = dw.code
+		}
+		span.RecordError(err)
+		return d.result(req, nil, toError(status, err))
+	}
+	return d.result(req, payload, nil)
+}
+
+// result returns the response of call, nil is returned for notification.
+func (d *Dispatcher) result(req *Request, payload any, rpcErr *Error) *Response {
+	if req.IsNotification() {
+		return nil
+	}
+	if rpcErr != nil {
+		return errorResponse(req.ID, rpcErr)
+	}
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return errorResponse(req.ID, NewError(CodeInternalError, err.Error(), nil))
+	}
+	return &Response{JSONRPC: Version, Result: b, ID: req.ID}
+}
+
+func errorResponse(id json.RawMessage, rpcErr *Error) *Response {
+	return &Response{JSONRPC: Version, Error: rpcErr, ID: idOrNull(id)}
+}
+
+func idOrNull(id json.RawMessage) json.RawMessage {
+	if id == nil {
+		return json.RawMessage("null")
+	}
+	return id
+}
+
+func writeResponse(w http.ResponseWriter, code int, v any) {
+	w.Header().Set(rest.HeaderContentType.String(), rest.MIMEApplicationJSON.String())
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Error().Err(err).Msg("jsonrpc")
+	}
+}
+
+// discardWriter is the response writer of method call, the adapter writes are discarded
+// because the result is carried by JSON-RPC response.
+type discardWriter struct {
+	header http.Header
+	code   int
+}
+
+// Header returns the discarded header map.
+func (w *discardWriter) Header() http.Header { return w.header }
+
+// Write discards the bytes.
+func (w *discardWriter) Write(b []byte) (int, error) { return len(b), nil }
+
+// WriteHeader records the status code.
+func (w *discardWriter) WriteHeader(code int) {
+	if w.code == 0 {
+		w.code = code
+	}
+}
@@ -0,0 +1,95 @@
+// Package jsonrpc is port adapter via JSON-RPC 2.0 over http/s protocol
+// # This manifest was generated by ymir. DO NOT EDIT.
+package jsonrpc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Version is the JSON-RPC protocol version.
+const Version = "2.0"
+
+// Standard error codes of JSON-RPC 2.0 specification.
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+	// CodeServerError is the first code of implementation defined server errors, -32000 to -32099.
+	CodeServerError = -32000
+)
+
+// Request holds the request definition for the JSON-RPC call.
+// ID is nil for notification.
+type Request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	ID      json.RawMessage `json:"id,omitempty"`
+}
+
+// IsNotification reports whether the request does not expect a response.
+func (r *Request) IsNotification() bool {
+	return r.ID == nil
+}
+
+// Response holds the response definition for the JSON-RPC call.
+type Response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+	ID      json.RawMessage `json:"id"`
+}
+
+// Error holds the error definition for the JSON-RPC call.
+// The adapter may return *Error to answer with its own code.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    any    `json:"data,omitempty"`
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	return fmt.Sprintf("jsonrpc: %d %s", e.Code, e.Message)
+}
+
+// NewError creates JSON-RPC error with code and message.
+func NewError(code int, message string, data any) *Error {
+	return &Error{Code: code, Message: message, Data: data}
+}
+
+// ErrorData holds the error data of errors mapped from http status code.
+type ErrorData struct {
+	Status int `json:"status"`
+	Errors any `json:"errors,omitempty"`
+}
+
+// StatusCode returns the JSON-RPC error code of http status code set by rest.ErrX helpers.
+// 400 and 422 are invalid params, 5xx is internal error and
+// the other 4xx are server errors -32000 minus the distance from 400, e.g. 404 is -32004.
+func StatusCode(status int) int {
+	switch {
+	case status == http.StatusBadRequest, status == http.StatusUnprocessableEntity:
+		return CodeInvalidParams
+	case status > http.StatusBadRequest && status < http.StatusInternalServerError:
+		return CodeServerError - (status - http.StatusBadRequest)
+	}
+	return CodeInternalError
+}
+
+// toError converts the adapter error into JSON-RPC error.
+func toError(status int, err error) *Error {
+	var rpcErr *Error
+	if errors.As(err, &rpcErr) {
+		return rpcErr
+	}
+	if status < http.StatusBadRequest {
+		status = http.StatusInternalServerError
+	}
+	return NewError(StatusCode(status), err.Error(), ErrorData{Status: status})
+}
@@ -0,0 +1,113 @@
+package jsonrpc
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kubuskotak/asgard/rest"
+)
+
+type sumRequest struct {
+	A int `json:"a" validate:"required"`
+	B int `json:"b"`
+}
+
+type sumResponse struct {
+	Sum int `json:"sum"`
+}
+
+func newTestDispatcher() *Dispatcher {
+	d := NewDispatcher()
+	Register[sumRequest, sumResponse](d, "sum", func(w http.ResponseWriter, r *http.Request) (sumResponse, error) {
+		req, err := rest.GetBind[sumRequest](r)
+		if err != nil {
+			return sumResponse{}, err
+		}
+		if req.B < 0 {
+			return sumResponse{}, rest.ErrNotFound(w, r, errors.New("negative is not found"))
+		}
+		return sumResponse{Sum: req.A + req.B}, nil
+	})
+	return d
+}
+
+func serve(d *Dispatcher, body string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	d.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/rpc", strings.NewReader(body)))
+	return rec
+}
+
+func TestDispatcherCall(t *testing.T) {
+	is := assert.New(t)
+	d := newTestDispatcher()
+
+	rec := serve(d, `{"jsonrpc":"2.0","method":"sum","params":{"a":1,"b":2},"id":1}`)
+	is.Equal(http.StatusOK, rec.Code)
+	is.JSONEq(`{"jsonrpc":"2.0","result":{"sum":3},"id":1}`, rec.Body.String())
+
+	var resp Response
+	rec = serve(d, `{"jsonrpc":"2.0","method":"sum","params":{"b":2},"id":"x"}`)
+	is.NoError(json.Unmarshal(rec.Body.Bytes(), &resp))
+	is.Equal(CodeInvalidParams, resp.Error.Code)
+
+	rec = serve(d, `{"jsonrpc":"2.0","method":"sum","params":{"a":1,"b":-1},"id":2}`)
+	is.NoError(json.Unmarshal(rec.Body.Bytes(), &resp))
+	is.Equal(-32004, resp.Error.Code)
+
+	rec = serve(d, `{"jsonrpc":"2.0","method":"nope","id":3}`)
+	is.NoError(json.Unmarshal(rec.Body.Bytes(), &resp))
+	is.Equal(CodeMethodNotFound, resp.Error.Code)
+
+	rec = serve(d, `{"jsonrpc":`)
+	is.NoError(json.Unmarshal(rec.Body.Bytes(), &resp))
+	is.Equal(CodeParseError, resp.Error.Code)
+	is.Equal("null", string(resp.ID))
+
+	// the well-formed body which is not a request object is invalid request.
+	for _, body := range []string{`42`, `"x"`, `null`, `{"jsonrpc":"2.0","method":1,"id":4}`} {
+		resp = Response{}
+		rec = serve(d, body)
+		is.NoError(json.Unmarshal(rec.Body.Bytes(), &resp))
+		is.Equal(CodeInvalidRequest, resp.Error.Code, body)
+	}
+	rec = serve(d, ``)
+	resp = Response{}
+	is.NoError(json.Unmarshal(rec.Body.Bytes(), &resp))
+	is.Equal(CodeParseError, resp.Error.Code)
+
+	rec = serve(d, `{"jsonrpc":"2.0","method":"sum","params":{"a":1}}`)
+	is.Equal(http.StatusNoContent, rec.Code)
+	is.Empty(rec.Body.String())
+}
+
+func TestDispatcherBatch(t *testing.T) {
+	is := assert.New(t)
+	d := newTestDispatcher()
+
+	rec := serve(d, `[
+		{"jsonrpc":"2.0","method":"sum","params":[{"a":1,"b":1}],"id":1},
+		{"jsonrpc":"2.0","method":"sum","params":{"a":5}},
+		1,
+		{"jsonrpc":"2.0","method":"sum","params":{"a":2,"b":2},"id":2}
+	]`)
+	is.Equal(http.StatusOK, rec.Code)
+	is.JSONEq(`[
+		{"jsonrpc":"2.0","result":{"sum":2},"id":1},
+		{"jsonrpc":"2.0","error":{"code":-32600,"message":"json: cannot unmarshal number into Go value of type jsonrpc.Request"},"id":null},
+		{"jsonrpc":"2.0","result":{"sum":4},"id":2}
+	]`, rec.Body.String())
+
+	rec = serve(d, `[]`)
+	var resp Response
+	is.NoError(json.Unmarshal(rec.Body.Bytes(), &resp))
+	is.Equal(CodeInvalidRequest, resp.Error.Code)
+
+	rec = serve(d, `[{"jsonrpc":"2.0","method":"sum","params":{"a":1}}]`)
+	is.Equal(http.StatusNoContent, rec.Code)
+}
@@ -0,0 +1,65 @@
+// Package operation is implements the long-running operations of asynchronous work.
+// # This manifest was generated by ymir. DO NOT EDIT.
+package operation
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/kubuskotak/asgard/rest"
+)
+
+// RequestOperation request of operation resource.
+type RequestOperation struct {
+	ID string `schema:"id" json:"-" validate:"required"`
+}
+
+// Accepted answers the adapter with 202 and Location of operation, e.g.
+//
+//	op, err := manager.Start(r.Context(), "export", job)
+//	if err != nil {
+//		return operation.Operation{}, err
+//	}
+//	return manager.Accepted(w, r, op), nil
+func (m *Manager) Accepted(w http.ResponseWriter, r *http.Request, op Operation) Operation {
+	w.Header().Set(rest.HeaderLocation.String(), m.Location(op.ID))
+	w.Header().Set(rest.HeaderRetryAfter.String(), "1")
+	rest.StatusAccepted(r)
+	return op
+}
+
+// RegisterRoutes registers the operation resources on router:
+//   - GET {prefix}/{id}, the status of operation;
+//   - POST {prefix}/{id}/cancel, the cancellation of operation.
+func (m *Manager) RegisterRoutes(rt *rest.Router, opts ...rest.RouteOption) {
+	rest.Get[RequestOperation, Operation](rt, m.prefix+"/{id}",
+		func(w http.ResponseWriter, r *http.Request) (Operation, error) {
+			req, err := rest.GetBind[RequestOperation](r)
+			if err != nil {
+				return Operation{}, rest.ErrBadRequest(w, r, err)
+			}
+			op, err := m.Get(r.Context(), req.ID)
+			if errors.Is(err, ErrNotFound) {
+				return op, rest.ErrNotFound(w, r, err)
+			}
+			if err == nil && !op.Done() {
+				w.Header().Set(rest.HeaderRetryAfter.String(), "1")
+			}
+			return op, err
+		}, append([]rest.RouteOption{rest.WithSummary("operation status"), rest.WithTags("operations")}, opts...)...)
+	rest.Post[RequestOperation, Operation](rt, m.prefix+"/{id}/cancel",
+		func(w http.ResponseWriter, r *http.Request) (Operation, error) {
+			req, err := rest.GetBind[RequestOperation](r)
+			if err != nil {
+				return Operation{}, rest.ErrBadRequest(w, r, err)
+			}
+			op, err := m.Cancel(r.Context(), req.ID)
+			switch {
+			case errors.Is(err, ErrNotFound):
+				return op, rest.ErrNotFound(w, r, err)
+			case errors.Is(err, ErrDone):
+				return op, rest.ErrStatusConflict(w, r, err)
+			}
+			return op, err
+		}, append([]rest.RouteOption{rest.WithSummary("cancel operation"), rest.WithTags("operations")}, opts...)...)
+}
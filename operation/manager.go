@@ -0,0 +1,333 @@
+// Package operation is implements the long-running operations of asynchronous work.
+// # This manifest was generated by ymir. DO NOT EDIT.
+package operation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/kubuskotak/asgard/internal/xcontext"
+	"github.com/kubuskotak/asgard/security"
+)
+
+const tracerName = "otelOperation"
+
+// Option is the option of Manager.
+type Option = func(m *Manager) error
+
+// WithPrefix will set the path prefix of operation resources, "/operations" by default.
+func WithPrefix(prefix string) Option {
+	return func(m *Manager) error {
+		if !strings.HasPrefix(prefix, "/") {
+			return errors.New("operation: prefix must start with /")
+		}
+		m.prefix = strings.TrimSuffix(prefix, "/")
+		return nil
+	}
+}
+
+// WithJobTimeout will fail the jobs running longer than d.
+func WithJobTimeout(d time.Duration) Option {
+	return func(m *Manager) error {
+		if d <= 0 {
+			return errors.New("operation: job timeout must be positive")
+		}
+		m.timeout = d
+		return nil
+	}
+}
+
+// Manager runs the jobs in background and persists their status in Store.
+// Cancellation reaches the jobs running on this instance, others observe the canceled status when they finish.
+type Manager struct {
+	store   Store
+	prefix  string
+	timeout time.Duration
+	tracer  trace.Tracer
+
+	mu      sync.Mutex
+	cancels map[string]context.CancelFunc
+	locks   map[string]*opLock
+	wg      sync.WaitGroup
+}
+
+// opLock serializes the updates of one operation, it is removed when no update holds it.
+type opLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+// NewManager creates the manager of store.
+func NewManager(store Store, opts ...Option) *Manager {
+	m := &Manager{
+		store:   store,
+		prefix:  "/operations",
+		tracer:  otel.GetTracerProvider().Tracer(tracerName, trace.WithInstrumentationVersion("v1.0.0")),
+		cancels: make(map[string]context.CancelFunc),
+		locks:   make(map[string]*opLock),
+	}
+	for _, opt := range opts {
+		if err := opt(m); err != nil {
+			panic(err)
+		}
+	}
+	return m
+}
+
+// Location returns the path of operation resource.
+func (m *Manager) Location(id string) string {
+	return m.prefix + "/" + id
+}
+
+// Start persists the pending operation and runs the job in background. The job keeps the values
+// of ctx, e.g. tenant, but not its cancellation, and its trace is linked to the span of ctx.
+func (m *Manager) Start(ctx context.Context, kind string, job Job) (Operation, error) {
+	id, err := security.GenID()
+	if err != nil {
+		return Operation{}, err
+	}
+	now := time.Now().UTC()
+	op := Operation{ID: id, Kind: kind, Status: StatusPending, CreatedAt: now, UpdatedAt: now}
+
+	jobCtx, span := m.tracer.Start(xcontext.WithoutCancel(ctx), "operation "+kind,
+		trace.WithNewRoot(),
+		trace.WithLinks(trace.LinkFromContext(ctx)),
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(
+			attribute.String("operation.id", id),
+			attribute.String("operation.kind", kind),
+		))
+	if sc := span.SpanContext(); sc.HasTraceID() {
+		op.TraceID = sc.TraceID().String()
+	}
+	if err = m.store.Create(ctx, op); err != nil {
+		span.End()
+		return Operation{}, err
+	}
+	trace.SpanFromContext(ctx).AddEvent("operation started", trace.WithAttributes(
+		attribute.String("operation.id", id),
+		attribute.String("operation.trace_id", op.TraceID),
+	))
+
+	var cancel context.CancelFunc
+	if m.timeout > 0 {
+		jobCtx, cancel = context.WithTimeout(jobCtx, m.timeout)
+	} else {
+		jobCtx, cancel = context.WithCancel(jobCtx)
+	}
+	m.mu.Lock()
+	m.cancels[id] = cancel
+	m.mu.Unlock()
+	m.wg.Add(1)
+	go m.run(jobCtx, span, op, job)
+	return op, nil
+}
+
+// Get returns the operation.
+func (m *Manager) Get(ctx context.Context, id string) (Operation, error) {
+	return m.store.Get(ctx, id)
+}
+
+// Cancel marks the operation canceled and cancels its job, ErrDone is returned when it is finished.
+func (m *Manager) Cancel(ctx context.Context, id string) (Operation, error) {
+	op, err := m.update(ctx, id, func(op *Operation) error {
+		if op.Done() {
+			return ErrDone
+		}
+		op.Status = StatusCanceled
+		op.Error = ErrCanceled.Error()
+		return nil
+	})
+	if err != nil {
+		return op, err
+	}
+	m.mu.Lock()
+	cancel, ok := m.cancels[id]
+	m.mu.Unlock()
+	if ok {
+		cancel()
+	}
+	return op, nil
+}
+
+// Wait polls the operation every interval until it is done or ctx is done.
+func (m *Manager) Wait(ctx context.Context, id string, interval time.Duration) (Operation, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		op, err := m.store.Get(ctx, id)
+		if err != nil || op.Done() {
+			return op, err
+		}
+		select {
+		case <-ctx.Done():
+			return op, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Recover fails the pending and running operations not updated within stale, their jobs were lost
+// by restart. Call it on startup before Start, stale should outlast the job timeout when several
+// instances share the store, 0 fails all of them.
+func (m *Manager) Recover(ctx context.Context, stale time.Duration) (int, error) {
+	return m.store.Interrupt(ctx, time.Now().Add(-stale))
+}
+
+// Cleanup deletes the operations finished longer than retention ago.
+func (m *Manager) Cleanup(ctx context.Context, retention time.Duration) (int, error) {
+	return m.store.DeleteDone(ctx, time.Now().Add(-retention))
+}
+
+// Shutdown cancels the running jobs and waits until they finish or ctx is done.
+func (m *Manager) Shutdown(ctx context.Context) error {
+	m.mu.Lock()
+	for _, cancel := range m.cancels {
+		cancel()
+	}
+	m.mu.Unlock()
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (m *Manager) run(ctx context.Context, span trace.Span, op Operation, job Job) {
+	defer m.wg.Done()
+	defer span.End()
+	defer func() {
+		m.mu.Lock()
+		cancel := m.cancels[op.ID]
+		delete(m.cancels, op.ID)
+		m.mu.Unlock()
+		cancel()
+	}()
+	// the status updates outlive the job cancellation.
+	storeCtx := xcontext.WithoutCancel(ctx)
+	if _, err := m.update(storeCtx, op.ID, func(o *Operation) error {
+		if o.Done() {
+			return ErrDone
+		}
+		o.Status = StatusRunning
+		return nil
+	}); err != nil {
+		return
+	}
+	report := func(percent int, message string) {
+		if _, err := m.update(storeCtx, op.ID, func(o *Operation) error {
+			if o.Done() {
+				return ErrDone
+			}
+			o.Progress, o.Message = clamp(percent), message
+			return nil
+		}); err != nil && !errors.Is(err, ErrDone) {
+			log.Error().Err(err).Str("operation.id", op.ID).Msg("operation progress")
+		}
+	}
+
+	result, err := safeJob(ctx, job, report)
+	_, uErr := m.update(storeCtx, op.ID, func(o *Operation) error {
+		if o.Status == StatusCanceled {
+			return nil
+		}
+		switch {
+		case errors.Is(ctx.Err(), context.DeadlineExceeded):
+			o.Status, o.Error = StatusFailed, ErrTimeout.Error()
+		case err != nil:
+			o.Status, o.Error = StatusFailed, err.Error()
+		default:
+			b, mErr := json.Marshal(result)
+			if mErr != nil {
+				o.Status, o.Error = StatusFailed, mErr.Error()
+				break
+			}
+			o.Status, o.Result, o.Progress = StatusSucceeded, b, 100
+		}
+		return nil
+	})
+	if uErr != nil {
+		log.Error().Err(uErr).Str("operation.id", op.ID).Msg("operation result")
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
+
+// update applies fn on the stored operation, the completion time is set once it is done.
+func (m *Manager) update(ctx context.Context, id string, fn func(op *Operation) error) (Operation, error) {
+	unlock := m.lock(id)
+	defer unlock()
+	op, err := m.store.Get(ctx, id)
+	if err != nil {
+		return op, err
+	}
+	if err = fn(&op); err != nil {
+		return op, err
+	}
+	now := time.Now().UTC()
+	op.UpdatedAt = now
+	if op.Done() && op.CompletedAt == nil {
+		op.CompletedAt = &now
+	}
+	return op, m.store.Update(ctx, op)
+}
+
+// lock locks the updates of operation id, the returned func unlocks it.
+func (m *Manager) lock(id string) func() {
+	m.mu.Lock()
+	l, ok := m.locks[id]
+	if !ok {
+		l = &opLock{}
+		m.locks[id] = l
+	}
+	l.refs++
+	m.mu.Unlock()
+	l.mu.Lock()
+	return func() {
+		l.mu.Unlock()
+		m.mu.Lock()
+		if l.refs--; l.refs == 0 {
+			delete(m.locks, id)
+		}
+		m.mu.Unlock()
+	}
+}
+
+// safeJob runs the job and recovers its panic as error.
+func safeJob(ctx context.Context, job Job, report Reporter) (result any, err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("operation: job panic: %v", rec)
+		}
+	}()
+	return job(ctx, report)
+}
+
+func clamp(percent int) int {
+	switch {
+	case percent < 0:
+		return 0
+	case percent > 100:
+		return 100
+	}
+	return percent
+}
@@ -0,0 +1,70 @@
+// Package operation is implements the long-running operations of asynchronous work.
+// # This manifest was generated by ymir. DO NOT EDIT.
+package operation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrNotFound is define error when the operation is not found.
+	ErrNotFound = errors.New("operation is not found")
+	// ErrDone is define error when the operation is already done and can not be canceled.
+	ErrDone = errors.New("operation is already done")
+	// ErrCanceled is define error of the canceled operation.
+	ErrCanceled = errors.New("operation is canceled")
+	// ErrTimeout is define error when the operation exceeds the job timeout.
+	ErrTimeout = errors.New("operation timeout")
+	// ErrInterrupted is define error of the operation whose job was lost by restart of its manager.
+	ErrInterrupted = errors.New("operation is interrupted")
+)
+
+// Status is the state of operation.
+type Status string
+
+// Declare related constants for each Status.
+const (
+	StatusPending   Status = "pending"
+	StatusRunning   Status = "running"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+	StatusCanceled  Status = "canceled"
+)
+
+// Done reports whether the status is final.
+func (s Status) Done() bool {
+	return s == StatusSucceeded || s == StatusFailed || s == StatusCanceled
+}
+
+// Operation holds the status resource of asynchronous job.
+type Operation struct {
+	ID     string `json:"id"`
+	Kind   string `json:"kind"`
+	Status Status `json:"status"`
+	// Progress is the percentage of job, 0 to 100.
+	Progress int    `json:"progress"`
+	Message  string `json:"message,omitempty"`
+	// Result is the JSON result of succeeded job.
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+	// TraceID is the trace of job, it is linked to the trace of originating request.
+	TraceID     string     `json:"trace_id,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// Done reports whether the operation is finished.
+func (o Operation) Done() bool {
+	return o.Status.Done()
+}
+
+// Reporter reports the progress percentage and message of running job.
+type Reporter func(percent int, message string)
+
+// Job is the background work of operation, the result is marshaled as JSON.
+// ctx is canceled when the operation is canceled or the manager is shutdown.
+type Job func(ctx context.Context, report Reporter) (any, error)
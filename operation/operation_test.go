@@ -0,0 +1,183 @@
+package operation
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kubuskotak/asgard/rest"
+	"github.com/kubuskotak/asgard/rest/resttest"
+	_ "github.com/kubuskotak/asgard/sqlite"
+)
+
+type exportRequest struct {
+	Fail bool `json:"fail"`
+}
+
+func TestManager(t *testing.T) {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "operations.db"))
+	assert.NoError(t, err)
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	sqliteStore, err := NewSQLiteStore(context.Background(), db)
+	assert.NoError(t, err)
+
+	for name, store := range map[string]Store{"memory": NewMemoryStore(), "sqlite": sqliteStore} {
+		t.Run(name, func(t *testing.T) {
+			testManager(t, store)
+		})
+	}
+}
+
+func testManager(t *testing.T, store Store) {
+	is := assert.New(t)
+	m := NewManager(store)
+	release := make(chan struct{})
+	router := rest.NewRouter()
+	rest.Post[exportRequest, Operation](router, "/exports", func(w http.ResponseWriter, r *http.Request) (Operation, error) {
+		req, _ := rest.GetBind[exportRequest](r)
+		op, err := m.Start(r.Context(), "export", func(ctx context.Context, report Reporter) (any, error) {
+			report(50, "halfway")
+			select {
+			case <-release:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+			if req.Fail {
+				return nil, errors.New("disk is full")
+			}
+			return map[string]string{"url": "/files/export.csv"}, nil
+		})
+		if err != nil {
+			return Operation{}, err
+		}
+		return m.Accepted(w, r, op), nil
+	})
+	m.RegisterRoutes(router)
+	h := resttest.New(t, router)
+
+	resp := h.Post("/exports").JSON(exportRequest{}).Do().Status(http.StatusAccepted)
+	op := resttest.Data[Operation](resp)
+	is.Equal(m.Location(op.ID), resp.Recorder.Header().Get(rest.HeaderLocation.String()))
+	close(release)
+	done, err := m.Wait(context.Background(), op.ID, 5*time.Millisecond)
+	is.NoError(err)
+	is.Equal(StatusSucceeded, done.Status)
+	status := resttest.Data[Operation](h.Get(m.Location(op.ID)).Do().Status(http.StatusOK))
+	is.Equal(100, status.Progress)
+	is.JSONEq(`{"url":"/files/export.csv"}`, string(status.Result))
+	is.NotNil(status.CompletedAt)
+	h.Post(m.Location(op.ID) + "/cancel").Do().Status(http.StatusConflict)
+
+	failed := resttest.Data[Operation](h.Post("/exports").JSON(exportRequest{Fail: true}).Do())
+	done, err = m.Wait(context.Background(), failed.ID, 5*time.Millisecond)
+	is.NoError(err)
+	is.Equal(StatusFailed, done.Status)
+	is.Equal("disk is full", done.Error)
+
+	blocked, err := m.Start(context.Background(), "export", func(ctx context.Context, report Reporter) (any, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	is.NoError(err)
+	canceled := resttest.Data[Operation](h.Post(m.Location(blocked.ID) + "/cancel").Do().Status(http.StatusOK))
+	is.Equal(StatusCanceled, canceled.Status)
+	is.NoError(m.Shutdown(context.Background()))
+	done, err = m.Get(context.Background(), blocked.ID)
+	is.NoError(err)
+	is.Equal(StatusCanceled, done.Status)
+
+	h.Get(m.Location("unknown")).Do().Status(http.StatusNotFound)
+	n, err := m.Cleanup(context.Background(), -time.Second)
+	is.NoError(err)
+	is.Equal(3, n)
+}
+
+func TestManagerRecover(t *testing.T) {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "operations.db"))
+	assert.NoError(t, err)
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	sqliteStore, err := NewSQLiteStore(context.Background(), db)
+	assert.NoError(t, err)
+
+	for name, store := range map[string]Store{"memory": NewMemoryStore(), "sqlite": sqliteStore} {
+		t.Run(name, func(t *testing.T) {
+			is := assert.New(t)
+			ctx := context.Background()
+			old := time.Now().UTC().Add(-time.Hour)
+			for id, status := range map[string]Status{"lost": StatusRunning, "queued": StatusPending, "done": StatusSucceeded} {
+				is.NoError(store.Create(ctx, Operation{ID: id, Kind: "export", Status: status, CreatedAt: old, UpdatedAt: old}))
+			}
+			now := time.Now().UTC()
+			is.NoError(store.Create(ctx, Operation{ID: "fresh", Kind: "export", Status: StatusRunning, CreatedAt: now, UpdatedAt: now}))
+
+			n, err := NewManager(store).Recover(ctx, time.Minute)
+			is.NoError(err)
+			is.Equal(2, n)
+			for id, status := range map[string]Status{"lost": StatusFailed, "queued": StatusFailed, "done": StatusSucceeded, "fresh": StatusRunning} {
+				op, err := store.Get(ctx, id)
+				is.NoError(err)
+				is.Equal(status, op.Status, id)
+			}
+			op, _ := store.Get(ctx, "lost")
+			is.Equal(ErrInterrupted.Error(), op.Error)
+			is.NotNil(op.CompletedAt)
+		})
+	}
+}
+
+// blockingStore blocks Get of the operation id until release is closed.
+type blockingStore struct {
+	Store
+	id      string
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (s blockingStore) Get(ctx context.Context, id string) (Operation, error) {
+	if id == s.id {
+		close(s.entered)
+		<-s.release
+	}
+	return s.Store.Get(ctx, id)
+}
+
+func TestManagerLock(t *testing.T) {
+	is := assert.New(t)
+	ctx := context.Background()
+	store := blockingStore{Store: NewMemoryStore(), id: "slow", entered: make(chan struct{}), release: make(chan struct{})}
+	now := time.Now().UTC()
+	for _, id := range []string{"slow", "fast"} {
+		is.NoError(store.Create(ctx, Operation{ID: id, Kind: "export", Status: StatusRunning, CreatedAt: now, UpdatedAt: now}))
+	}
+	m := NewManager(store)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = m.Cancel(ctx, "slow")
+	}()
+	<-store.entered
+
+	// the update of another operation is not blocked by the slow store call.
+	fast := make(chan Operation)
+	go func() {
+		op, _ := m.Cancel(ctx, "fast")
+		fast <- op
+	}()
+	select {
+	case op := <-fast:
+		is.Equal(StatusCanceled, op.Status)
+	case <-time.After(time.Second):
+		is.Fail("the update of fast operation is blocked")
+	}
+	close(store.release)
+	<-done
+	is.Empty(m.locks)
+}
@@ -0,0 +1,129 @@
+// Package operation is implements the long-running operations of asynchronous work.
+// # This manifest was generated by ymir. DO NOT EDIT.
+package operation
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+const sqliteSchema = `CREATE TABLE IF NOT EXISTS operations (
+	id           TEXT PRIMARY KEY,
+	kind         TEXT NOT NULL,
+	status       TEXT NOT NULL,
+	progress     INTEGER NOT NULL DEFAULT 0,
+	message      TEXT NOT NULL DEFAULT '',
+	result       BLOB,
+	error        TEXT NOT NULL DEFAULT '',
+	trace_id     TEXT NOT NULL DEFAULT '',
+	created_at   INTEGER NOT NULL,
+	updated_at   INTEGER NOT NULL,
+	completed_at INTEGER
+);
+CREATE INDEX IF NOT EXISTS operations_completed_at ON operations (completed_at);`
+
+// SQLiteStore is the Store of sqlite database, e.g. opened by the sqlite3 driver of asgard/sqlite.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteStore creates the store and migrates the operations table. sqlite allows one writer, so db should
+// wait on lock by busy_timeout pragma or limit its open connections, otherwise the concurrent job updates
+// fail with SQLITE_BUSY.
+func NewSQLiteStore(ctx context.Context, db *sql.DB) (*SQLiteStore, error) {
+	if _, err := db.ExecContext(ctx, sqliteSchema); err != nil {
+		return nil, err
+	}
+	return &SQLiteStore{db: db}, nil
+}
+
+// Create stores the operation.
+func (s *SQLiteStore) Create(ctx context.Context, op Operation) error {
+	_, err := s.db.ExecContext(ctx, `INSERT INTO operations
+		(id, kind, status, progress, message, result, error, trace_id, created_at, updated_at, completed_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		op.ID, op.Kind, string(op.Status), op.Progress, op.Message, []byte(op.Result), op.Error, op.TraceID,
+		op.CreatedAt.UnixNano(), op.UpdatedAt.UnixNano(), unixNano(op.CompletedAt))
+	return err
+}
+
+// Get returns the operation.
+func (s *SQLiteStore) Get(ctx context.Context, id string) (Operation, error) {
+	var (
+		op               Operation
+		status           string
+		result           []byte
+		created, updated int64
+		completed        sql.NullInt64
+	)
+	err := s.db.QueryRowContext(ctx, `SELECT id, kind, status, progress, message, result, error, trace_id,
+		created_at, updated_at, completed_at FROM operations WHERE id = ?`, id).
+		Scan(&op.ID, &op.Kind, &status, &op.Progress, &op.Message, &result, &op.Error, &op.TraceID,
+			&created, &updated, &completed)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Operation{}, ErrNotFound
+	}
+	if err != nil {
+		return Operation{}, err
+	}
+	op.Status = Status(status)
+	if len(result) > 0 {
+		op.Result = result
+	}
+	op.CreatedAt = time.Unix(0, created).UTC()
+	op.UpdatedAt = time.Unix(0, updated).UTC()
+	if completed.Valid {
+		t := time.Unix(0, completed.Int64).UTC()
+		op.CompletedAt = &t
+	}
+	return op, nil
+}
+
+// Update replaces the operation.
+func (s *SQLiteStore) Update(ctx context.Context, op Operation) error {
+	res, err := s.db.ExecContext(ctx, `UPDATE operations SET status = ?, progress = ?, message = ?, result = ?,
+		error = ?, trace_id = ?, updated_at = ?, completed_at = ? WHERE id = ?`,
+		string(op.Status), op.Progress, op.Message, []byte(op.Result), op.Error, op.TraceID,
+		op.UpdatedAt.UnixNano(), unixNano(op.CompletedAt), op.ID)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Interrupt fails the pending and running operations updated before t.
+func (s *SQLiteStore) Interrupt(ctx context.Context, before time.Time) (int, error) {
+	now := time.Now().UTC().UnixNano()
+	res, err := s.db.ExecContext(ctx, `UPDATE operations SET status = ?, error = ?, updated_at = ?, completed_at = ?
+		WHERE status IN (?, ?) AND updated_at < ?`,
+		string(StatusFailed), ErrInterrupted.Error(), now, now,
+		string(StatusPending), string(StatusRunning), before.UnixNano())
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+// DeleteDone deletes the finished operations completed before t.
+func (s *SQLiteStore) DeleteDone(ctx context.Context, before time.Time) (int, error) {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM operations WHERE completed_at IS NOT NULL AND completed_at < ?`,
+		before.UnixNano())
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+func unixNano(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return t.UnixNano()
+}
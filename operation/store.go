@@ -0,0 +1,92 @@
+// Package operation is implements the long-running operations of asynchronous work.
+// # This manifest was generated by ymir. DO NOT EDIT.
+package operation
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Store persists the operations.
+type Store interface {
+	Create(ctx context.Context, op Operation) error
+	Get(ctx context.Context, id string) (Operation, error)
+	Update(ctx context.Context, op Operation) error
+	// Interrupt fails the pending and running operations updated before t.
+	Interrupt(ctx context.Context, before time.Time) (int, error)
+	// DeleteDone deletes the finished operations completed before t.
+	DeleteDone(ctx context.Context, before time.Time) (int, error)
+}
+
+// MemoryStore is the in memory Store.
+type MemoryStore struct {
+	mu  sync.RWMutex
+	ops map[string]Operation
+}
+
+// NewMemoryStore creates the memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{ops: make(map[string]Operation)}
+}
+
+// Create stores the operation.
+func (s *MemoryStore) Create(_ context.Context, op Operation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ops[op.ID] = op
+	return nil
+}
+
+// Get returns the operation.
+func (s *MemoryStore) Get(_ context.Context, id string) (Operation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	op, ok := s.ops[id]
+	if !ok {
+		return Operation{}, ErrNotFound
+	}
+	return op, nil
+}
+
+// Update replaces the operation.
+func (s *MemoryStore) Update(_ context.Context, op Operation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.ops[op.ID]; !ok {
+		return ErrNotFound
+	}
+	s.ops[op.ID] = op
+	return nil
+}
+
+// Interrupt fails the pending and running operations updated before t.
+func (s *MemoryStore) Interrupt(_ context.Context, before time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now().UTC()
+	n := 0
+	for id, op := range s.ops {
+		if op.Done() || !op.UpdatedAt.Before(before) {
+			continue
+		}
+		op.Status, op.Error, op.UpdatedAt, op.CompletedAt = StatusFailed, ErrInterrupted.Error(), now, &now
+		s.ops[id] = op
+		n++
+	}
+	return n, nil
+}
+
+// DeleteDone deletes the finished operations completed before t.
+func (s *MemoryStore) DeleteDone(_ context.Context, before time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for id, op := range s.ops {
+		if op.CompletedAt != nil && op.CompletedAt.Before(before) {
+			delete(s.ops, id)
+			n++
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,363 @@
+// Package rest is port adapter via http/s protocol
+// # This manifest was generated by ymir. DO NOT EDIT.
+package rest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semConv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const batchTracerName = "otelBatch"
+
+var (
+	// ErrInvalidBatch is define error when the batch can not be dispatched, e.g. unknown or cyclic dependencies.
+	ErrInvalidBatch = errors.New("invalid batch request")
+	// ErrBatchDependency is define error when the dependency of sub-request failed.
+	ErrBatchDependency = errors.New("batch dependency failed")
+)
+
+// BatchRequest holds the sub-request of batch.
+type BatchRequest struct {
+	ID      string            `json:"id" validate:"required"`
+	Method  string            `json:"method" validate:"required"`
+	Path    string            `json:"path" validate:"required"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    json.RawMessage   `json:"body,omitempty"`
+	// DependsOn holds the ids of sub-requests which must succeed before this one runs.
+	DependsOn []string `json:"depends_on,omitempty"`
+}
+
+// BatchPayload holds the sub-requests, they run in parallel unless Sequential is set.
+type BatchPayload struct {
+	Sequential bool           `json:"sequential"`
+	Requests   []BatchRequest `json:"requests" validate:"required,min=1,dive"`
+}
+
+// BatchResponse holds the response of sub-request, body is the response envelope of sub-request.
+type BatchResponse struct {
+	ID      string            `json:"id"`
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    json.RawMessage   `json:"body,omitempty"`
+}
+
+// BatchConfig holds the configuration of Batch.
+type BatchConfig struct {
+	MaxRequests int
+	Concurrency int
+}
+
+// BatchOption is the option of Batch.
+type BatchOption = func(c *BatchConfig) error
+
+// WithBatchMaxRequests will limit the number of sub-requests, 20 by default.
+func WithBatchMaxRequests(n int) BatchOption {
+	return func(c *BatchConfig) error {
+		if n < 1 {
+			return errors.New("batch: max requests must be at least 1")
+		}
+		c.MaxRequests = n
+		return nil
+	}
+}
+
+// WithBatchConcurrency will limit the number of sub-requests running at once, 4 by default.
+func WithBatchConcurrency(n int) BatchOption {
+	return func(c *BatchConfig) error {
+		if n < 1 {
+			return errors.New("batch: concurrency must be at least 1")
+		}
+		c.Concurrency = n
+		return nil
+	}
+}
+
+// Batch returns the adapter which dispatches the sub-requests in process through h, e.g. the router
+// which registers it, so sub-requests pass the same middleware chain:
+//
+//	rest.Post(router, "/batch", rest.Batch(router))
+//
+// The headers of batch request are forwarded and overridden by the sub-request headers.
+// A sub-request of failed dependency is answered with 424 without running.
+func Batch(h http.Handler, opts ...BatchOption) Adapter[BatchPayload, []BatchResponse] {
+	cfg := &BatchConfig{MaxRequests: 20, Concurrency: 4}
+	for _, opt := range opts {
+		if err := opt(cfg); err != nil {
+			panic(err)
+		}
+	}
+	tracer := otel.GetTracerProvider().Tracer(batchTracerName, trace.WithInstrumentationVersion("v1.0.0"))
+	return func(w http.ResponseWriter, r *http.Request) ([]BatchResponse, error) {
+		payload, err := GetBind[BatchPayload](r)
+		if err != nil {
+			return nil, ErrBadRequest(w, r, err)
+		}
+		if len(payload.Requests) > cfg.MaxRequests {
+			return nil, ErrRequestEntityTooLarge(w, r,
+				fmt.Errorf("%w: batch has more than %d requests", ErrInvalidBatch, cfg.MaxRequests))
+		}
+		if err = checkBatch(r, payload); err != nil {
+			return nil, ErrBadRequest(w, r, err)
+		}
+		b := &batch{
+			handler:    h,
+			tracer:     tracer,
+			parent:     r,
+			requests:   payload.Requests,
+			responses:  make([]BatchResponse, len(payload.Requests)),
+			done:       make(map[string]chan struct{}, len(payload.Requests)),
+			index:      make(map[string]int, len(payload.Requests)),
+			sem:        make(chan struct{}, cfg.Concurrency),
+			sequential: payload.Sequential,
+		}
+		b.run()
+		return b.responses, nil
+	}
+}
+
+// checkBatch validates the ids, dependencies and paths of sub-requests,
+// sequential batch depends only on the previous sub-requests.
+func checkBatch(r *http.Request, payload BatchPayload) error {
+	requests := payload.Requests
+	deps := make(map[string][]string, len(requests))
+	for _, req := range requests {
+		if payload.Sequential {
+			for _, dep := range req.DependsOn {
+				if _, ok := deps[dep]; !ok {
+					return fmt.Errorf("%w: %s depends on unknown or later %s", ErrInvalidBatch, req.ID, dep)
+				}
+			}
+		}
+		if _, ok := deps[req.ID]; ok {
+			return fmt.Errorf("%w: duplicate id %s", ErrInvalidBatch, req.ID)
+		}
+		if !strings.HasPrefix(req.Path, "/") {
+			return fmt.Errorf("%w: path of %s must be absolute", ErrInvalidBatch, req.ID)
+		}
+		if p, _, _ := strings.Cut(req.Path, "?"); p == r.URL.Path {
+			return fmt.Errorf("%w: %s can not be nested batch", ErrInvalidBatch, req.ID)
+		}
+		deps[req.ID] = req.DependsOn
+	}
+	// visiting holds 1 while the id is on the path and 2 once it is done.
+	visiting := make(map[string]int, len(requests))
+	var visit func(id string) error
+	visit = func(id string) error {
+		switch visiting[id] {
+		case 1:
+			return fmt.Errorf("%w: cyclic dependency on %s", ErrInvalidBatch, id)
+		case 2:
+			return nil
+		}
+		visiting[id] = 1
+		for _, dep := range deps[id] {
+			if _, ok := deps[dep]; !ok {
+				return fmt.Errorf("%w: %s depends on unknown %s", ErrInvalidBatch, id, dep)
+			}
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		visiting[id] = 2
+		return nil
+	}
+	for _, req := range requests {
+		if err := visit(req.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type batch struct {
+	handler    http.Handler
+	tracer     trace.Tracer
+	parent     *http.Request
+	requests   []BatchRequest
+	responses  []BatchResponse
+	done       map[string]chan struct{}
+	index      map[string]int
+	sem        chan struct{}
+	sequential bool
+}
+
+// run dispatches every sub-request once its dependencies are done, sequential batch keeps the order.
+func (b *batch) run() {
+	for i, req := range b.requests {
+		b.done[req.ID] = make(chan struct{})
+		b.index[req.ID] = i
+	}
+	var wg sync.WaitGroup
+	for i := range b.requests {
+		if b.sequential {
+			b.dispatch(i)
+			continue
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			b.dispatch(i)
+		}(i)
+	}
+	wg.Wait()
+}
+
+func (b *batch) dispatch(i int) {
+	req := b.requests[i]
+	defer close(b.done[req.ID])
+	// the parallel sub-request runs outside of net/http recovery, its panic answers 500 instead of crashing.
+	defer func() {
+		if rec := recover(); rec != nil {
+			log.Error().Str("batch.id", req.ID).Interface("panic", rec).Msg("batch")
+			b.responses[i] = batchError(req, http.StatusInternalServerError,
+				fmt.Errorf("batch: sub-request %s panic", req.ID))
+		}
+	}()
+	for _, dep := range req.DependsOn {
+		<-b.done[dep]
+		if status := b.responses[b.index[dep]].Status; status < 200 || status >= 300 {
+			b.responses[i] = failedDependency(req, dep)
+			return
+		}
+	}
+	b.sem <- struct{}{}
+	defer func() { <-b.sem }()
+	b.responses[i] = b.serve(req)
+}
+
+// serve runs the sub-request through handler with child span of batch request.
+func (b *batch) serve(req BatchRequest) BatchResponse {
+	ctx, span := b.tracer.Start(b.parent.Context(), "batch "+req.Method+" "+req.Path,
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(
+			attribute.String("batch.id", req.ID),
+			semConv.HTTPMethodKey.String(req.Method),
+			semConv.HTTPTargetKey.String(req.Path),
+		))
+	defer span.End()
+
+	r, err := http.NewRequestWithContext(batchContext{ctx}, strings.ToUpper(req.Method), req.Path, bytes.NewReader(req.Body))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return batchError(req, http.StatusBadRequest, err)
+	}
+	r.RemoteAddr, r.Host, r.Proto = b.parent.RemoteAddr, b.parent.Host, b.parent.Proto
+	for key, values := range b.parent.Header {
+		switch http.CanonicalHeaderKey(key) {
+		case HeaderContentType.String(), HeaderContentLength.String():
+			continue
+		}
+		r.Header[key] = values
+	}
+	if len(req.Body) > 0 {
+		r.Header.Set(HeaderContentType.String(), MIMEApplicationJSON.String())
+	}
+	for key, value := range req.Headers {
+		r.Header.Set(key, value)
+	}
+
+	bw := &batchWriter{header: http.Header{}, code: http.StatusOK}
+	b.handler.ServeHTTP(bw, r)
+	span.SetAttributes(semConv.HTTPStatusCodeKey.Int(bw.code))
+	if bw.code >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, http.StatusText(bw.code))
+	}
+	return bw.response(req.ID)
+}
+
+func batchError(req BatchRequest, status int, err error) BatchResponse {
+	body, _ := DefaultEnvelope{}.Marshal(EnvelopeParts{
+		StatusCode: status,
+		Meta:       Meta{Code: strconv.Itoa(status), Message: err.Error()},
+		Data:       make(map[string]any),
+		Err:        err,
+	})
+	return BatchResponse{
+		ID:      req.ID,
+		Status:  status,
+		Headers: map[string]string{HeaderContentType.String(): MIMEApplicationJSON.String()},
+		Body:    body,
+	}
+}
+
+func failedDependency(req BatchRequest, dep string) BatchResponse {
+	return batchError(req, http.StatusFailedDependency, fmt.Errorf("%w: %s", ErrBatchDependency, dep))
+}
+
+// batchContext hides the rest and chi values of batch request from sub-requests, other values are kept,
+// e.g. the authenticated user and tenant.
+type batchContext struct {
+	context.Context
+}
+
+// Value returns nil for the keys of rest and chi route context.
+func (c batchContext) Value(key any) any {
+	switch key.(type) {
+	case ResponseType, RequestType:
+		return nil
+	}
+	if key == chi.RouteCtxKey {
+		return nil
+	}
+	return c.Context.Value(key)
+}
+
+// batchWriter records the response of sub-request.
+type batchWriter struct {
+	header      http.Header
+	buf         bytes.Buffer
+	code        int
+	wroteHeader bool
+}
+
+// Header returns the header map.
+func (bw *batchWriter) Header() http.Header { return bw.header }
+
+// WriteHeader records the first status code.
+func (bw *batchWriter) WriteHeader(code int) {
+	if bw.wroteHeader {
+		return
+	}
+	bw.code, bw.wroteHeader = code, true
+}
+
+// Write records the body.
+func (bw *batchWriter) Write(b []byte) (int, error) {
+	bw.WriteHeader(http.StatusOK)
+	return bw.buf.Write(b)
+}
+
+func (bw *batchWriter) response(id string) BatchResponse {
+	resp := BatchResponse{ID: id, Status: bw.code}
+	if len(bw.header) > 0 {
+		resp.Headers = make(map[string]string, len(bw.header))
+		for key := range bw.header {
+			resp.Headers[key] = bw.header.Get(key)
+		}
+	}
+	body := bytes.TrimSpace(bw.buf.Bytes())
+	switch {
+	case len(body) == 0:
+	case strings.Contains(bw.header.Get(HeaderContentType.String()), "json") && json.Valid(body):
+		resp.Body = body
+	default:
+		resp.Body, _ = json.Marshal(string(body))
+	}
+	return resp
+}
@@ -0,0 +1,107 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type batchItem struct {
+	ID   string `schema:"id" json:"id"`
+	Name string `json:"name"`
+}
+
+func TestBatch(t *testing.T) {
+	is := assert.New(t)
+	router := NewRouter()
+	var (
+		mu     sync.Mutex
+		tenant string
+	)
+	router.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			tenant = r.Header.Get("X-Tenant-ID")
+			mu.Unlock()
+			next.ServeHTTP(w, r)
+		})
+	})
+	Get[batchItem, batchItem](router, "/items/{id}", func(w http.ResponseWriter, r *http.Request) (batchItem, error) {
+		req, _ := GetBind[batchItem](r)
+		if req.ID == "0" {
+			return batchItem{}, ErrNotFound(w, r, errors.New("item is not found"))
+		}
+		return batchItem{ID: req.ID, Name: "book"}, nil
+	})
+	Post[batchItem, batchItem](router, "/items", func(w http.ResponseWriter, r *http.Request) (batchItem, error) {
+		req, _ := GetBind[batchItem](r)
+		return batchItem{ID: "9", Name: req.Name}, nil
+	})
+	Get[batchItem, batchItem](router, "/panic", func(w http.ResponseWriter, r *http.Request) (batchItem, error) {
+		panic("boom")
+	})
+	Post(router, "/batch", Batch(router, WithBatchConcurrency(2)))
+
+	serve := func(body string) (int, []BatchResponse) {
+		r := httptest.NewRequest(http.MethodPost, "/batch", strings.NewReader(body))
+		r.Header.Set(HeaderContentType.String(), MIMEApplicationJSON.String())
+		r.Header.Set("X-Tenant-ID", "acme")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, r)
+		var result struct {
+			Data []BatchResponse `json:"data"`
+		}
+		_ = json.Unmarshal(rec.Body.Bytes(), &result)
+		return rec.Code, result.Data
+	}
+
+	code, responses := serve(`{"requests":[
+		{"id":"a","method":"GET","path":"/items/1"},
+		{"id":"b","method":"POST","path":"/items","body":{"name":"pen"},"depends_on":["a"]},
+		{"id":"c","method":"GET","path":"/items/0"},
+		{"id":"d","method":"GET","path":"/items/2","depends_on":["c"]}
+	]}`)
+	is.Equal(http.StatusOK, code)
+	is.Equal("acme", tenant)
+	is.Len(responses, 4)
+	is.Equal(http.StatusOK, responses[0].Status)
+	is.JSONEq(`{"id":"1","name":"book"}`, string(mustData(t, responses[0].Body)))
+	is.JSONEq(`{"id":"9","name":"pen"}`, string(mustData(t, responses[1].Body)))
+	is.Equal(http.StatusNotFound, responses[2].Status)
+	is.Equal(http.StatusFailedDependency, responses[3].Status)
+
+	for _, sequential := range []string{"false", "true"} {
+		code, responses = serve(`{"sequential":` + sequential + `,"requests":[
+			{"id":"a","method":"GET","path":"/panic"},
+			{"id":"b","method":"GET","path":"/items/1","depends_on":["a"]},
+			{"id":"c","method":"GET","path":"/items/2"}
+		]}`)
+		is.Equal(http.StatusOK, code)
+		is.Equal(http.StatusInternalServerError, responses[0].Status)
+		is.Equal(http.StatusFailedDependency, responses[1].Status)
+		is.Equal(http.StatusOK, responses[2].Status)
+	}
+
+	code, _ = serve(`{"sequential":true,"requests":[
+		{"id":"a","method":"GET","path":"/items/1","depends_on":["b"]},
+		{"id":"b","method":"GET","path":"/items/2"}
+	]}`)
+	is.Equal(http.StatusBadRequest, code)
+	code, _ = serve(`{"requests":[{"id":"a","method":"POST","path":"/batch"}]}`)
+	is.Equal(http.StatusBadRequest, code)
+}
+
+func mustData(t *testing.T, body json.RawMessage) json.RawMessage {
+	t.Helper()
+	var envelope struct {
+		Data json.RawMessage `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(body, &envelope))
+	return envelope.Data
+}
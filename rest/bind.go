@@ -7,8 +7,10 @@ import (
 	"encoding/json"
 	"encoding/xml"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
+	"reflect"
 	"strings"
 
 	"github.com/go-chi/chi/v5"
@@ -20,25 +22,24 @@ import (
 const (
 	defaultMemory = 32 << 20 // 32 MB
 	wildcardPath  = "*"
+
+	tagQuery  = "query"
+	tagHeader = "header"
+	tagCookie = "cookie"
 )
 
 // decoderBody detects the correct decoderBody for use on an HTTP request and
-// marshals into a given interface.
+// marshals into a given interface. Decoders are looked up by media type, see RegisterDecoder.
 func decoderBody(r *http.Request, i any) (err error) {
 	if r.ContentLength == 0 {
 		return nil
 	}
-	switch GetRequestContentType(r) {
-	case MIMEApplicationJSON:
-		err = DecodeJSON(r.Body, i)
-	case MIMETextXML:
-		err = DecodeXML(r.Body, i)
-	case MIMEApplicationForm, MIMEMultipartForm:
-		err = DecodeForm(r, i)
-	default:
-		err = errors.New("unable to decode the request content type")
+	mediaType := requestMediaType(r)
+	decoder, ok := LookupDecoder(mediaType)
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnsupportedContentType, mediaType)
 	}
-	return err
+	return decoder(r, i)
 }
 
 // DecodeJSON decodes a given reader into an interface using the json decoderBody.
@@ -64,9 +65,20 @@ func DecodeXML(r io.Reader, v any) error {
 }
 
 // DecodeForm decodes a given reader into an interface using the form decoderBody.
+// Files of multipart form are bound into fields tagged by `form` which type is
+// *multipart.FileHeader, *UploadedFile or slices of them, see Upload. The multipart body
+// is limited by DefaultUploadConfig when Upload middleware is not installed.
 func DecodeForm(r *http.Request, v any) error {
+	cfg := uploadConfig(r)
 	if strings.HasPrefix(r.Header.Get(HeaderContentType.String()), MIMEMultipartForm.String()) {
-		if err := r.ParseMultipartForm(defaultMemory); err != nil {
+		if _, ok := r.Context().Value(CtxUploadConfig).(UploadConfig); !ok {
+			r.Body = http.MaxBytesReader(nil, r.Body, cfg.maxRequestSize())
+		}
+		if err := r.ParseMultipartForm(cfg.MaxMemory); err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				return fmt.Errorf("%w: %s", ErrUploadTooLarge, err)
+			}
 			return err
 		}
 	} else {
@@ -75,16 +87,27 @@ func DecodeForm(r *http.Request, v any) error {
 		}
 	}
 	decoding := schema.NewDecoder()
-	return decoding.Decode(&v, r.Form)
+	if err := decoding.Decode(v, r.Form); err != nil {
+		return err
+	}
+	if r.MultipartForm != nil {
+		return bindFiles(r, v, cfg)
+	}
+	return nil
 }
 
 type Binder[T any] struct {
-	str *T
+	str    *T
+	locale string
 }
 
 // Bind implements all bind request raw data.
-// Binding is done in following order: 1) path params; 2) query params; 3) request body. Each step COULD override previous
-// step bind values.
+// Binding is done in following order, each step COULD override previous step bind values:
+//  1. path params, fields tagged by `schema`;
+//  2. query params, fields tagged by `schema` on GET, DELETE and HEAD, fields tagged by `query` on every method;
+//  3. headers, fields tagged by `header`, e.g. `header:"X-Tenant-Id"`;
+//  4. cookies, fields tagged by `cookie`, e.g. `cookie:"session"`;
+//  5. request body.
 func Bind[T any](r *http.Request, v *T) (*Binder[T], error) {
 	binder := &Binder[T]{}
 	if err := bindURLParams(r.Context(), v); err != nil {
@@ -98,20 +121,41 @@ func Bind[T any](r *http.Request, v *T) (*Binder[T], error) {
 			return nil, err
 		}
 	}
+	if err := bindTaggedQueryParams(r, v); err != nil {
+		return nil, err
+	}
+	if err := bindHeaders(r, v); err != nil {
+		return nil, err
+	}
+	if err := bindCookies(r, v); err != nil {
+		return nil, err
+	}
 	if err := decoderBody(r, v); err != nil {
 		return nil, err
 	}
 	binder.str = v
+	binder.locale = LocaleOf(r)
 	return binder, nil
 }
 
-// Validate implements value validations for structs and individual fields based on tags.
+// Validate implements value validations for structs and individual fields based on tags,
+// messages are translated when the request locale is set, see AcceptLanguage.
 func (b *Binder[T]) Validate() error {
+	return validateStruct(b.str, b.locale)
+}
+
+// validateStruct returns the joined messages of security.Validate,
+// security.ValidateLocale is used when locale is not empty.
+func validateStruct(v any, locale string) error {
+	var validators []security.ErrorValidator
+	if locale == "" {
+		validators = security.Validate(v)
+	} else {
+		validators = security.ValidateLocale(v, locale)
+	}
 	var errs []error
-	validators := security.Validate(b.str)
 	for n := range validators {
-		v := validators[n]
-		errs = append(errs, errors.New(v.Message))
+		errs = append(errs, errors.New(validators[n].Message))
 	}
 	if len(errs) < 1 {
 		return nil
@@ -143,6 +187,90 @@ func bindURLParams(ctx context.Context, v any) error {
 
 func bindQueryParams(r *http.Request, v any) error {
 	queries := r.URL.Query()
+	// keys of `query` tags are bound by bindTaggedQueryParams.
+	for _, name := range tagNames(v, tagQuery) {
+		delete(queries, name)
+	}
 	decoding := schema.NewDecoder()
 	return decoding.Decode(v, queries)
 }
+
+func bindTaggedQueryParams(r *http.Request, v any) error {
+	var (
+		queries = r.URL.Query()
+		params  = map[string][]string{}
+	)
+	for _, name := range tagNames(v, tagQuery) {
+		if values, ok := queries[name]; ok {
+			params[name] = values
+		}
+	}
+	return decodeTagged(v, tagQuery, params)
+}
+
+func bindHeaders(r *http.Request, v any) error {
+	params := map[string][]string{}
+	for _, name := range tagNames(v, tagHeader) {
+		if values := r.Header.Values(name); len(values) > 0 {
+			params[name] = values
+		}
+	}
+	return decodeTagged(v, tagHeader, params)
+}
+
+func bindCookies(r *http.Request, v any) error {
+	params := map[string][]string{}
+	for _, name := range tagNames(v, tagCookie) {
+		if c, err := r.Cookie(name); err == nil {
+			params[name] = []string{c.Value}
+		}
+	}
+	return decodeTagged(v, tagCookie, params)
+}
+
+// decodeTagged decodes params into fields which alias is the given tag.
+func decodeTagged(v any, tag string, params map[string][]string) error {
+	if len(params) < 1 {
+		return nil
+	}
+	decoding := schema.NewDecoder()
+	decoding.SetAliasTag(tag)
+	decoding.IgnoreUnknownKeys(true)
+	return decoding.Decode(v, params)
+}
+
+// tagNames returns names of struct fields which are explicitly tagged by tag,
+// the fields of embedded structs are included.
+func tagNames(v any, tag string) []string {
+	rt := reflect.TypeOf(v)
+	for rt != nil && rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+	if rt == nil || rt.Kind() != reflect.Struct {
+		return nil
+	}
+	return structTagNames(rt, tag)
+}
+
+func structTagNames(rt reflect.Type, tag string) []string {
+	var names []string
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		name := strings.SplitN(field.Tag.Get(tag), ",", 2)[0]
+		if field.Anonymous && name == "" {
+			ft := field.Type
+			if ft.Kind() == reflect.Ptr && field.IsExported() {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				names = append(names, structTagNames(ft, tag)...)
+			}
+			continue
+		}
+		if !field.IsExported() || name == "" || name == "-" {
+			continue
+		}
+		names = append(names, name)
+	}
+	return names
+}
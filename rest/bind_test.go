@@ -0,0 +1,73 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+type bindScope struct {
+	Tenant string `header:"X-Tenant-Id"`
+	Trace  string `cookie:"trace"`
+}
+
+type BindPage struct {
+	Limit int `query:"limit"`
+}
+
+type precedenceRequest struct {
+	bindScope
+	*BindPage
+	Value string `schema:"value" query:"value" header:"X-Value" cookie:"value" json:"value"`
+}
+
+func TestBindPrecedence(t *testing.T) {
+	var bound precedenceRequest
+	router := chi.NewRouter()
+	router.Post("/{value}", HandlerAdapter[precedenceRequest](func(w http.ResponseWriter, r *http.Request) (any, error) {
+		bound, _ = GetBind[precedenceRequest](r)
+		return nil, nil
+	}).JSON)
+	serve := func(query, header, cookie, body string) precedenceRequest {
+		bound = precedenceRequest{}
+		r := httptest.NewRequest(http.MethodPost, "/path?"+query, strings.NewReader(body))
+		if header != "" {
+			r.Header.Set("X-Value", header)
+		}
+		if cookie != "" {
+			r.AddCookie(&http.Cookie{Name: "value", Value: cookie})
+		}
+		if body != "" {
+			r.Header.Set(HeaderContentType.String(), MIMEApplicationJSON.String())
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, r)
+		assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		return bound
+	}
+
+	is := assert.New(t)
+	// every step overrides the previous one: path, query, header, cookie, body.
+	is.Equal("path", serve("", "", "", "").Value)
+	is.Equal("query", serve("value=query", "", "", "").Value)
+	is.Equal("header", serve("value=query", "header", "", "").Value)
+	is.Equal("cookie", serve("value=query", "header", "cookie", "").Value)
+	is.Equal("body", serve("value=query", "header", "cookie", `{"value":"body"}`).Value)
+
+	// the tags of embedded structs are bound.
+	r := httptest.NewRequest(http.MethodPost, "/path?limit=20", nil)
+	r.Header.Set("X-Tenant-Id", "acme")
+	r.AddCookie(&http.Cookie{Name: "trace", Value: "t1"})
+	router.ServeHTTP(httptest.NewRecorder(), r)
+	is.Equal("acme", bound.Tenant)
+	is.Equal("t1", bound.Trace)
+	if is.NotNil(bound.BindPage) {
+		is.Equal(20, bound.Limit)
+	}
+	is.Equal([]string{"X-Tenant-Id", "X-Value"}, tagNames(&precedenceRequest{}, tagHeader))
+	is.Equal([]string{"limit", "value"}, tagNames(&precedenceRequest{}, tagQuery))
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"net/http"
 	"net/url"
+	"time"
 )
 
 // DefaultClientHttp is the default Client and is used by Get, Head, and Post.
@@ -28,8 +29,82 @@ func (c *DefaultClientHttp) RoundTrip(req *http.Request) (*http.Response, error)
 		}
 	}
 	newReq := *req
-	newReq.URL = c.BaseUrl.ResolveReference(req.URL)
+	if c.BaseUrl != nil {
+		newReq.URL = c.BaseUrl.ResolveReference(req.URL)
+	}
 	args := newReq.URL.Query()
 	newReq.URL.RawQuery = args.Encode()
 	return rt.RoundTrip(&newReq)
 }
+
+// Tripperware is middleware type of http.RoundTripper.
+type Tripperware func(next http.RoundTripper) http.RoundTripper
+
+// Client is the resilient http client built on DefaultClientHttp,
+// requests are retried by RetryPolicy and bounded by the overall timeout.
+//
+// The transport chain is: DefaultClientHttp -> middlewares -> retry -> circuit breaker -> bulkhead -> transport.
+type Client struct {
+	*http.Client
+	// BaseUrl is the base url to set on requests.
+	BaseUrl *url.URL
+	// Breaker is the circuit breaker of client, nil when it is not enabled.
+	Breaker *CircuitBreaker
+
+	transport       http.RoundTripper
+	retry           RetryPolicy
+	timeout         time.Duration
+	breaker         *BreakerConfig
+	bulkheadSize    int
+	bulkheadMaxWait time.Duration
+	middlewares     []Tripperware
+}
+
+// NewClient creates a client.
+func NewClient(opts ...ClientOption) *Client {
+	c := &Client{
+		retry:   DefaultRetryPolicy(),
+		timeout: 30 * time.Second,
+	}
+
+	for _, opt := range opts {
+		err := opt(c)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	rt := c.transport
+	if c.bulkheadSize > 0 {
+		rt = NewBulkhead(rt, c.bulkheadSize, c.bulkheadMaxWait)
+	}
+	if c.breaker != nil {
+		c.Breaker = NewCircuitBreaker(rt, *c.breaker)
+		rt = c.Breaker
+	}
+	rt = &RetryTransport{
+		Policy:    c.retry,
+		Transport: rt,
+	}
+	for i := len(c.middlewares) - 1; i >= 0; i-- {
+		rt = c.middlewares[i](rt)
+	}
+	rt = &DefaultClientHttp{
+		BaseUrl:   c.BaseUrl,
+		Transport: rt,
+	}
+	c.Client = &http.Client{
+		Timeout:   c.timeout,
+		Transport: rt,
+	}
+	return c
+}
+
+// Close closes the idle connections and the circuit breaker of client.
+func (c *Client) Close() error {
+	c.CloseIdleConnections()
+	if c.Breaker == nil {
+		return nil
+	}
+	return c.Breaker.Close()
+}
@@ -0,0 +1,452 @@
+// Package rest is port adapter via http/s protocol
+// # This manifest was generated by ymir. DO NOT EDIT.
+package rest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+var (
+	// ErrCircuitOpen is define error when circuit breaker rejects the request.
+	ErrCircuitOpen = errors.New("circuit breaker is open")
+	// ErrBulkheadFull is define error when bulkhead has no free slot for the request.
+	ErrBulkheadFull = errors.New("bulkhead is full")
+)
+
+// BreakerState - Custom type to hold value for circuit breaker state.
+type BreakerState int
+
+// Declare related constants for each BreakerState starting with index 0.
+const (
+	StateClosed BreakerState = iota
+	StateOpen
+	StateHalfOpen
+)
+
+// String - Creating common behavior - give the type a String function.
+func (s BreakerState) String() string {
+	return [...]string{
+		"closed",
+		"open",
+		"half-open",
+	}[s]
+}
+
+// Index - Return index of the Constant.
+func (s BreakerState) Index() int {
+	return int(s)
+}
+
+// BreakerConfig holds the configuration of circuit breaker.
+type BreakerConfig struct {
+	// WindowSize is the number of last calls used to calculate failure and slow-call rate.
+	WindowSize int
+	// MinimumCalls is the number of calls required before the rates are evaluated.
+	MinimumCalls int
+	// FailureRateThreshold opens the circuit when failure rate percentage is equal or greater.
+	FailureRateThreshold float64
+	// SlowCallDuration is the duration above which a call is considered slow, zero disables slow-call tracking.
+	SlowCallDuration time.Duration
+	// SlowCallRateThreshold opens the circuit when slow-call rate percentage is equal or greater.
+	SlowCallRateThreshold float64
+	// OpenTimeout is the duration of open state before probes are permitted.
+	OpenTimeout time.Duration
+	// HalfOpenProbes is the number of probe calls permitted in half-open state.
+	HalfOpenProbes int
+	// IdleTimeout evicts the closed circuit which is unused for the duration, so keys of KeyByRoute
+	// do not grow without bound.
+	IdleTimeout time.Duration
+	// KeyFunc returns the circuit key of request, KeyByHost by default.
+	KeyFunc func(r *http.Request) string
+	// IsFailure reports whether the call is failure, network errors and 5xx by default.
+	IsFailure func(resp *http.Response, err error) bool
+}
+
+// DefaultBreakerConfig returns the default circuit breaker configuration.
+func DefaultBreakerConfig() BreakerConfig {
+	return BreakerConfig{
+		WindowSize:            20,
+		MinimumCalls:          10,
+		FailureRateThreshold:  50,
+		SlowCallDuration:      5 * time.Second,
+		SlowCallRateThreshold: 100,
+		OpenTimeout:           30 * time.Second,
+		HalfOpenProbes:        3,
+		IdleTimeout:           10 * time.Minute,
+		KeyFunc:               KeyByHost,
+		IsFailure: func(resp *http.Response, err error) bool {
+			return err != nil || resp.StatusCode >= http.StatusInternalServerError
+		},
+	}
+}
+
+// KeyByHost returns circuit key of request by host.
+func KeyByHost(r *http.Request) string {
+	return r.URL.Host
+}
+
+// KeyByRoute returns circuit key of request by method, host and path.
+func KeyByRoute(r *http.Request) string {
+	return r.Method + " " + r.URL.Host + r.URL.Path
+}
+
+// CircuitBreaker is http.RoundTripper which stops calling a degraded downstream,
+// the circuit is kept per key of BreakerConfig.KeyFunc.
+type CircuitBreaker struct {
+	// Transport is the underlying HTTP transport.
+	// If nil, http.DefaultTransport is used.
+	Transport http.RoundTripper
+
+	cfg          BreakerConfig
+	mu           sync.Mutex
+	circuits     map[string]*circuit
+	lastSweep    time.Time
+	now          func() time.Time
+	transitions  metric.Int64Counter
+	registration metric.Registration
+}
+
+// NewCircuitBreaker creates a circuit breaker, zero fields of cfg are filled by DefaultBreakerConfig.
+func NewCircuitBreaker(rt http.RoundTripper, cfg BreakerConfig) *CircuitBreaker {
+	def := DefaultBreakerConfig()
+	if cfg.WindowSize < 1 {
+		cfg.WindowSize = def.WindowSize
+	}
+	if cfg.MinimumCalls < 1 {
+		cfg.MinimumCalls = def.MinimumCalls
+	}
+	if cfg.MinimumCalls > cfg.WindowSize {
+		cfg.MinimumCalls = cfg.WindowSize
+	}
+	if cfg.FailureRateThreshold <= 0 {
+		cfg.FailureRateThreshold = def.FailureRateThreshold
+	}
+	if cfg.SlowCallRateThreshold <= 0 {
+		cfg.SlowCallRateThreshold = def.SlowCallRateThreshold
+	}
+	if cfg.OpenTimeout <= 0 {
+		cfg.OpenTimeout = def.OpenTimeout
+	}
+	if cfg.HalfOpenProbes < 1 {
+		cfg.HalfOpenProbes = def.HalfOpenProbes
+	}
+	if cfg.IdleTimeout <= 0 {
+		cfg.IdleTimeout = def.IdleTimeout
+	}
+	if cfg.KeyFunc == nil {
+		cfg.KeyFunc = def.KeyFunc
+	}
+	if cfg.IsFailure == nil {
+		cfg.IsFailure = def.IsFailure
+	}
+	cb := &CircuitBreaker{
+		Transport: rt,
+		cfg:       cfg,
+		circuits:  make(map[string]*circuit),
+		now:       time.Now,
+	}
+	meter := otel.Meter(clientMeterName)
+	cb.transitions, _ = meter.Int64Counter("http.client.breaker.transitions",
+		metric.WithDescription("Number of circuit breaker state changes"))
+	gauge, err := meter.Int64ObservableGauge("http.client.breaker.state",
+		metric.WithDescription("Circuit breaker state: 0 closed, 1 open, 2 half-open"))
+	if err == nil {
+		cb.registration, err = meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+			cb.mu.Lock()
+			defer cb.mu.Unlock()
+			for key, c := range cb.circuits {
+				o.ObserveInt64(gauge, int64(c.state), metric.WithAttributes(attribute.String("breaker.key", key)))
+			}
+			return nil
+		}, gauge)
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("CircuitBreaker")
+	}
+	return cb
+}
+
+// Close unregisters the state gauge callback, the global meter keeps the breaker alive until it is closed.
+func (cb *CircuitBreaker) Close() error {
+	cb.mu.Lock()
+	registration := cb.registration
+	cb.registration = nil
+	cb.mu.Unlock()
+	if registration == nil {
+		return nil
+	}
+	return registration.Unregister()
+}
+
+// State returns the current circuit state of key.
+func (cb *CircuitBreaker) State(key string) BreakerState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	c, ok := cb.circuits[key]
+	if !ok {
+		return StateClosed
+	}
+	cb.refresh(key, c)
+	return c.state
+}
+
+// RoundTrip executes the request when the circuit of request key permits it.
+func (cb *CircuitBreaker) RoundTrip(req *http.Request) (*http.Response, error) {
+	rt := cb.Transport
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	key := cb.cfg.KeyFunc(req)
+	generation, err := cb.allow(req.Context(), key)
+	if err != nil {
+		return nil, err
+	}
+	start := cb.now()
+	resp, err := rt.RoundTrip(req)
+	if req.Context().Err() != nil && err != nil {
+		// canceled by caller, it says nothing about downstream health.
+		cb.release(key, generation)
+		return resp, err
+	}
+	slow := cb.cfg.SlowCallDuration > 0 && cb.now().Sub(start) >= cb.cfg.SlowCallDuration
+	cb.record(req.Context(), key, generation, cb.cfg.IsFailure(resp, err), slow)
+	return resp, err
+}
+
+// circuit holds the state and sliding window of a key.
+type circuit struct {
+	state      BreakerState
+	generation uint64
+	openedAt   time.Time
+	usedAt     time.Time
+	// window is ring buffer of last outcomes.
+	window   []outcome
+	next     int
+	count    int
+	failures int
+	slows    int
+	// probes in half-open state.
+	probes    int
+	succeeded int
+}
+
+type outcome struct {
+	failure bool
+	slow    bool
+}
+
+func (c *circuit) reset() {
+	c.next, c.count, c.failures, c.slows = 0, 0, 0, 0
+	c.probes, c.succeeded = 0, 0
+}
+
+func (c *circuit) push(o outcome) {
+	if c.count == len(c.window) {
+		old := c.window[c.next]
+		if old.failure {
+			c.failures--
+		}
+		if old.slow {
+			c.slows--
+		}
+	} else {
+		c.count++
+	}
+	c.window[c.next] = o
+	c.next = (c.next + 1) % len(c.window)
+	if o.failure {
+		c.failures++
+	}
+	if o.slow {
+		c.slows++
+	}
+}
+
+func (cb *CircuitBreaker) circuit(key string) *circuit {
+	c, ok := cb.circuits[key]
+	if !ok {
+		c = &circuit{window: make([]outcome, cb.cfg.WindowSize)}
+		cb.circuits[key] = c
+	}
+	return c
+}
+
+// refresh moves open circuit to half-open once the open timeout is passed.
+func (cb *CircuitBreaker) refresh(key string, c *circuit) {
+	if c.state == StateOpen && cb.now().Sub(c.openedAt) >= cb.cfg.OpenTimeout {
+		cb.transition(context.Background(), key, c, StateHalfOpen)
+	}
+}
+
+func (cb *CircuitBreaker) allow(ctx context.Context, key string) (uint64, error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	now := cb.now()
+	cb.sweep(now)
+	c := cb.circuit(key)
+	c.usedAt = now
+	cb.refresh(key, c)
+	switch c.state {
+	case StateOpen:
+		return 0, ErrCircuitOpen
+	case StateHalfOpen:
+		if c.probes >= cb.cfg.HalfOpenProbes {
+			return 0, ErrCircuitOpen
+		}
+		c.probes++
+		log.Ctx(ctx).Debug().Str("breaker", key).Int("probe", c.probes).Msg("circuit breaker probe")
+	}
+	return c.generation, nil
+}
+
+// sweep removes the closed circuits which are idle, open and half-open circuits are kept.
+func (cb *CircuitBreaker) sweep(now time.Time) {
+	if now.Sub(cb.lastSweep) < cb.cfg.IdleTimeout {
+		return
+	}
+	cb.lastSweep = now
+	for key, c := range cb.circuits {
+		if c.state == StateClosed && now.Sub(c.usedAt) >= cb.cfg.IdleTimeout {
+			delete(cb.circuits, key)
+		}
+	}
+}
+
+// release gives back the probe slot of a call which outcome is ignored.
+func (cb *CircuitBreaker) release(key string, generation uint64) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	c := cb.circuit(key)
+	if c.generation == generation && c.state == StateHalfOpen && c.probes > 0 {
+		c.probes--
+	}
+}
+
+func (cb *CircuitBreaker) record(ctx context.Context, key string, generation uint64, failure, slow bool) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	c := cb.circuit(key)
+	if c.generation != generation {
+		// outcome of a call started in previous state.
+		return
+	}
+	switch c.state {
+	case StateClosed:
+		c.push(outcome{failure: failure, slow: slow})
+		if c.count < cb.cfg.MinimumCalls {
+			return
+		}
+		failureRate := float64(c.failures) * 100 / float64(c.count)
+		slowRate := float64(c.slows) * 100 / float64(c.count)
+		if failureRate >= cb.cfg.FailureRateThreshold ||
+			(cb.cfg.SlowCallDuration > 0 && slowRate >= cb.cfg.SlowCallRateThreshold) {
+			cb.transition(ctx, key, c, StateOpen)
+		}
+	case StateHalfOpen:
+		if failure || slow {
+			cb.transition(ctx, key, c, StateOpen)
+			return
+		}
+		c.succeeded++
+		if c.succeeded >= cb.cfg.HalfOpenProbes {
+			cb.transition(ctx, key, c, StateClosed)
+		}
+	}
+}
+
+func (cb *CircuitBreaker) transition(ctx context.Context, key string, c *circuit, to BreakerState) {
+	from := c.state
+	c.state = to
+	c.generation++
+	c.reset()
+	if to == StateOpen {
+		c.openedAt = cb.now()
+	}
+	cb.transitions.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("breaker.key", key),
+		attribute.String("breaker.from", from.String()),
+		attribute.String("breaker.to", to.String()),
+	))
+	log.Warn().
+		Str("breaker", key).
+		Str("from", from.String()).
+		Str("to", to.String()).
+		Msg("circuit breaker state changed")
+}
+
+// Bulkhead is http.RoundTripper which caps the concurrent in-flight requests,
+// a slot is held until the response body is closed.
+type Bulkhead struct {
+	// Transport is the underlying HTTP transport.
+	// If nil, http.DefaultTransport is used.
+	Transport http.RoundTripper
+
+	slots    chan struct{}
+	maxWait  time.Duration
+	inflight metric.Int64UpDownCounter
+}
+
+// NewBulkhead creates a bulkhead with maxConcurrent slots, a request waits at most maxWait for a free slot.
+func NewBulkhead(rt http.RoundTripper, maxConcurrent int, maxWait time.Duration) *Bulkhead {
+	if maxConcurrent < 1 {
+		maxConcurrent = 1
+	}
+	b := &Bulkhead{
+		Transport: rt,
+		slots:     make(chan struct{}, maxConcurrent),
+		maxWait:   maxWait,
+	}
+	b.inflight, _ = otel.Meter(clientMeterName).Int64UpDownCounter("http.client.bulkhead.inflight",
+		metric.WithDescription("Number of in-flight requests held by bulkhead"))
+	return b
+}
+
+// RoundTrip executes the request once a slot is acquired.
+func (b *Bulkhead) RoundTrip(req *http.Request) (*http.Response, error) {
+	rt := b.Transport
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	ctx := req.Context()
+	select {
+	case b.slots <- struct{}{}:
+	default:
+		if b.maxWait <= 0 {
+			return nil, ErrBulkheadFull
+		}
+		timer := time.NewTimer(b.maxWait)
+		defer timer.Stop()
+		select {
+		case b.slots <- struct{}{}:
+		case <-timer.C:
+			return nil, ErrBulkheadFull
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	attrs := metric.WithAttributes(attribute.String("net.peer.name", req.URL.Host))
+	b.inflight.Add(ctx, 1, attrs)
+	var once sync.Once
+	release := func() {
+		once.Do(func() {
+			<-b.slots
+			b.inflight.Add(context.Background(), -1, attrs)
+		})
+	}
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: release}
+	return resp, nil
+}
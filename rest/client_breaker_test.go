@@ -0,0 +1,187 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	sdkMetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+type roundTripFunc func(r *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func stubResponse(code int) *http.Response {
+	return &http.Response{
+		StatusCode: code,
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader("")),
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	is := assert.New(t)
+	var (
+		code  = http.StatusInternalServerError
+		clock = time.Now()
+	)
+	cb := NewCircuitBreaker(roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		return stubResponse(code), nil
+	}), BreakerConfig{
+		WindowSize:           4,
+		MinimumCalls:         4,
+		FailureRateThreshold: 50,
+		OpenTimeout:          time.Second,
+		HalfOpenProbes:       2,
+	})
+	cb.now = func() time.Time { return clock }
+	req, _ := http.NewRequest(http.MethodGet, "http://downstream/items", http.NoBody)
+
+	for i := 0; i < 3; i++ {
+		_, err := cb.RoundTrip(req)
+		is.NoError(err)
+		is.Equal(StateClosed, cb.State("downstream"))
+	}
+	_, err := cb.RoundTrip(req)
+	is.NoError(err)
+	is.Equal(StateOpen, cb.State("downstream"))
+
+	_, err = cb.RoundTrip(req)
+	is.True(errors.Is(err, ErrCircuitOpen))
+
+	clock = clock.Add(time.Second)
+	is.Equal(StateHalfOpen, cb.State("downstream"))
+	_, err = cb.RoundTrip(req)
+	is.NoError(err)
+	is.Equal(StateOpen, cb.State("downstream"), "failed probe opens the circuit again")
+
+	code = http.StatusOK
+	clock = clock.Add(time.Second)
+	for i := 0; i < 2; i++ {
+		_, err = cb.RoundTrip(req)
+		is.NoError(err)
+	}
+	is.Equal(StateClosed, cb.State("downstream"))
+}
+
+func TestCircuitBreakerEviction(t *testing.T) {
+	is := assert.New(t)
+	clock := time.Now()
+	cb := NewCircuitBreaker(roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		if r.URL.Path == "/down" {
+			return stubResponse(http.StatusInternalServerError), nil
+		}
+		return stubResponse(http.StatusOK), nil
+	}), BreakerConfig{WindowSize: 1, IdleTimeout: time.Minute, OpenTimeout: time.Hour, KeyFunc: KeyByRoute})
+	defer cb.Close()
+	cb.now = func() time.Time { return clock }
+	call := func(path string) {
+		req, _ := http.NewRequest(http.MethodGet, "http://downstream"+path, http.NoBody)
+		_, _ = cb.RoundTrip(req)
+	}
+
+	for i := 0; i < 100; i++ {
+		call("/items/" + strconv.Itoa(i))
+	}
+	call("/down")
+	is.Equal(StateOpen, cb.State("GET downstream/down"))
+	is.Len(cb.circuits, 101)
+
+	clock = clock.Add(30 * time.Second)
+	call("/items/0")
+	clock = clock.Add(40 * time.Second)
+	call("/items/100")
+	is.Len(cb.circuits, 3, "the idle closed circuits are evicted")
+	is.Contains(cb.circuits, "GET downstream/items/0")
+	is.Equal(StateOpen, cb.State("GET downstream/down"), "the open circuit is kept")
+}
+
+func TestCircuitBreakerSlowCall(t *testing.T) {
+	is := assert.New(t)
+	clock := time.Now()
+	cb := NewCircuitBreaker(nil, BreakerConfig{
+		WindowSize:            2,
+		MinimumCalls:          2,
+		SlowCallDuration:      time.Second,
+		SlowCallRateThreshold: 100,
+	})
+	cb.Transport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		clock = clock.Add(2 * time.Second)
+		return stubResponse(http.StatusOK), nil
+	})
+	cb.now = func() time.Time { return clock }
+	req, _ := http.NewRequest(http.MethodGet, "http://slow/items", http.NoBody)
+
+	_, _ = cb.RoundTrip(req)
+	_, _ = cb.RoundTrip(req)
+	is.Equal(StateOpen, cb.State("slow"))
+}
+
+func TestBulkhead(t *testing.T) {
+	is := assert.New(t)
+	b := NewBulkhead(roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		return stubResponse(http.StatusOK), nil
+	}), 1, 10*time.Millisecond)
+	req, _ := http.NewRequest(http.MethodGet, "http://downstream/items", http.NoBody)
+
+	resp, err := b.RoundTrip(req)
+	is.NoError(err)
+	_, err = b.RoundTrip(req)
+	is.True(errors.Is(err, ErrBulkheadFull))
+
+	is.NoError(resp.Body.Close())
+	resp, err = b.RoundTrip(req)
+	is.NoError(err)
+	is.NoError(resp.Body.Close())
+}
+
+func TestCircuitBreakerGauge(t *testing.T) {
+	is := assert.New(t)
+	reader := sdkMetric.NewManualReader()
+	prev := otel.GetMeterProvider()
+	otel.SetMeterProvider(sdkMetric.NewMeterProvider(sdkMetric.WithReader(reader)))
+	t.Cleanup(func() { otel.SetMeterProvider(prev) })
+	// states returns the observed gauge points by breaker key.
+	states := func() map[string]int64 {
+		var rm metricdata.ResourceMetrics
+		is.NoError(reader.Collect(context.Background(), &rm))
+		points := make(map[string]int64)
+		for _, sm := range rm.ScopeMetrics {
+			for _, m := range sm.Metrics {
+				gauge, ok := m.Data.(metricdata.Gauge[int64])
+				if m.Name != "http.client.breaker.state" || !ok {
+					continue
+				}
+				for _, p := range gauge.DataPoints {
+					key, _ := p.Attributes.Value("breaker.key")
+					points[key.AsString()] = p.Value
+				}
+			}
+		}
+		return points
+	}
+
+	c := NewClient(WithCircuitBreaker(BreakerConfig{WindowSize: 1, MinimumCalls: 1}))
+	c.Breaker.Transport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		return stubResponse(http.StatusInternalServerError), nil
+	})
+	req, _ := http.NewRequest(http.MethodPost, "http://gauge/items", http.NoBody)
+	_, err := c.Breaker.RoundTrip(req)
+	is.NoError(err)
+	is.Equal(int64(StateOpen), states()["gauge"])
+
+	is.NoError(c.Close())
+	is.NoError(c.Close())
+	is.NotContains(states(), "gauge", "the closed breaker is not observed anymore")
+}
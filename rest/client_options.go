@@ -0,0 +1,93 @@
+// Package rest is port adapter via http/s protocol
+// # This manifest was generated by ymir. DO NOT EDIT.
+package rest
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// ClientOption is client type return func.
+type ClientOption = func(c *Client) error
+
+// WithBaseUrl will assign to base url field client.
+func WithBaseUrl(baseUrl string) ClientOption {
+	return func(c *Client) error {
+		u, err := url.Parse(baseUrl)
+		if err != nil {
+			return err
+		}
+		c.BaseUrl = u
+		return nil
+	}
+}
+
+// WithClientTransport will assign to underlying transport field client.
+func WithClientTransport(rt http.RoundTripper) ClientOption {
+	return func(c *Client) error {
+		c.transport = rt
+		return nil
+	}
+}
+
+// WithClientTimeout will assign to overall timeout field client, it covers every attempt and backoff.
+func WithClientTimeout(timeout time.Duration) ClientOption {
+	return func(c *Client) error {
+		c.timeout = timeout
+		return nil
+	}
+}
+
+// WithRetryPolicy will assign to retry policy field client.
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(c *Client) error {
+		c.retry = policy
+		return nil
+	}
+}
+
+// WithMaxAttempts will assign to max attempts field retry policy.
+func WithMaxAttempts(attempts int) ClientOption {
+	return func(c *Client) error {
+		c.retry.MaxAttempts = attempts
+		return nil
+	}
+}
+
+// WithAttemptTimeout will assign to per-attempt timeout field retry policy.
+func WithAttemptTimeout(timeout time.Duration) ClientOption {
+	return func(c *Client) error {
+		c.retry.AttemptTimeout = timeout
+		return nil
+	}
+}
+
+// WithCircuitBreaker will enable circuit breaker of client, zero fields of cfg are filled by DefaultBreakerConfig.
+func WithCircuitBreaker(cfg BreakerConfig) ClientOption {
+	return func(c *Client) error {
+		c.breaker = &cfg
+		return nil
+	}
+}
+
+// WithBulkhead will cap concurrent in-flight requests of client.
+func WithBulkhead(maxConcurrent int, maxWait time.Duration) ClientOption {
+	return func(c *Client) error {
+		if maxConcurrent < 1 {
+			return errors.New("bulkhead: max concurrent must be greater than zero")
+		}
+		c.bulkheadSize = maxConcurrent
+		c.bulkheadMaxWait = maxWait
+		return nil
+	}
+}
+
+// WithClientMiddleware will wrap the client transport, the first middleware is the outermost.
+func WithClientMiddleware(mw ...Tripperware) ClientOption {
+	return func(c *Client) error {
+		c.middlewares = append(c.middlewares, mw...)
+		return nil
+	}
+}
@@ -0,0 +1,278 @@
+// Package rest is port adapter via http/s protocol
+// # This manifest was generated by ymir. DO NOT EDIT.
+package rest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	clientMeterName = "rest.client"
+	// maxDrainBody is the limit of response body to read before retrying, so the connection can be reused.
+	maxDrainBody = 4 << 10 // 4 KB
+)
+
+// RetryPolicy holds the configuration of retrying outbound requests.
+type RetryPolicy struct {
+	// MaxAttempts is the total attempts including the first one, less than 2 disables retrying.
+	MaxAttempts int
+	// BaseDelay is the backoff of the first retry, it is doubled on every next retry.
+	BaseDelay time.Duration
+	// MaxDelay is the upper bound of backoff and Retry-After delay.
+	MaxDelay time.Duration
+	// AttemptTimeout bounds every single attempt, zero means no per-attempt timeout.
+	AttemptTimeout time.Duration
+	// Methods are the retryable http methods, the idempotent ones by default.
+	Methods []string
+	// StatusCodes are the retryable response status codes.
+	StatusCodes []int
+	// RetryOn overrides the decision of StatusCodes and transient network errors when it is set.
+	RetryOn func(resp *http.Response, err error) bool
+}
+
+// DefaultRetryPolicy returns policy which retries idempotent methods on 429/502/503/504 and transient network errors.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   100 * time.Millisecond,
+		MaxDelay:    5 * time.Second,
+		Methods: []string{
+			http.MethodGet,
+			http.MethodHead,
+			http.MethodOptions,
+			http.MethodPut,
+			http.MethodDelete,
+			http.MethodTrace,
+		},
+		StatusCodes: []int{
+			http.StatusTooManyRequests,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+	}
+}
+
+func (p RetryPolicy) retryableMethod(method string) bool {
+	for _, m := range p.Methods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+func (p RetryPolicy) retryable(resp *http.Response, err error) bool {
+	if p.RetryOn != nil {
+		return p.RetryOn(resp, err)
+	}
+	if err != nil {
+		return transient(err)
+	}
+	for _, code := range p.StatusCodes {
+		if resp.StatusCode == code {
+			return true
+		}
+	}
+	return false
+}
+
+// transient reports whether err is timeout, refused or reset connection or truncated response,
+// e.g. tls verification, unsupported scheme and malformed url errors are not retried.
+func transient(err error) bool {
+	if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// backoff returns exponential backoff with full jitter, Retry-After of response is preferred when it is present.
+func (p RetryPolicy) backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if delay, ok := retryAfter(resp.Header.Get(HeaderRetryAfter.String())); ok {
+			if p.MaxDelay > 0 && delay > p.MaxDelay {
+				delay = p.MaxDelay
+			}
+			return delay
+		}
+	}
+	ceil := p.BaseDelay << uint(attempt-1)
+	if ceil <= 0 || (p.MaxDelay > 0 && ceil > p.MaxDelay) {
+		ceil = p.MaxDelay
+	}
+	if ceil <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(ceil) + 1)) //nolint:gosec // jitter does not need crypto random.
+}
+
+// retryAfter parses Retry-After header value in delay seconds or http date.
+func retryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		delay := time.Until(at)
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+	return 0, false
+}
+
+// RetryTransport is http.RoundTripper which retries the request by RetryPolicy.
+// Request body is buffered when it can not be rewound by GetBody.
+type RetryTransport struct {
+	// Transport is the underlying HTTP transport.
+	// If nil, http.DefaultTransport is used.
+	Transport http.RoundTripper
+	// Policy is the retry configuration.
+	Policy RetryPolicy
+
+	once     sync.Once
+	retries  metric.Int64Counter
+	attempts metric.Int64Histogram
+}
+
+func (t *RetryTransport) instruments() {
+	meter := otel.Meter(clientMeterName)
+	t.retries, _ = meter.Int64Counter("http.client.retries",
+		metric.WithDescription("Number of retried outbound requests"))
+	t.attempts, _ = meter.Int64Histogram("http.client.attempts",
+		metric.WithDescription("Number of attempts per outbound request"))
+}
+
+// RoundTrip executes the request and retries it while the policy allows.
+func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.once.Do(t.instruments)
+	rt := t.Transport
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	maxAttempts := t.Policy.MaxAttempts
+	if maxAttempts < 1 || !t.Policy.retryableMethod(req.Method) {
+		maxAttempts = 1
+	}
+	if maxAttempts > 1 {
+		var err error
+		if req, err = rewindable(req); err != nil {
+			return nil, err
+		}
+	}
+
+	var (
+		ctx   = req.Context()
+		span  = trace.SpanFromContext(ctx)
+		attrs = metric.WithAttributes(
+			attribute.String("http.method", req.Method),
+			attribute.String("net.peer.name", req.URL.Host),
+		)
+	)
+	for attempt := 1; ; attempt++ {
+		attemptReq := req
+		if attempt > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			attemptReq = req.Clone(ctx)
+			attemptReq.Body = body
+		}
+		cancel := context.CancelFunc(func() {})
+		if t.Policy.AttemptTimeout > 0 {
+			var attemptCtx context.Context
+			attemptCtx, cancel = context.WithTimeout(ctx, t.Policy.AttemptTimeout)
+			attemptReq = attemptReq.WithContext(attemptCtx)
+		}
+
+		resp, err := rt.RoundTrip(attemptReq)
+		if attempt >= maxAttempts || ctx.Err() != nil || !t.Policy.retryable(resp, err) {
+			span.SetAttributes(attribute.Int("http.retry_count", attempt-1))
+			t.attempts.Record(ctx, int64(attempt), attrs)
+			if err != nil {
+				cancel()
+				return nil, err
+			}
+			resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+			return resp, nil
+		}
+
+		delay := t.Policy.backoff(attempt, resp)
+		event := []attribute.KeyValue{
+			attribute.Int("http.retry.attempt", attempt),
+			attribute.Int64("http.retry.delay_ms", delay.Milliseconds()),
+		}
+		if err != nil {
+			event = append(event, attribute.String("error", err.Error()))
+		} else {
+			event = append(event, attribute.Int("http.status_code", resp.StatusCode))
+			_, _ = io.CopyN(io.Discard, resp.Body, maxDrainBody)
+			_ = resp.Body.Close()
+		}
+		cancel()
+		span.AddEvent("http.retry", trace.WithAttributes(event...))
+		t.retries.Add(ctx, 1, attrs)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// rewindable returns request which body can be read again by GetBody.
+func rewindable(req *http.Request) (*http.Request, error) {
+	if req.Body == nil || req.Body == http.NoBody || req.GetBody != nil {
+		return req, nil
+	}
+	b, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	newReq := req.Clone(req.Context())
+	newReq.Body = io.NopCloser(bytes.NewReader(b))
+	newReq.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(b)), nil
+	}
+	return newReq, nil
+}
+
+// cancelBody releases the per-attempt context once the response body is closed.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+// Close closes the body and cancels the attempt context.
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
@@ -0,0 +1,274 @@
+package rest
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// onceReader is the body without GetBody, so the transport must buffer it to retry.
+type onceReader struct {
+	io.Reader
+}
+
+func TestRetryAfter(t *testing.T) {
+	is := assert.New(t)
+	for _, tc := range []struct {
+		value string
+		delay time.Duration
+		ok    bool
+	}{
+		{value: "", ok: false},
+		{value: "2", delay: 2 * time.Second, ok: true},
+		{value: "0", delay: 0, ok: true},
+		{value: "-1", ok: false},
+		{value: "soon", ok: false},
+		{value: time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat), delay: 0, ok: true},
+	} {
+		delay, ok := retryAfter(tc.value)
+		is.Equal(tc.ok, ok, tc.value)
+		is.Equal(tc.delay, delay, tc.value)
+	}
+	delay, ok := retryAfter(time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+	is.True(ok)
+	is.InDelta(time.Hour, delay, float64(2*time.Second))
+}
+
+func TestRetryTransport(t *testing.T) {
+	// step answers the attempt n, starting at 1.
+	type step func(w http.ResponseWriter, n int)
+	status := func(codes ...int) step {
+		return func(w http.ResponseWriter, n int) {
+			if n > len(codes) {
+				n = len(codes)
+			}
+			w.WriteHeader(codes[n-1])
+			_, _ = w.Write([]byte("ok"))
+		}
+	}
+	fast := RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   time.Millisecond,
+		MaxDelay:    5 * time.Millisecond,
+		Methods:     DefaultRetryPolicy().Methods,
+		StatusCodes: DefaultRetryPolicy().StatusCodes,
+	}
+	slow := fast
+	slow.BaseDelay, slow.MaxDelay = time.Hour, time.Hour
+
+	for _, tc := range []struct {
+		name     string
+		method   string
+		body     io.Reader
+		policy   RetryPolicy
+		step     step
+		code     int
+		attempts int
+	}{
+		{
+			name: "retries until success", method: http.MethodGet, policy: fast,
+			step: status(503, 502, 200), code: 200, attempts: 3,
+		},
+		{
+			name: "gives up after max attempts", method: http.MethodGet, policy: fast,
+			step: status(503), code: 503, attempts: 3,
+		},
+		{
+			name: "does not retry other status", method: http.MethodGet, policy: fast,
+			step: status(500, 200), code: 500, attempts: 1,
+		},
+		{
+			name: "does not retry non idempotent method", method: http.MethodPost, policy: fast,
+			body: strings.NewReader("payload"), step: status(503, 200), code: 503, attempts: 1,
+		},
+		{
+			name: "rewinds buffered body", method: http.MethodPut, policy: fast,
+			body: onceReader{strings.NewReader("payload")}, step: status(503, 200), code: 200, attempts: 2,
+		},
+		{
+			name: "rewinds body by GetBody", method: http.MethodPut, policy: fast,
+			body: strings.NewReader("payload"), step: status(429, 200), code: 200, attempts: 2,
+		},
+		{
+			name: "retry on overrides status codes", method: http.MethodGet,
+			policy: func() RetryPolicy {
+				p := fast
+				p.RetryOn = func(resp *http.Response, err error) bool {
+					return err == nil && resp.StatusCode == http.StatusInternalServerError
+				}
+				return p
+			}(),
+			step: status(500, 503), code: 503, attempts: 2,
+		},
+		{
+			name: "retry after seconds is preferred over backoff", method: http.MethodGet, policy: slow,
+			step: func(w http.ResponseWriter, n int) {
+				if n == 1 {
+					w.Header().Set(HeaderRetryAfter.String(), "0")
+					w.WriteHeader(http.StatusTooManyRequests)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			},
+			code: 200, attempts: 2,
+		},
+		{
+			name: "retry after date is preferred over backoff", method: http.MethodGet, policy: slow,
+			step: func(w http.ResponseWriter, n int) {
+				if n == 1 {
+					w.Header().Set(HeaderRetryAfter.String(), time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat))
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			},
+			code: 200, attempts: 2,
+		},
+		{
+			name: "retry after is capped by max delay", method: http.MethodGet,
+			policy: func() RetryPolicy {
+				p := slow
+				p.MaxDelay = time.Millisecond
+				return p
+			}(),
+			step: func(w http.ResponseWriter, n int) {
+				if n == 1 {
+					w.Header().Set(HeaderRetryAfter.String(), "3600")
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			},
+			code: 200, attempts: 2,
+		},
+		{
+			name: "retries the attempt timeout", method: http.MethodGet,
+			policy: func() RetryPolicy {
+				p := fast
+				p.AttemptTimeout = 50 * time.Millisecond
+				return p
+			}(),
+			step: func(w http.ResponseWriter, n int) {
+				if n == 1 {
+					time.Sleep(200 * time.Millisecond)
+				}
+				_, _ = w.Write([]byte("ok"))
+			},
+			code: 200, attempts: 2,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			is := assert.New(t)
+			var (
+				mu     sync.Mutex
+				n      int
+				bodies []string
+			)
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				b, _ := io.ReadAll(r.Body)
+				mu.Lock()
+				n++
+				attempt := n
+				bodies = append(bodies, string(b))
+				mu.Unlock()
+				tc.step(w, attempt)
+			}))
+			defer srv.Close()
+
+			req, err := http.NewRequest(tc.method, srv.URL, tc.body)
+			is.NoError(err)
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			resp, err := (&RetryTransport{Policy: tc.policy}).RoundTrip(req.WithContext(ctx))
+			is.NoError(err)
+			is.Equal(tc.code, resp.StatusCode)
+			// the body of the returned attempt is readable, its timeout is released on close.
+			_, err = io.ReadAll(resp.Body)
+			is.NoError(err)
+			is.NoError(resp.Body.Close())
+
+			mu.Lock()
+			defer mu.Unlock()
+			is.Equal(tc.attempts, n)
+			if tc.body != nil {
+				for _, b := range bodies {
+					is.Equal("payload", b)
+				}
+			}
+		})
+	}
+}
+
+func TestRetryTransportContext(t *testing.T) {
+	is := assert.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+	policy := DefaultRetryPolicy()
+	policy.BaseDelay, policy.MaxDelay = time.Hour, time.Hour
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, http.NoBody)
+	start := time.Now()
+	_, err := (&RetryTransport{Policy: policy}).RoundTrip(req)
+	is.True(errors.Is(err, context.DeadlineExceeded))
+	is.Less(time.Since(start), time.Second, "the backoff is interrupted by the request context")
+
+	// the network error is returned after the last attempt.
+	policy = DefaultRetryPolicy()
+	policy.BaseDelay = time.Millisecond
+	calls := 0
+	rt := &RetryTransport{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			calls++
+			return nil, &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+		}),
+		Policy: policy,
+	}
+	req, _ = http.NewRequest(http.MethodGet, "http://downstream", http.NoBody)
+	_, err = rt.RoundTrip(req)
+	is.ErrorIs(err, syscall.ECONNREFUSED)
+	is.Equal(3, calls)
+}
+
+func TestRetryTransient(t *testing.T) {
+	is := assert.New(t)
+	for err, retry := range map[error]bool{
+		&net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}:             true,
+		&url.Error{Op: "Get", URL: "http://downstream", Err: io.ErrUnexpectedEOF}: true,
+		context.DeadlineExceeded:                        true,
+		x509.UnknownAuthorityError{}:                    false,
+		errors.New(`unsupported protocol scheme "ftp"`): false,
+		&url.Error{Op: "parse", URL: "::", Err: errors.New("missing protocol scheme")}: false,
+		ErrCircuitOpen:  false,
+		ErrBulkheadFull: false,
+	} {
+		is.Equal(retry, DefaultRetryPolicy().retryable(nil, err), err.Error())
+	}
+}
+
+func TestCancelBody(t *testing.T) {
+	is := assert.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	body := &cancelBody{ReadCloser: io.NopCloser(strings.NewReader("ok")), cancel: cancel}
+	b, err := io.ReadAll(body)
+	is.NoError(err)
+	is.Equal("ok", string(b))
+	is.NoError(ctx.Err())
+	is.NoError(body.Close())
+	is.ErrorIs(ctx.Err(), context.Canceled)
+}
@@ -0,0 +1,328 @@
+// Package rest is port adapter via http/s protocol
+// # This manifest was generated by ymir. DO NOT EDIT.
+package rest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// maxErrorBody is the limit of non envelope error body kept as message.
+const maxErrorBody = 512
+
+var pathParamRegex = regexp.MustCompile(`\{([^}:]+)(:[^}]*)?}`)
+
+// Doer is the interface of http client which sends the request, it is implemented by http.Client and Client.
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Result holds the decoded response definition for the Response entity.
+type Result[T any] struct {
+	Meta       `json:"meta"`
+	Version    `json:"version"`
+	Pagination `json:"pagination,omitempty"`
+	Data       T           `json:"data,omitempty"`
+	StatusCode int         `json:"-"`
+	Header     http.Header `json:"-"`
+}
+
+// ResponseError is define error of response envelope which meta code is not success.
+type ResponseError struct {
+	StatusCode int
+	Code       string
+	Message    string
+}
+
+// Error returns the meta code and message of response.
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("rest: response %s: %s", e.Code, e.Message)
+}
+
+// Do sends the typed request and decodes data of response envelope.
+func Do[Req, Resp any](ctx context.Context, client Doer, method, path string, req Req) (Resp, error) {
+	result, err := DoResult[Req, Resp](ctx, client, method, path, req)
+	if err != nil {
+		var zero Resp
+		return zero, err
+	}
+	return result.Data, nil
+}
+
+// DoResult sends the typed request and decodes the whole response envelope.
+// Meta code of failed response is returned as *ResponseError.
+func DoResult[Req, Resp any](ctx context.Context, client Doer, method, path string, req Req) (*Result[Resp], error) {
+	httpReq, err := NewRequest(ctx, method, path, req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	return decodeResult[Resp](resp)
+}
+
+// NewRequest encodes the typed request into http request.
+// Fields tagged by `schema` fill the `{name}` placeholders of path, the remaining ones and fields
+// tagged by `query` are sent as query string. Other methods than GET, DELETE and HEAD send the request
+// as JSON body without the path and `query` fields, the `schema` fields are sent in the body only,
+// unless they are skipped by `json:"-"`.
+func NewRequest[Req any](ctx context.Context, method, path string, req Req) (*http.Request, error) {
+	var hasBody bool
+	switch method {
+	case http.MethodGet, http.MethodDelete, http.MethodHead:
+	default:
+		hasBody = true
+	}
+	values, keys := taggedValues(req, "schema")
+	var omitted []string
+	path = pathParamRegex.ReplaceAllStringFunc(path, func(placeholder string) string {
+		name := pathParamRegex.FindStringSubmatch(placeholder)[1]
+		value, ok := values[name]
+		if !ok || len(value) < 1 {
+			return placeholder
+		}
+		delete(values, name)
+		omitted = append(omitted, keys[name])
+		return url.PathEscape(value[0])
+	})
+	if pathParamRegex.MatchString(path) {
+		return nil, fmt.Errorf("rest: missing path params of %s", path)
+	}
+	u, err := url.Parse(path)
+	if err != nil {
+		return nil, err
+	}
+	queryValues, queryKeys := taggedValues(req, tagQuery)
+	for _, key := range queryKeys {
+		omitted = append(omitted, key)
+	}
+	if hasBody {
+		for name := range values {
+			if keys[name] != "-" {
+				delete(values, name)
+			}
+		}
+	}
+	query := u.Query()
+	for _, vv := range []url.Values{values, queryValues} {
+		for k, v := range vv {
+			query[k] = append(query[k], v...)
+		}
+	}
+	u.RawQuery = query.Encode()
+
+	var body io.Reader
+	if hasBody {
+		b, err := jsonBody(req, omitted)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(b)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		httpReq.Header.Set(HeaderContentType.String(), MIMEApplicationJSON.String())
+	}
+	httpReq.Header.Set("Accept", MIMEApplicationJSON.String())
+	return httpReq, nil
+}
+
+// jsonBody encodes the request without the json keys of omitted fields.
+func jsonBody(req any, omitted []string) ([]byte, error) {
+	b, err := json.Marshal(req)
+	if err != nil || len(omitted) < 1 {
+		return b, err
+	}
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(b, &fields) != nil {
+		return b, nil
+	}
+	for _, key := range omitted {
+		delete(fields, key)
+	}
+	return json.Marshal(fields)
+}
+
+func decodeResult[Resp any](resp *http.Response) (*Result[Resp], error) {
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	result := &Result[Resp]{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+	}
+	if err = json.Unmarshal(b, result); err != nil {
+		if resp.StatusCode >= http.StatusBadRequest {
+			message := strings.TrimSpace(string(b))
+			if len(message) > maxErrorBody {
+				message = message[:maxErrorBody]
+			}
+			if message == "" {
+				message = http.StatusText(resp.StatusCode)
+			}
+			return nil, &ResponseError{
+				StatusCode: resp.StatusCode,
+				Code:       strconv.Itoa(resp.StatusCode),
+				Message:    message,
+			}
+		}
+		return nil, err
+	}
+	code, _ := strconv.Atoi(result.Meta.Code)
+	if resp.StatusCode >= http.StatusBadRequest || code >= http.StatusBadRequest {
+		if result.Meta.Code == "" {
+			result.Meta.Code = strconv.Itoa(resp.StatusCode)
+		}
+		return result, &ResponseError{
+			StatusCode: resp.StatusCode,
+			Code:       result.Meta.Code,
+			Message:    result.Meta.Message,
+		}
+	}
+	return result, nil
+}
+
+// taggedValues returns values of fields which are tagged by tag, and the json key of every tagged field.
+func taggedValues(v any, tag string) (url.Values, map[string]string) {
+	values, keys := url.Values{}, map[string]string{}
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return values, keys
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return values, keys
+	}
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		name := strings.SplitN(field.Tag.Get(tag), ",", 2)[0]
+		if !field.IsExported() || name == "" || name == "-" {
+			continue
+		}
+		keys[name] = field.Name
+		if key := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]; key != "" {
+			keys[name] = key
+		}
+		fv := rv.Field(i)
+		if fv.Kind() == reflect.Slice {
+			for j := 0; j < fv.Len(); j++ {
+				if s, ok := formatValue(fv.Index(j)); ok {
+					values.Add(name, s)
+				}
+			}
+			continue
+		}
+		if s, ok := formatValue(fv); ok && s != "" {
+			values.Set(name, s)
+		}
+	}
+	return values, keys
+}
+
+func formatValue(v reflect.Value) (string, bool) {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return "", false
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), true
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), true
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64), true
+	default:
+		if s, ok := v.Interface().(fmt.Stringer); ok {
+			return s.String(), true
+		}
+		return "", false
+	}
+}
+
+// Pager iterates the pages of list endpoint by pagination of response envelope.
+type Pager[Req, Resp any] struct {
+	client  Doer
+	method  string
+	path    string
+	req     Req
+	setPage func(req *Req, page int)
+
+	page    int
+	last    int
+	current *Result[Resp]
+	err     error
+}
+
+// NewPager creates a pager, setPage assigns the page number into the request before every call.
+func NewPager[Req, Resp any](client Doer, method, path string, req Req, setPage func(req *Req, page int)) *Pager[Req, Resp] {
+	return &Pager[Req, Resp]{
+		client:  client,
+		method:  method,
+		path:    path,
+		req:     req,
+		setPage: setPage,
+		last:    -1,
+	}
+}
+
+// Next fetches the next page, it returns false when there is no more page or an error occurred.
+func (p *Pager[Req, Resp]) Next(ctx context.Context) bool {
+	if p.err != nil || (p.last >= 0 && p.page >= p.last) {
+		return false
+	}
+	p.page++
+	p.setPage(&p.req, p.page)
+	result, err := DoResult[Req, Resp](ctx, p.client, p.method, p.path, p.req)
+	if err != nil {
+		p.err = err
+		return false
+	}
+	p.current = result
+	p.last = lastPage(result.Pagination)
+	return true
+}
+
+// Result returns the current page.
+func (p *Pager[Req, Resp]) Result() *Result[Resp] {
+	return p.current
+}
+
+// Err returns the error which stopped the iteration.
+func (p *Pager[Req, Resp]) Err() error {
+	return p.err
+}
+
+// lastPage returns the last page number, zero when the response is not paginated.
+func lastPage(p Pagination) int {
+	if p.Limit > 0 && p.Total > 0 {
+		return (p.Total + p.Limit - 1) / p.Limit
+	}
+	return p.Size
+}
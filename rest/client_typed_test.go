@@ -0,0 +1,185 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type itemRequest struct {
+	ID     string `schema:"id" json:"id"`
+	Tenant string `schema:"tenant" json:"tenant"`
+	Limit  int    `query:"limit" json:"limit"`
+	Name   string `json:"name"`
+	Trace  string `schema:"trace" json:"-"`
+}
+
+type pageRequest struct {
+	Page int `schema:"page"`
+}
+
+type item struct {
+	Name string `json:"name"`
+}
+
+func TestNewRequest(t *testing.T) {
+	is := assert.New(t)
+	ctx := context.Background()
+	req := itemRequest{ID: "a/1", Tenant: "acme", Limit: 10, Name: "book", Trace: "t-1"}
+
+	r, err := NewRequest(ctx, http.MethodGet, "/items/{id}?sort=name", req)
+	is.NoError(err)
+	is.Equal("/items/a%2F1", r.URL.EscapedPath())
+	is.Equal("10", r.URL.Query().Get("limit"))
+	is.Equal("acme", r.URL.Query().Get("tenant"))
+	is.Equal("name", r.URL.Query().Get("sort"))
+	is.Equal("t-1", r.URL.Query().Get("trace"))
+	is.Empty(r.URL.Query().Get("id"))
+	is.Nil(r.Body)
+	is.Empty(r.Header.Get(HeaderContentType.String()))
+
+	r, err = NewRequest(ctx, http.MethodPost, "/items/{id:[a-z0-9/]+}", req)
+	is.NoError(err)
+	is.Equal("/items/a%2F1", r.URL.EscapedPath())
+	is.Equal("10", r.URL.Query().Get("limit"))
+	// every field is sent once, the schema fields of body are not copied into the query.
+	is.Empty(r.URL.Query().Get("tenant"))
+	is.Equal("t-1", r.URL.Query().Get("trace"))
+	is.Equal(MIMEApplicationJSON.String(), r.Header.Get(HeaderContentType.String()))
+	b, err := io.ReadAll(r.Body)
+	is.NoError(err)
+	// the path and query fields are not sent again in the body.
+	is.JSONEq(`{"tenant":"acme","name":"book"}`, string(b))
+
+	_, err = NewRequest(ctx, http.MethodGet, "/items/{id}", itemRequest{})
+	is.EqualError(err, "rest: missing path params of /items/{id}")
+
+	r, err = NewRequest(ctx, http.MethodPut, "/items", []string{"a"})
+	is.NoError(err)
+	b, _ = io.ReadAll(r.Body)
+	is.JSONEq(`["a"]`, string(b))
+}
+
+func TestDoResult(t *testing.T) {
+	is := assert.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ok":
+			_, _ = w.Write([]byte(`{"meta":{"code":"200"},"data":{"name":"book"}}`))
+		case "/not-found":
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"meta":{"code":"404","error_message":"item is not found"}}`))
+		case "/meta-failed":
+			_, _ = w.Write([]byte(`{"meta":{"code":"500","error_message":"database is down"}}`))
+		case "/no-meta":
+			w.WriteHeader(http.StatusConflict)
+			_, _ = w.Write([]byte(`{"data":{"name":"book"}}`))
+		case "/bad-gateway":
+			w.WriteHeader(http.StatusBadGateway)
+			_, _ = w.Write([]byte("  upstream is down\n"))
+		case "/empty":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case "/long":
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(strings.Repeat("x", 2*maxErrorBody)))
+		case "/html":
+			_, _ = w.Write([]byte("<html></html>"))
+		}
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+
+	got, err := Do[struct{}, item](ctx, srv.Client(), http.MethodGet, srv.URL+"/ok", struct{}{})
+	is.NoError(err)
+	is.Equal(item{Name: "book"}, got)
+
+	result, err := DoResult[struct{}, item](ctx, srv.Client(), http.MethodGet, srv.URL+"/not-found", struct{}{})
+	var respErr *ResponseError
+	is.True(errors.As(err, &respErr))
+	is.Equal(&ResponseError{StatusCode: 404, Code: "404", Message: "item is not found"}, respErr)
+	is.Equal(http.StatusNotFound, result.StatusCode)
+	is.EqualError(err, "rest: response 404: item is not found")
+
+	_, err = DoResult[struct{}, item](ctx, srv.Client(), http.MethodGet, srv.URL+"/meta-failed", struct{}{})
+	is.True(errors.As(err, &respErr))
+	is.Equal(&ResponseError{StatusCode: 200, Code: "500", Message: "database is down"}, respErr)
+
+	result, err = DoResult[struct{}, item](ctx, srv.Client(), http.MethodGet, srv.URL+"/no-meta", struct{}{})
+	is.True(errors.As(err, &respErr))
+	is.Equal("409", respErr.Code)
+	is.Equal("409", result.Meta.Code)
+	is.Equal("book", result.Data.Name)
+
+	for path, want := range map[string]ResponseError{
+		"/bad-gateway": {StatusCode: 502, Code: "502", Message: "upstream is down"},
+		"/empty":       {StatusCode: 503, Code: "503", Message: http.StatusText(http.StatusServiceUnavailable)},
+		"/long":        {StatusCode: 500, Code: "500", Message: strings.Repeat("x", maxErrorBody)},
+	} {
+		got, err := Do[struct{}, item](ctx, srv.Client(), http.MethodGet, srv.URL+path, struct{}{})
+		is.True(errors.As(err, &respErr), path)
+		is.Equal(want, *respErr, path)
+		is.Zero(got, path)
+	}
+
+	_, err = Do[struct{}, item](ctx, srv.Client(), http.MethodGet, srv.URL+"/html", struct{}{})
+	var syntaxErr *json.SyntaxError
+	is.True(errors.As(err, &syntaxErr))
+}
+
+func TestPager(t *testing.T) {
+	is := assert.New(t)
+	items := []string{"a", "b", "c", "d", "e"}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if r.URL.Path == "/broken" && page > 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		start, end := (page-1)*2, page*2
+		if end > len(items) {
+			end = len(items)
+		}
+		b, _ := json.Marshal(map[string]any{
+			"meta":       Meta{Code: "200"},
+			"pagination": Pagination{Page: page, Limit: 2, Total: len(items)},
+			"data":       items[start:end],
+		})
+		_, _ = w.Write(b)
+	}))
+	defer srv.Close()
+	setPage := func(req *pageRequest, page int) { req.Page = page }
+
+	pager := NewPager[pageRequest, []string](srv.Client(), http.MethodGet, srv.URL+"/items", pageRequest{}, setPage)
+	var got []string
+	for pager.Next(context.Background()) {
+		got = append(got, pager.Result().Data...)
+	}
+	is.NoError(pager.Err())
+	is.Equal(items, got)
+	is.Equal(3, pager.Result().Page)
+	is.False(pager.Next(context.Background()))
+
+	pager = NewPager[pageRequest, []string](srv.Client(), http.MethodGet, srv.URL+"/broken", pageRequest{}, setPage)
+	is.True(pager.Next(context.Background()))
+	is.False(pager.Next(context.Background()))
+	var respErr *ResponseError
+	is.True(errors.As(pager.Err(), &respErr))
+	is.False(pager.Next(context.Background()))
+}
+
+func TestLastPage(t *testing.T) {
+	is := assert.New(t)
+	is.Equal(3, lastPage(Pagination{Limit: 2, Total: 5}))
+	is.Equal(2, lastPage(Pagination{Limit: 2, Total: 4}))
+	is.Equal(4, lastPage(Pagination{Size: 4}))
+	is.Equal(4, lastPage(Pagination{Limit: 2, Size: 4}))
+	is.Zero(lastPage(Pagination{}))
+}
@@ -37,6 +37,9 @@ const (
 	MIMEOctetStream
 	MIMEImageJPEG
 	MIMEImagePNG
+	MIMEApplicationMergePatchJSON
+	MIMEApplicationJSONPatchJSON
+	MIMEApplicationVndAPIJSON
 )
 
 // String - Creating common behavior - give the type a String function.
@@ -67,6 +70,9 @@ func (m ContentType) String() string {
 		"application/octet-stream",
 		"image/jpeg",
 		"image/png",
+		"application/merge-patch+json",
+		"application/json-patch+json",
+		"application/vnd.api+json",
 	}[m]
 }
 
@@ -84,14 +90,20 @@ func GetRequestContentType(r *http.Request) ContentType {
 		return MIMETextPlain
 	case MIMETextHTML.String():
 		return MIMETextHTML
-	case MIMEApplicationJSON.String(), MIMETextJavaScript.String():
+	case MIMEApplicationJSON.String():
 		return MIMEApplicationJSON
+	case MIMETextJavaScript.String():
+		return MIMETextJavaScript
 	case MIMETextXML.String(), MIMEApplicationXML.String():
 		return MIMETextXML
 	case MIMEApplicationForm.String():
 		return MIMEApplicationForm
 	case MIMEMultipartForm.String():
 		return MIMEMultipartForm
+	case MIMEApplicationMsgpack.String(), "application/x-msgpack":
+		return MIMEApplicationMsgpack
+	case MIMEApplicationProtobuf.String(), "application/x-protobuf":
+		return MIMEApplicationProtobuf
 	case MIMEOctetStream.String():
 		return MIMEOctetStream
 	default:
@@ -0,0 +1,235 @@
+// Package rest is port adapter via http/s protocol
+// # This manifest was generated by ymir. DO NOT EDIT.
+package rest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/vmihailenco/msgpack/v5"
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	// ErrUnsupportedContentType is define error when no body decoder is registered for the request media type.
+	ErrUnsupportedContentType = errors.New("unable to decode the request content type")
+	// ErrStrictJSON is define error when request body violates the strict JSON rules.
+	ErrStrictJSON = errors.New("invalid strict json")
+)
+
+// BodyDecoder is func type to decode request body into v.
+type BodyDecoder func(r *http.Request, v any) error
+
+var decoders = struct {
+	sync.RWMutex
+	m map[string]BodyDecoder
+}{m: make(map[string]BodyDecoder)}
+
+func init() {
+	jsonDecoder := func(r *http.Request, v any) error {
+		if cfg, ok := r.Context().Value(CtxStrictJSON).(StrictJSONConfig); ok {
+			return DecodeStrictJSON(r.Body, v, cfg)
+		}
+		return DecodeJSON(r.Body, v)
+	}
+	xmlDecoder := func(r *http.Request, v any) error {
+		return DecodeXML(r.Body, v)
+	}
+	msgpackDecoder := func(r *http.Request, v any) error {
+		return DecodeMsgpack(r.Body, v)
+	}
+	protobufDecoder := func(r *http.Request, v any) error {
+		return DecodeProtobuf(r.Body, v)
+	}
+	RegisterDecoder(MIMEApplicationJSON.String(), jsonDecoder)
+	RegisterDecoder(MIMETextXML.String(), xmlDecoder)
+	RegisterDecoder(MIMEApplicationXML.String(), xmlDecoder)
+	RegisterDecoder(MIMEApplicationForm.String(), DecodeForm)
+	RegisterDecoder(MIMEMultipartForm.String(), DecodeForm)
+	RegisterDecoder(MIMEApplicationMsgpack.String(), msgpackDecoder)
+	RegisterDecoder("application/x-msgpack", msgpackDecoder)
+	RegisterDecoder(MIMEApplicationProtobuf.String(), protobufDecoder)
+	RegisterDecoder("application/x-protobuf", protobufDecoder)
+}
+
+// RegisterDecoder registers body decoder of media type, the existing decoder is replaced.
+func RegisterDecoder(mediaType string, decoder BodyDecoder) {
+	decoders.Lock()
+	defer decoders.Unlock()
+	decoders.m[strings.ToLower(mediaType)] = decoder
+}
+
+// LookupDecoder returns body decoder of media type.
+func LookupDecoder(mediaType string) (BodyDecoder, bool) {
+	decoders.RLock()
+	defer decoders.RUnlock()
+	decoder, ok := decoders.m[strings.ToLower(mediaType)]
+	return decoder, ok
+}
+
+// requestMediaType returns media type of request Content-Type without parameters.
+func requestMediaType(r *http.Request) string {
+	contentType := r.Header.Get(HeaderContentType.String())
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	}
+	return mediaType
+}
+
+// DecodeMsgpack decodes a given reader into an interface using the msgpack decoderBody,
+// fields are matched by `json` tags.
+func DecodeMsgpack(r io.Reader, v any) error {
+	defer func(dst io.Writer, src io.Reader) {
+		_, _ = io.Copy(dst, src)
+	}(io.Discard, r)
+	dec := msgpack.NewDecoder(r)
+	dec.SetCustomStructTag("json")
+	return dec.Decode(v)
+}
+
+// DecodeProtobuf decodes a given reader into an interface using the protobuf decoderBody,
+// v must implement proto.Message.
+func DecodeProtobuf(r io.Reader, v any) error {
+	msg, ok := v.(proto.Message)
+	if !ok {
+		return fmt.Errorf("protobuf: %T does not implement proto.Message", v)
+	}
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return proto.Unmarshal(b, msg)
+}
+
+// StrictJSONConfig holds the rules of strict JSON decoding.
+type StrictJSONConfig struct {
+	// DisallowUnknownFields rejects fields which are not in the destination struct.
+	DisallowUnknownFields bool
+	// DisallowDuplicateKeys rejects objects with the same key more than once.
+	DisallowDuplicateKeys bool
+	// MaxDepth rejects documents nested deeper than it, zero means unlimited.
+	MaxDepth int
+}
+
+// DefaultStrictJSONConfig returns the strict JSON configuration with every rule enabled.
+func DefaultStrictJSONConfig() StrictJSONConfig {
+	return StrictJSONConfig{
+		DisallowUnknownFields: true,
+		DisallowDuplicateKeys: true,
+		MaxDepth:              32,
+	}
+}
+
+// StrictJSON is middleware handler to select strict JSON decoding of the route.
+func StrictJSON(cfg StrictJSONConfig) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			*r = *r.WithContext(context.WithValue(r.Context(), CtxStrictJSON, cfg))
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// DecodeStrictJSON decodes a given reader into an interface using the json decoderBody with strict rules.
+func DecodeStrictJSON(r io.Reader, v any, cfg StrictJSONConfig) error {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	if cfg.DisallowDuplicateKeys || cfg.MaxDepth > 0 {
+		if err = checkJSON(b, cfg); err != nil {
+			return err
+		}
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	if cfg.DisallowUnknownFields {
+		dec.DisallowUnknownFields()
+	}
+	if err = dec.Decode(v); err != nil {
+		// the body which is decoded without the unknown fields rule fails only by the unknown field.
+		if cfg.DisallowUnknownFields && json.NewDecoder(bytes.NewReader(b)).Decode(v) == nil {
+			return fmt.Errorf("%w: %s", ErrStrictJSON, strings.TrimPrefix(err.Error(), "json: "))
+		}
+		return err
+	}
+	if dec.More() {
+		return fmt.Errorf("%w: unexpected data after top-level value", ErrStrictJSON)
+	}
+	return nil
+}
+
+// malformedJSON reports whether err is the syntax or type error of JSON body.
+func malformedJSON(err error) bool {
+	var (
+		syntaxErr *json.SyntaxError
+		typeErr   *json.UnmarshalTypeError
+	)
+	return errors.As(err, &syntaxErr) || errors.As(err, &typeErr) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// checkJSON walks the JSON tokens to detect duplicate keys and nesting depth.
+func checkJSON(b []byte, cfg StrictJSONConfig) error {
+	type frame struct {
+		object bool
+		keys   map[string]struct{}
+		key    bool // next string token of object is a key.
+	}
+	var (
+		dec   = json.NewDecoder(bytes.NewReader(b))
+		stack []*frame
+	)
+	dec.UseNumber()
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		var top *frame
+		if len(stack) > 0 {
+			top = stack[len(stack)-1]
+		}
+		switch t := tok.(type) {
+		case json.Delim:
+			switch t {
+			case '{', '[':
+				if top != nil && top.object {
+					top.key = true
+				}
+				stack = append(stack, &frame{object: t == '{', keys: map[string]struct{}{}, key: t == '{'})
+				if cfg.MaxDepth > 0 && len(stack) > cfg.MaxDepth {
+					return fmt.Errorf("%w: nesting depth exceeds %d", ErrStrictJSON, cfg.MaxDepth)
+				}
+			case '}', ']':
+				stack = stack[:len(stack)-1]
+			}
+		case string:
+			if top != nil && top.object && top.key {
+				if _, ok := top.keys[t]; ok && cfg.DisallowDuplicateKeys {
+					return fmt.Errorf("%w: duplicate key %q", ErrStrictJSON, t)
+				}
+				top.keys[t] = struct{}{}
+				top.key = false
+				continue
+			}
+			if top != nil && top.object {
+				top.key = true
+			}
+		default:
+			if top != nil && top.object {
+				top.key = true
+			}
+		}
+	}
+}
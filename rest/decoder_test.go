@@ -0,0 +1,127 @@
+package rest
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vmihailenco/msgpack/v5"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+type decodeItem struct {
+	Name  string         `json:"name"`
+	Tags  []string       `json:"tags"`
+	Extra map[string]any `json:"extra"`
+}
+
+func TestDecodeStrictJSON(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		body   string
+		cfg    StrictJSONConfig
+		strict string
+		err    bool
+	}{
+		{name: "valid", body: `{"name":"book","tags":["a"],"extra":{"a":{"b":1}}}`, cfg: DefaultStrictJSONConfig()},
+		{name: "unknown field", body: `{"name":"book","price":1}`, cfg: DefaultStrictJSONConfig(),
+			strict: `invalid strict json: unknown field "price"`},
+		{name: "unknown field allowed", body: `{"name":"book","price":1}`, cfg: StrictJSONConfig{}},
+		{name: "type error is not strict", body: `{"name":1,"price":1}`, cfg: DefaultStrictJSONConfig(), err: true},
+		{name: "duplicate key", body: `{"name":"a","name":"b"}`, cfg: DefaultStrictJSONConfig(),
+			strict: `invalid strict json: duplicate key "name"`},
+		{name: "nested duplicate key", body: `{"extra":{"a":1,"b":[{"c":1,"c":2}]}}`, cfg: DefaultStrictJSONConfig(),
+			strict: `invalid strict json: duplicate key "c"`},
+		{name: "same key in sibling objects", body: `{"extra":{"a":{"k":1},"b":{"k":1}},"tags":["name","name"]}`,
+			cfg: DefaultStrictJSONConfig()},
+		{name: "duplicate key allowed", body: `{"name":"a","name":"b"}`, cfg: StrictJSONConfig{}},
+		{name: "depth limit", body: `{"extra":{"a":{"b":[1]}}}`, cfg: StrictJSONConfig{MaxDepth: 3},
+			strict: "invalid strict json: nesting depth exceeds 3"},
+		{name: "depth within limit", body: `{"extra":{"a":[1]}}`, cfg: StrictJSONConfig{MaxDepth: 3}},
+		{name: "trailing data", body: `{"name":"a"} {"name":"b"}`, cfg: StrictJSONConfig{},
+			strict: "invalid strict json: unexpected data after top-level value"},
+		{name: "malformed", body: `{"name":`, cfg: DefaultStrictJSONConfig(), err: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			is := assert.New(t)
+			err := DecodeStrictJSON(strings.NewReader(tc.body), &decodeItem{}, tc.cfg)
+			switch {
+			case tc.strict != "":
+				is.True(errors.Is(err, ErrStrictJSON))
+				is.EqualError(err, tc.strict)
+			case tc.err:
+				is.Error(err)
+				is.False(errors.Is(err, ErrStrictJSON))
+			default:
+				is.NoError(err)
+			}
+		})
+	}
+}
+
+func TestStrictJSON(t *testing.T) {
+	is := assert.New(t)
+	handler := HandlerAdapter[decodeItem](func(w http.ResponseWriter, r *http.Request) (any, error) {
+		return GetBind[decodeItem](r)
+	})
+	h := StrictJSON(DefaultStrictJSONConfig())(handler)
+	serve := func(h http.Handler, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		r.Header.Set(HeaderContentType.String(), MIMEApplicationJSON.String())
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+		return rec
+	}
+	is.Equal(http.StatusOK, serve(h, `{"name":"book"}`).Code)
+	rec := serve(h, `{"name":"book","name":"pen"}`)
+	is.Equal(http.StatusBadRequest, rec.Code)
+	is.Contains(rec.Body.String(), "duplicate key")
+	is.Equal(http.StatusBadRequest, serve(h, `{"name":"book","price":1}`).Code)
+
+	// the malformed body is the client error with or without the strict rules.
+	for _, body := range []string{`{"name":`, `{"name":1}`, `{"name" "book"}`} {
+		is.Equal(http.StatusBadRequest, serve(h, body).Code, body)
+		is.Equal(http.StatusBadRequest, serve(handler, body).Code, body)
+	}
+}
+
+func TestDecoders(t *testing.T) {
+	is := assert.New(t)
+	decode := func(contentType string, body []byte, v any) error {
+		r := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+		r.Header.Set(HeaderContentType.String(), contentType)
+		return decoderBody(r, v)
+	}
+
+	var item decodeItem
+	is.True(errors.Is(decode("text/javascript; charset=utf-8", []byte(`{"name":"book"}`), &item), ErrUnsupportedContentType))
+	r := httptest.NewRequest(http.MethodPost, "/", nil)
+	r.Header.Set(HeaderContentType.String(), "text/javascript; charset=utf-8")
+	is.Equal(MIMETextJavaScript, GetRequestContentType(r))
+
+	b, err := msgpack.Marshal(map[string]any{"name": "book", "tags": []string{"a", "b"}})
+	is.NoError(err)
+	for _, contentType := range []string{MIMEApplicationMsgpack.String(), "application/x-msgpack"} {
+		item = decodeItem{}
+		is.NoError(decode(contentType, b, &item))
+		is.Equal(decodeItem{Name: "book", Tags: []string{"a", "b"}}, item)
+	}
+
+	b, err = proto.Marshal(wrapperspb.String("book"))
+	is.NoError(err)
+	for _, contentType := range []string{MIMEApplicationProtobuf.String(), "application/x-protobuf"} {
+		msg := &wrapperspb.StringValue{}
+		is.NoError(decode(contentType, b, msg))
+		is.Equal("book", msg.GetValue())
+	}
+	is.EqualError(decode(MIMEApplicationProtobuf.String(), b, &item),
+		"protobuf: *rest.decodeItem does not implement proto.Message")
+
+	err = decode("application/yaml", []byte("name: book"), &item)
+	is.True(errors.Is(err, ErrUnsupportedContentType))
+}
@@ -0,0 +1,225 @@
+// Package rest is port adapter via http/s protocol
+// # This manifest was generated by ymir. DO NOT EDIT.
+package rest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strconv"
+)
+
+// defaultVersion is written by DefaultEnvelope when the version is not set.
+var defaultVersion = Version{Label: "v1", Number: "0.1.0"}
+
+// EnvelopeParts holds the parts of adapter response rendered by Envelope.
+type EnvelopeParts struct {
+	StatusCode int
+	Meta       Meta
+	Version    Version
+	Pagination Pagination
+	Data       any
+	// Err is the adapter error, it is nil on success response.
+	Err error
+}
+
+// Envelope renders the body of adapter JSON response and error response.
+type Envelope interface {
+	ContentType() string
+	Marshal(p EnvelopeParts) ([]byte, error)
+}
+
+// WithEnvelope is middleware handler to choose the response envelope of adapters.
+func WithEnvelope(env Envelope) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			*r = *r.WithContext(context.WithValue(r.Context(), CtxEnvelope, env))
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// WithRouteEnvelope will render the route responses by env, see WithEnvelope.
+func WithRouteEnvelope(env Envelope) RouteOption {
+	return WithRouteMiddleware(WithEnvelope(env))
+}
+
+// EnvelopeOf returns the envelope of request, DefaultEnvelope is used when none is chosen.
+func EnvelopeOf(r *http.Request) Envelope {
+	if env, ok := r.Context().Value(CtxEnvelope).(Envelope); ok && env != nil {
+		return env
+	}
+	return DefaultEnvelope{}
+}
+
+// DefaultEnvelope renders the `{meta, version, pagination, data}` envelope.
+// The zero value renders the same body as Response, field names are configurable.
+type DefaultEnvelope struct {
+	MetaField       string
+	VersionField    string
+	PaginationField string
+	DataField       string
+	// OmitEmptyVersion omits the version when it is not set, otherwise v1/0.1.0 is written.
+	OmitEmptyVersion bool
+	// OmitEmptyPagination omits the pagination when it is not set.
+	OmitEmptyPagination bool
+}
+
+// ContentType returns the content type of envelope.
+func (e DefaultEnvelope) ContentType() string {
+	return MIMEApplicationJSON.String()
+}
+
+// Marshal renders the fields in order of meta, version, pagination and data.
+func (e DefaultEnvelope) Marshal(p EnvelopeParts) ([]byte, error) {
+	obj := &orderedObject{}
+	obj.add(fieldName(e.MetaField, "meta"), p.Meta)
+	switch {
+	case p.Version != (Version{}):
+		obj.add(fieldName(e.VersionField, "version"), p.Version)
+	case !e.OmitEmptyVersion:
+		obj.add(fieldName(e.VersionField, "version"), defaultVersion)
+	}
+	if p.Pagination != (Pagination{}) || !e.OmitEmptyPagination {
+		obj.add(fieldName(e.PaginationField, "pagination"), p.Pagination)
+	}
+	if p.Data != nil {
+		obj.add(fieldName(e.DataField, "data"), p.Data)
+	}
+	return obj.bytes()
+}
+
+// RawEnvelope renders the adapter data as is, errors are rendered as Meta, e.g. for third-party callbacks.
+type RawEnvelope struct{}
+
+// ContentType returns the content type of envelope.
+func (RawEnvelope) ContentType() string {
+	return MIMEApplicationJSON.String()
+}
+
+// Marshal renders the data or meta of error.
+func (RawEnvelope) Marshal(p EnvelopeParts) ([]byte, error) {
+	if p.Err != nil {
+		return json.Marshal(p.Meta)
+	}
+	return json.Marshal(p.Data)
+}
+
+// JSONAPIResource is implemented by data rendered as JSON:API resource object.
+type JSONAPIResource interface {
+	JSONAPIType() string
+	JSONAPIID() string
+}
+
+// JSONAPIEnvelope renders the JSON:API style document, data of JSONAPIResource (or slice of them)
+// is rendered as resource objects and the pagination as top level meta.
+type JSONAPIEnvelope struct{}
+
+type jsonAPIResource struct {
+	Type       string `json:"type"`
+	ID         string `json:"id"`
+	Attributes any    `json:"attributes,omitempty"`
+}
+
+type jsonAPIError struct {
+	Status string `json:"status"`
+	Title  string `json:"title"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// ContentType returns the content type of envelope.
+func (JSONAPIEnvelope) ContentType() string {
+	return MIMEApplicationVndAPIJSON.String()
+}
+
+// Marshal renders the JSON:API document.
+func (JSONAPIEnvelope) Marshal(p EnvelopeParts) ([]byte, error) {
+	obj := &orderedObject{}
+	if p.Err != nil {
+		obj.add("errors", []jsonAPIError{{
+			Status: strconv.Itoa(p.StatusCode),
+			Title:  http.StatusText(p.StatusCode),
+			Detail: p.Meta.Message,
+		}})
+	} else {
+		obj.add("data", jsonAPIData(p.Data))
+		if p.Pagination != (Pagination{}) {
+			obj.add("meta", p.Pagination)
+		}
+	}
+	if p.Version != (Version{}) {
+		obj.add("jsonapi", map[string]any{"version": "1.0", "meta": p.Version})
+	}
+	return obj.bytes()
+}
+
+func jsonAPIData(data any) any {
+	if res, ok := data.(JSONAPIResource); ok {
+		return jsonAPIResource{Type: res.JSONAPIType(), ID: res.JSONAPIID(), Attributes: data}
+	}
+	v := reflect.ValueOf(data)
+	if v.Kind() != reflect.Slice || !v.Type().Elem().Implements(reflect.TypeOf((*JSONAPIResource)(nil)).Elem()) {
+		return data
+	}
+	out := make([]any, v.Len())
+	for i := range out {
+		out[i] = jsonAPIData(v.Index(i).Interface())
+	}
+	return out
+}
+
+// EnvelopeFunc is the custom JSON envelope.
+type EnvelopeFunc func(p EnvelopeParts) ([]byte, error)
+
+// ContentType returns the content type of envelope.
+func (f EnvelopeFunc) ContentType() string {
+	return MIMEApplicationJSON.String()
+}
+
+// Marshal calls f(p).
+func (f EnvelopeFunc) Marshal(p EnvelopeParts) ([]byte, error) {
+	return f(p)
+}
+
+func fieldName(name, fallback string) string {
+	if name == "" {
+		return fallback
+	}
+	return name
+}
+
+// orderedObject writes the JSON object fields in order of add.
+type orderedObject struct {
+	keys   []string
+	values []any
+}
+
+func (o *orderedObject) add(key string, value any) {
+	o.keys = append(o.keys, key)
+	o.values = append(o.values, value)
+}
+
+func (o *orderedObject) bytes() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	buf.WriteByte('{')
+	for i, key := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		v, err := json.Marshal(o.values[i])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
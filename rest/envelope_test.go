@@ -0,0 +1,65 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type envelopeItem struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func (i envelopeItem) JSONAPIType() string { return "items" }
+
+func (i envelopeItem) JSONAPIID() string { return i.ID }
+
+func serveEnvelope(env Envelope, fail bool) *httptest.ResponseRecorder {
+	h := HandlerAdapter[RequestNotFound](func(w http.ResponseWriter, r *http.Request) ([]envelopeItem, error) {
+		if fail {
+			return nil, ErrNotFound(w, r, errors.New("item is not found"))
+		}
+		return []envelopeItem{{ID: "1", Name: "book"}}, nil
+	})
+	rec := httptest.NewRecorder()
+	WithEnvelope(env)(http.HandlerFunc(h.JSON)).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/items", nil))
+	return rec
+}
+
+func TestEnvelope(t *testing.T) {
+	is := assert.New(t)
+
+	rec := serveEnvelope(RawEnvelope{}, false)
+	is.JSONEq(`[{"id":"1","name":"book"}]`, rec.Body.String())
+	rec = serveEnvelope(RawEnvelope{}, true)
+	is.Equal(http.StatusNotFound, rec.Code)
+	is.JSONEq(`{"code":"404","error_message":"item is not found"}`, rec.Body.String())
+
+	rec = serveEnvelope(DefaultEnvelope{DataField: "items", OmitEmptyVersion: true, OmitEmptyPagination: true}, false)
+	is.Equal(`{"meta":{"code":"200"},"items":[{"id":"1","name":"book"}]}`+"\n", rec.Body.String())
+
+	rec = serveEnvelope(JSONAPIEnvelope{}, false)
+	is.Equal(MIMEApplicationVndAPIJSON.String(), rec.Header().Get(HeaderContentType.String()))
+	is.JSONEq(`{"data":[{"type":"items","id":"1","attributes":{"id":"1","name":"book"}}]}`, rec.Body.String())
+	rec = serveEnvelope(JSONAPIEnvelope{}, true)
+	is.JSONEq(`{"errors":[{"status":"404","title":"Not Found","detail":"item is not found"}]}`, rec.Body.String())
+
+	rec = serveEnvelope(EnvelopeFunc(func(p EnvelopeParts) ([]byte, error) {
+		return []byte(`{"ok":` + map[bool]string{true: "false", false: "true"}[p.Err != nil] + `}`), nil
+	}), false)
+	is.JSONEq(`{"ok":true}`, rec.Body.String())
+}
+
+func TestRouteEnvelope(t *testing.T) {
+	router := NewRouter()
+	Get[RequestNotFound, envelopeItem](router, "/raw", func(w http.ResponseWriter, r *http.Request) (envelopeItem, error) {
+		return envelopeItem{ID: "2"}, nil
+	}, WithRouteEnvelope(RawEnvelope{}))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/raw", nil))
+	assert.JSONEq(t, `{"id":"2","name":""}`, rec.Body.String())
+}
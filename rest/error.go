@@ -87,6 +87,22 @@ func ErrStatusConflict(w http.ResponseWriter, r *http.Request, err error) error
 	return err
 }
 
+// ErrGone error http StatusGone.
+func ErrGone(w http.ResponseWriter, r *http.Request, err error) error {
+	*r = *r.WithContext(context.WithValue(r.Context(), CtxStatusCode, http.StatusGone))
+	w.Header().Set(HeaderContentTypeOptions.String(), "nosniff")
+	w.WriteHeader(http.StatusGone)
+	return err
+}
+
+// ErrRequestEntityTooLarge error http StatusRequestEntityTooLarge.
+func ErrRequestEntityTooLarge(w http.ResponseWriter, r *http.Request, err error) error {
+	*r = *r.WithContext(context.WithValue(r.Context(), CtxStatusCode, http.StatusRequestEntityTooLarge))
+	w.Header().Set(HeaderContentTypeOptions.String(), "nosniff")
+	w.WriteHeader(http.StatusRequestEntityTooLarge)
+	return err
+}
+
 // ErrUnsupportedMediaType error http StatusUnsupportedMediaType.
 func ErrUnsupportedMediaType(w http.ResponseWriter, r *http.Request, err error) error {
 	*r = *r.WithContext(context.WithValue(r.Context(), CtxStatusCode, http.StatusUnsupportedMediaType))
@@ -103,6 +119,14 @@ func ErrUnprocessableEntity(w http.ResponseWriter, r *http.Request, err error) e
 	return err
 }
 
+// ErrTooManyRequests error http StatusTooManyRequests.
+func ErrTooManyRequests(w http.ResponseWriter, r *http.Request, err error) error {
+	*r = *r.WithContext(context.WithValue(r.Context(), CtxStatusCode, http.StatusTooManyRequests))
+	w.Header().Set(HeaderContentTypeOptions.String(), "nosniff")
+	w.WriteHeader(http.StatusTooManyRequests)
+	return err
+}
+
 // ErrInternalServerError error http StatusInternalServerError.
 func ErrInternalServerError(w http.ResponseWriter, r *http.Request, err error) error {
 	*r = *r.WithContext(context.WithValue(r.Context(), CtxStatusCode, http.StatusInternalServerError))
@@ -18,6 +18,22 @@ const (
 	HeaderXTraceId
 	HeaderUberTraceId
 	HeaderContentTypeOptions
+	HeaderRetryAfter
+	HeaderETag
+	HeaderLastModified
+	HeaderAcceptRanges
+	HeaderAccept
+	HeaderDeprecation
+	HeaderSunset
+	HeaderLink
+	HeaderAPIVersion
+	HeaderAcceptLanguage
+	HeaderContentLanguage
+	HeaderVary
+	HeaderLocation
+	HeaderRateLimitLimit
+	HeaderRateLimitRemaining
+	HeaderRateLimitReset
 )
 
 // String - Creating common behavior - give the type a String function.
@@ -35,6 +51,22 @@ func (h HeaderType) String() string {
 		"X-Trace-Id",
 		"Uber-Trace-Id",
 		"X-Content-Type-Options",
+		"Retry-After",
+		"ETag",
+		"Last-Modified",
+		"Accept-Ranges",
+		"Accept",
+		"Deprecation",
+		"Sunset",
+		"Link",
+		"X-API-Version",
+		"Accept-Language",
+		"Content-Language",
+		"Vary",
+		"Location",
+		"RateLimit-Limit",
+		"RateLimit-Remaining",
+		"RateLimit-Reset",
 	}[h]
 }
 
@@ -0,0 +1,194 @@
+// Package rest is port adapter via http/s protocol
+// # This manifest was generated by ymir. DO NOT EDIT.
+package rest
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/kubuskotak/asgard/security"
+)
+
+// catalog holds the translations of error messages per locale, keyed by the sentinel error.
+var catalog = struct {
+	sync.RWMutex
+	messages map[string]map[error]string
+}{
+	messages: map[string]map[error]string{
+		"id": {
+			ErrResourceNotFound:       "sumber daya tidak ditemukan",
+			ErrFileNotFound:           "berkas tidak ditemukan",
+			ErrFileNotOpened:          "berkas tidak dapat dibuka",
+			ErrPayloadNotFound:        "payload permintaan tidak ditemukan",
+			ErrBodyTooLarge:           "isi permintaan terlalu besar",
+			ErrHandlerTimeout:         "waktu pemrosesan habis",
+			ErrHandlerCanceled:        "pemrosesan dibatalkan",
+			ErrRateLimited:            "batas jumlah permintaan terlampaui",
+			ErrVersionNotSupported:    "versi api tidak didukung",
+			ErrVersionRemoved:         "versi api telah dihapus",
+			ErrUploadTooLarge:         "unggahan terlalu besar",
+			ErrUploadTypeNotAllowed:   "tipe konten unggahan tidak diizinkan",
+			ErrUnsupportedContentType: "tipe konten permintaan tidak dapat dibaca",
+			ErrStrictJSON:             "json tidak valid",
+			ErrInvalidPatch:           "dokumen patch tidak valid",
+			ErrPatchConflict:          "patch tidak dapat diterapkan",
+		},
+	},
+}
+
+// RegisterErrorMessages adds the translations of error messages for locale, keyed by the sentinel error,
+// e.g. RegisterErrorMessages("id", map[error]string{ErrItemNotFound: "barang tidak ditemukan"}).
+// The validation messages are registered by security.RegisterMessages.
+func RegisterErrorMessages(locale string, messages map[error]string) {
+	locale = normalizeLocale(locale)
+	catalog.Lock()
+	defer catalog.Unlock()
+	if catalog.messages[locale] == nil {
+		catalog.messages[locale] = make(map[error]string, len(messages))
+	}
+	for target, text := range messages {
+		catalog.messages[locale][target] = text
+	}
+}
+
+// Translate returns the error message in request locale. The message of every registered error
+// which err wraps is translated innermost first, e.g. "invalid patch document: <detail>", the rest is kept as is.
+func Translate(r *http.Request, err error) string {
+	message := err.Error()
+	locale := LocaleOf(r)
+	if locale == "" {
+		return message
+	}
+	messages := localeMessages(locale)
+	chain := unwrapAll(err, nil)
+	translated := make(map[error]bool, len(chain))
+	for i := len(chain) - 1; i >= 0; i-- {
+		target := chain[i]
+		if !reflect.TypeOf(target).Comparable() || translated[target] {
+			continue
+		}
+		text, ok := messages[target]
+		if !ok {
+			continue
+		}
+		translated[target] = true
+		if !strings.Contains(message, target.Error()) {
+			if len(translated) > 1 {
+				// the message of target is translated already by the inner errors.
+				continue
+			}
+			return text
+		}
+		message = strings.Replace(message, target.Error(), text, 1)
+	}
+	return message
+}
+
+// unwrapAll appends err and the errors it wraps into chain, the outer ones first.
+func unwrapAll(err error, chain []error) []error {
+	chain = append(chain, err)
+	switch e := err.(type) {
+	case interface{ Unwrap() error }:
+		if inner := e.Unwrap(); inner != nil {
+			chain = unwrapAll(inner, chain)
+		}
+	case interface{ Unwrap() []error }:
+		for _, inner := range e.Unwrap() {
+			if inner != nil {
+				chain = unwrapAll(inner, chain)
+			}
+		}
+	}
+	return chain
+}
+
+// localeMessages returns the translations of locale merged over the ones of its base language.
+func localeMessages(locale string) map[error]string {
+	catalog.RLock()
+	defer catalog.RUnlock()
+	base, _, _ := strings.Cut(locale, "-")
+	messages := make(map[error]string, len(catalog.messages[base])+len(catalog.messages[locale]))
+	for target, text := range catalog.messages[base] {
+		messages[target] = text
+	}
+	for target, text := range catalog.messages[locale] {
+		messages[target] = text
+	}
+	return messages
+}
+
+// LocaleOf returns the negotiated locale of request, it is empty when AcceptLanguage is not used.
+func LocaleOf(r *http.Request) string {
+	locale, _ := r.Context().Value(CtxLocale).(string)
+	return locale
+}
+
+// AcceptLanguage is middleware handler to negotiate the request locale from Accept-Language header,
+// the first supported locale is the fallback. Validation and error messages are translated into the locale.
+// security.Locales are supported when none is given.
+func AcceptLanguage(supported ...string) func(next http.Handler) http.Handler {
+	if len(supported) < 1 {
+		supported = security.Locales()
+	}
+	locales := make([]string, 0, len(supported))
+	for _, locale := range supported {
+		locales = append(locales, normalizeLocale(locale))
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			locale := negotiateLocale(r.Header.Get(HeaderAcceptLanguage.String()), locales)
+			w.Header().Set(HeaderContentLanguage.String(), locale)
+			w.Header().Add(HeaderVary.String(), HeaderAcceptLanguage.String())
+			*r = *r.WithContext(context.WithValue(r.Context(), CtxLocale, locale))
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// negotiateLocale returns the supported locale of highest quality, exact tag is preferred to its base language.
+func negotiateLocale(header string, supported []string) string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+	var tags []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q > 0 {
+			tags = append(tags, weighted{tag: normalizeLocale(tag), q: q})
+		}
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+	for _, t := range tags {
+		if t.tag == "*" {
+			return supported[0]
+		}
+		base, _, _ := strings.Cut(t.tag, "-")
+		for _, s := range supported {
+			if s == t.tag || s == base {
+				return s
+			}
+		}
+	}
+	return supported[0]
+}
+
+func normalizeLocale(locale string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(locale)), "_", "-")
+}
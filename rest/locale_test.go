@@ -0,0 +1,85 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var errItemNotFound = errors.New("item is not found")
+
+type localeRequest struct {
+	Name string `json:"name" validate:"required"`
+}
+
+func TestNegotiateLocale(t *testing.T) {
+	is := assert.New(t)
+	supported := []string{"en", "id"}
+	is.Equal("id", negotiateLocale("id-ID,id;q=0.9,en;q=0.8", supported))
+	is.Equal("en", negotiateLocale("fr;q=1, en-GB;q=0.7, id;q=0.5", supported))
+	is.Equal("id", negotiateLocale("en;q=0, *", []string{"id", "en"}))
+	is.Equal("en", negotiateLocale("", supported))
+}
+
+func TestAcceptLanguage(t *testing.T) {
+	is := assert.New(t)
+	RegisterErrorMessages("id", map[error]string{errItemNotFound: "barang tidak ditemukan"})
+	h := AcceptLanguage()(http.HandlerFunc(HandlerAdapter[localeRequest](func(w http.ResponseWriter, r *http.Request) (any, error) {
+		return nil, ErrNotFound(w, r, errItemNotFound)
+	}).JSON))
+	serve := func(language, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(body))
+		r.Header.Set(HeaderContentType.String(), MIMEApplicationJSON.String())
+		r.Header.Set(HeaderAcceptLanguage.String(), language)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+		return rec
+	}
+
+	rec := serve("id-ID", `{"name":"book"}`)
+	is.Equal(http.StatusNotFound, rec.Code)
+	is.Equal("id", rec.Header().Get(HeaderContentLanguage.String()))
+	is.Contains(rec.Body.String(), `"error_message":"barang tidak ditemukan"`)
+
+	rec = serve("id", `{}`)
+	is.Contains(rec.Body.String(), `"error_message":"name wajib diisi"`)
+
+	rec = serve("en-US", `{}`)
+	is.Contains(rec.Body.String(), `"error_message":"name is a required field"`)
+}
+
+func TestTranslate(t *testing.T) {
+	is := assert.New(t)
+	RegisterErrorMessages("id-ID", map[error]string{ErrInvalidPatch: "patch tidak sah"})
+	request := func(locale string) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		return r.WithContext(context.WithValue(r.Context(), CtxLocale, locale))
+	}
+	wrapped := fmt.Errorf("%w: missing op", ErrInvalidPatch)
+
+	is.Equal(wrapped.Error(), Translate(request(""), wrapped))
+	is.Equal("dokumen patch tidak valid: missing op", Translate(request("id"), wrapped))
+	// the exact locale overrides its base language.
+	is.Equal("patch tidak sah: missing op", Translate(request("id-id"), wrapped))
+	is.Equal("json tidak valid", Translate(request("id-id"), ErrStrictJSON))
+	is.Equal("upload: unggahan terlalu besar", Translate(request("id"), fmt.Errorf("upload: %w", ErrUploadTooLarge)))
+	is.Equal("sumber daya tidak ditemukan", Translate(request("id"), ErrResourceNotFound))
+	// the error which only shares the message is not translated.
+	is.Equal(ErrStrictJSON.Error(), Translate(request("id"), errors.New(ErrStrictJSON.Error())))
+	is.Equal("isi permintaan terlalu besar: http: request body too large",
+		Translate(request("id"), fmt.Errorf("%w: %w", ErrBodyTooLarge, &http.MaxBytesError{Limit: 1})))
+
+	// the registered error wrapping another one is translated innermost first.
+	errRejected := fmt.Errorf("upload rejected: %w", ErrUploadTooLarge)
+	RegisterErrorMessages("id", map[error]string{errRejected: "unggahan ditolak"})
+	for i := 0; i < 20; i++ {
+		is.Equal("upload rejected: unggahan terlalu besar: avatar",
+			Translate(request("id"), fmt.Errorf("%w: avatar", errRejected)))
+	}
+}
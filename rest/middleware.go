@@ -24,10 +24,6 @@ type Adapter[Request RequestConstraint, Response ResponseConstraint] func(w http
 func HandlerAdapter[RequestType RequestConstraint, ResponseType ResponseConstraint](a Adapter[RequestType, ResponseType]) *Response[RequestType, ResponseType] {
 	null := make(map[string]any)
 	response := &Response[RequestType, ResponseType]{
-		Version: Version{
-			Label:  "v1",
-			Number: "0.1.0",
-		},
 		Data:       null,
 		Pagination: Pagination{},
 	}
@@ -35,7 +31,7 @@ func HandlerAdapter[RequestType RequestConstraint, ResponseType ResponseConstrai
 	return response
 }
 
-// SemanticVersion is middleware handler to semantic versioning.
+// SemanticVersion is middleware handler to semantic versioning, see Versioning to route multiple versions.
 func SemanticVersion(label string, version string) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -47,3 +43,14 @@ func SemanticVersion(label string, version string) func(next http.Handler) http.
 		})
 	}
 }
+
+// BodyLimit is middleware handler to limit the request body size in bytes,
+// binding a larger body is answered with 413.
+func BodyLimit(n int64) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.Body = http.MaxBytesReader(w, r.Body, n)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
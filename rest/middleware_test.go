@@ -0,0 +1,32 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type bodyLimitRequest struct {
+	Name string `json:"name"`
+}
+
+func TestBodyLimit(t *testing.T) {
+	is := assert.New(t)
+	h := BodyLimit(16)(http.HandlerFunc(HandlerAdapter[bodyLimitRequest](func(w http.ResponseWriter, r *http.Request) (any, error) {
+		return GetBind[bodyLimitRequest](r)
+	}).JSON))
+	serve := func(body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		r.Header.Set(HeaderContentType.String(), MIMEApplicationJSON.String())
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+		return rec
+	}
+	is.Equal(http.StatusOK, serve(`{"name":"book"}`).Code)
+	rec := serve(`{"name":"` + strings.Repeat("a", 16) + `"}`)
+	is.Equal(http.StatusRequestEntityTooLarge, rec.Code)
+	is.Contains(rec.Body.String(), ErrBodyTooLarge.Error())
+}
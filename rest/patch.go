@@ -0,0 +1,523 @@
+// Package rest is port adapter via http/s protocol
+// # This manifest was generated by ymir. DO NOT EDIT.
+package rest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrInvalidPatch is define error when patch document is malformed.
+	ErrInvalidPatch = errors.New("invalid patch document")
+	// ErrPatchConflict is define error when patch can not be applied to the resource, e.g. failed test operation.
+	ErrPatchConflict = errors.New("patch can not be applied")
+)
+
+func init() {
+	patchDecoder := func(r *http.Request, v any) error {
+		p, ok := v.(patcher)
+		if !ok {
+			return fmt.Errorf("%w: %T is not a patch document", ErrUnsupportedContentType, v)
+		}
+		b, err := io.ReadAll(r.Body)
+		if err != nil {
+			return err
+		}
+		return p.setPatch(requestMediaType(r), LocaleOf(r), b)
+	}
+	RegisterDecoder(MIMEApplicationMergePatchJSON.String(), patchDecoder)
+	RegisterDecoder(MIMEApplicationJSONPatchJSON.String(), patchDecoder)
+}
+
+// patcher is implemented by PatchDocument, also when it is embedded.
+type patcher interface {
+	setPatch(mediaType, locale string, raw []byte) error
+}
+
+// PatchOperation holds the operation definition of JSON Patch (RFC 6902).
+type PatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// PatchDocument holds the patch of resource T sent as application/merge-patch+json (RFC 7396)
+// or application/json-patch+json (RFC 6902). It is bound by Bind, also when it is embedded
+// into the request struct, so omitted fields are told apart from fields set to zero.
+type PatchDocument[T any] struct {
+	mediaType string
+	locale    string
+	merge     any
+	ops       []PatchOperation
+	fields    []string
+}
+
+func (p *PatchDocument[T]) setPatch(mediaType, locale string, raw []byte) error {
+	p.mediaType, p.locale = mediaType, locale
+	p.merge, p.ops, p.fields = nil, nil, nil
+	switch mediaType {
+	case MIMEApplicationMergePatchJSON.String():
+		if err := unmarshalNumber(raw, &p.merge); err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidPatch, err)
+		}
+		p.fields = mergeFields("", p.merge)
+	case MIMEApplicationJSONPatchJSON.String():
+		if err := json.Unmarshal(raw, &p.ops); err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidPatch, err)
+		}
+		for _, op := range p.ops {
+			if err := op.check(); err != nil {
+				return err
+			}
+			if op.Op == "move" {
+				p.fields = appendField(p.fields, strings.TrimPrefix(op.From, "/"))
+			}
+			if op.Op != "test" {
+				p.fields = appendField(p.fields, strings.TrimPrefix(op.Path, "/"))
+			}
+		}
+	default:
+		return fmt.Errorf("%w: %q", ErrUnsupportedContentType, mediaType)
+	}
+	return nil
+}
+
+// MediaType returns the media type of patch document.
+func (p PatchDocument[T]) MediaType() string {
+	return p.mediaType
+}
+
+// Fields returns the touched fields as JSON pointers without leading slash, e.g. "address/city".
+func (p PatchDocument[T]) Fields() []string {
+	return p.fields
+}
+
+// Touched reports whether the field or any of its children is touched by the patch.
+func (p PatchDocument[T]) Touched(field string) bool {
+	for _, f := range p.fields {
+		if f == field || strings.HasPrefix(f, field+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply applies the patch to the existing resource, validation runs on the patched result.
+// The resource is left untouched when the patch or validation fails. The fields which are not
+// encoded as JSON, e.g. `json:"-"` or unexported, keep their values.
+func (p PatchDocument[T]) Apply(resource *T) error {
+	b, err := json.Marshal(resource)
+	if err != nil {
+		return err
+	}
+	var doc any
+	if err = unmarshalNumber(b, &doc); err != nil {
+		return err
+	}
+	switch p.mediaType {
+	case MIMEApplicationMergePatchJSON.String():
+		doc = mergePatch(doc, p.merge)
+	case MIMEApplicationJSONPatchJSON.String():
+		for _, op := range p.ops {
+			if doc, err = op.apply(doc); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("%w: patch document is empty", ErrInvalidPatch)
+	}
+	if b, err = json.Marshal(doc); err != nil {
+		return err
+	}
+	patched := *resource
+	resetJSONFields(reflect.ValueOf(&patched).Elem())
+	if err = json.Unmarshal(b, &patched); err != nil {
+		return fmt.Errorf("%w: %s", ErrPatchConflict, err)
+	}
+	if err = validateStruct(&patched, p.locale); err != nil {
+		return err
+	}
+	*resource = patched
+	return nil
+}
+
+// resetJSONFields zeroes the fields of v encoded as JSON, so the removed fields stay removed
+// when the patched document is decoded onto v. The exported embedded pointer is copied before it is reset,
+// so the resource is not changed through it.
+func resetJSONFields(v reflect.Value) {
+	if v.Kind() != reflect.Struct {
+		v.Set(reflect.Zero(v.Type()))
+		return
+	}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f, field := t.Field(i), v.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		if f.Anonymous && strings.Split(tag, ",")[0] == "" {
+			switch {
+			case f.Type.Kind() == reflect.Struct:
+				resetJSONFields(field)
+				continue
+			case f.Type.Kind() == reflect.Pointer && f.Type.Elem().Kind() == reflect.Struct:
+				if !field.IsNil() && field.CanSet() {
+					cp := reflect.New(f.Type.Elem())
+					cp.Elem().Set(field.Elem())
+					resetJSONFields(cp.Elem())
+					field.Set(cp)
+				}
+				continue
+			}
+		}
+		if f.IsExported() && field.CanSet() {
+			field.Set(reflect.Zero(f.Type))
+		}
+	}
+}
+
+// patchError writes the status code of Apply error when the handler has not set it,
+// 409 when the patch can not be applied and 422 when the patch is invalid.
+func patchError(w http.ResponseWriter, r *http.Request, err error) error {
+	if code, ok := r.Context().Value(CtxStatusCode).(int); ok && code > 0 {
+		return err
+	}
+	switch {
+	case errors.Is(err, ErrPatchConflict):
+		return ErrStatusConflict(w, r, err)
+	case errors.Is(err, ErrInvalidPatch):
+		return ErrUnprocessableEntity(w, r, err)
+	}
+	return err
+}
+
+func unmarshalNumber(b []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+func appendField(fields []string, field string) []string {
+	for _, f := range fields {
+		if f == field {
+			return fields
+		}
+	}
+	return append(fields, field)
+}
+
+// mergeFields returns the leaf paths of merge patch.
+func mergeFields(prefix string, patch any) []string {
+	obj, ok := patch.(map[string]any)
+	if !ok {
+		return nil
+	}
+	var fields []string
+	for k, v := range obj {
+		path := prefix + escapePointer(k)
+		if child, ok := v.(map[string]any); ok && len(child) > 0 {
+			fields = append(fields, mergeFields(path+"/", child)...)
+			continue
+		}
+		fields = append(fields, path)
+	}
+	return fields
+}
+
+// mergePatch applies JSON Merge Patch (RFC 7396) to target.
+func mergePatch(target, patch any) any {
+	obj, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	doc, ok := target.(map[string]any)
+	if !ok {
+		doc = map[string]any{}
+	}
+	for k, v := range obj {
+		if v == nil {
+			delete(doc, k)
+			continue
+		}
+		doc[k] = mergePatch(doc[k], v)
+	}
+	return doc
+}
+
+func (op PatchOperation) check() error {
+	switch op.Op {
+	case "add", "replace", "test":
+		if op.Value == nil {
+			return fmt.Errorf("%w: %s %s requires value", ErrInvalidPatch, op.Op, op.Path)
+		}
+	case "move", "copy":
+		if _, err := parsePointer(op.From); err != nil {
+			return err
+		}
+	case "remove":
+	default:
+		return fmt.Errorf("%w: unknown operation %q", ErrInvalidPatch, op.Op)
+	}
+	_, err := parsePointer(op.Path)
+	return err
+}
+
+// apply applies the JSON Patch (RFC 6902) operation to doc.
+func (op PatchOperation) apply(doc any) (any, error) {
+	path, _ := parsePointer(op.Path)
+	switch op.Op {
+	case "add", "replace":
+		var value any
+		if err := unmarshalNumber(op.Value, &value); err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidPatch, err)
+		}
+		if op.Op == "add" {
+			return addValue(doc, path, value)
+		}
+		return replaceValue(doc, path, value)
+	case "remove":
+		doc, _, err := removeValue(doc, path)
+		return doc, err
+	case "move":
+		from, _ := parsePointer(op.From)
+		if strings.HasPrefix(op.Path+"/", op.From+"/") && op.Path != op.From {
+			return nil, fmt.Errorf("%w: can not move %s into its child %s", ErrPatchConflict, op.From, op.Path)
+		}
+		doc, value, err := removeValue(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		return addValue(doc, path, value)
+	case "copy":
+		from, _ := parsePointer(op.From)
+		value, err := getValue(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		b, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		var clone any
+		if err = unmarshalNumber(b, &clone); err != nil {
+			return nil, err
+		}
+		return addValue(doc, path, clone)
+	case "test":
+		var expected any
+		if err := unmarshalNumber(op.Value, &expected); err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidPatch, err)
+		}
+		actual, err := getValue(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		if !reflect.DeepEqual(normalizeJSON(actual), normalizeJSON(expected)) {
+			return nil, fmt.Errorf("%w: test %s failed", ErrPatchConflict, op.Path)
+		}
+		return doc, nil
+	}
+	return nil, fmt.Errorf("%w: unknown operation %q", ErrInvalidPatch, op.Op)
+}
+
+// parsePointer parses JSON Pointer (RFC 6901) into reference tokens.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("%w: pointer %q must start with /", ErrInvalidPatch, pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(t, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+func escapePointer(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+func arrayIndex(token string, size int, appendable bool) (int, error) {
+	if appendable && token == "-" {
+		return size, nil
+	}
+	idx, err := strconv.Atoi(token)
+	if err != nil || idx < 0 || (token != "0" && strings.HasPrefix(token, "0")) {
+		return 0, fmt.Errorf("%w: invalid array index %q", ErrPatchConflict, token)
+	}
+	limit := size - 1
+	if appendable {
+		limit = size
+	}
+	if idx > limit {
+		return 0, fmt.Errorf("%w: array index %d out of bounds", ErrPatchConflict, idx)
+	}
+	return idx, nil
+}
+
+// walk calls fn with the parent container of the last token, the changed container is set back to its parent.
+func walk(node any, tokens []string, fn func(parent any, key string) (any, error)) (any, error) {
+	if len(tokens) == 1 {
+		return fn(node, tokens[0])
+	}
+	switch n := node.(type) {
+	case map[string]any:
+		child, ok := n[tokens[0]]
+		if !ok {
+			return nil, fmt.Errorf("%w: path %q is not found", ErrPatchConflict, tokens[0])
+		}
+		child, err := walk(child, tokens[1:], fn)
+		if err != nil {
+			return nil, err
+		}
+		n[tokens[0]] = child
+		return n, nil
+	case []any:
+		idx, err := arrayIndex(tokens[0], len(n), false)
+		if err != nil {
+			return nil, err
+		}
+		child, err := walk(n[idx], tokens[1:], fn)
+		if err != nil {
+			return nil, err
+		}
+		n[idx] = child
+		return n, nil
+	}
+	return nil, fmt.Errorf("%w: path %q is not a container", ErrPatchConflict, tokens[0])
+}
+
+func getValue(doc any, tokens []string) (any, error) {
+	node := doc
+	for _, t := range tokens {
+		switch n := node.(type) {
+		case map[string]any:
+			child, ok := n[t]
+			if !ok {
+				return nil, fmt.Errorf("%w: path %q is not found", ErrPatchConflict, t)
+			}
+			node = child
+		case []any:
+			idx, err := arrayIndex(t, len(n), false)
+			if err != nil {
+				return nil, err
+			}
+			node = n[idx]
+		default:
+			return nil, fmt.Errorf("%w: path %q is not a container", ErrPatchConflict, t)
+		}
+	}
+	return node, nil
+}
+
+func addValue(doc any, tokens []string, value any) (any, error) {
+	if len(tokens) == 0 {
+		return value, nil
+	}
+	return walk(doc, tokens, func(parent any, key string) (any, error) {
+		switch n := parent.(type) {
+		case map[string]any:
+			n[key] = value
+			return n, nil
+		case []any:
+			idx, err := arrayIndex(key, len(n), true)
+			if err != nil {
+				return nil, err
+			}
+			n = append(n, nil)
+			copy(n[idx+1:], n[idx:])
+			n[idx] = value
+			return n, nil
+		}
+		return nil, fmt.Errorf("%w: path %q is not a container", ErrPatchConflict, key)
+	})
+}
+
+func replaceValue(doc any, tokens []string, value any) (any, error) {
+	if len(tokens) == 0 {
+		return value, nil
+	}
+	return walk(doc, tokens, func(parent any, key string) (any, error) {
+		switch n := parent.(type) {
+		case map[string]any:
+			if _, ok := n[key]; !ok {
+				return nil, fmt.Errorf("%w: path %q is not found", ErrPatchConflict, key)
+			}
+			n[key] = value
+			return n, nil
+		case []any:
+			idx, err := arrayIndex(key, len(n), false)
+			if err != nil {
+				return nil, err
+			}
+			n[idx] = value
+			return n, nil
+		}
+		return nil, fmt.Errorf("%w: path %q is not a container", ErrPatchConflict, key)
+	})
+}
+
+func removeValue(doc any, tokens []string) (any, any, error) {
+	if len(tokens) == 0 {
+		return nil, doc, nil
+	}
+	var removed any
+	doc, err := walk(doc, tokens, func(parent any, key string) (any, error) {
+		switch n := parent.(type) {
+		case map[string]any:
+			value, ok := n[key]
+			if !ok {
+				return nil, fmt.Errorf("%w: path %q is not found", ErrPatchConflict, key)
+			}
+			removed = value
+			delete(n, key)
+			return n, nil
+		case []any:
+			idx, err := arrayIndex(key, len(n), false)
+			if err != nil {
+				return nil, err
+			}
+			removed = n[idx]
+			return append(n[:idx], n[idx+1:]...), nil
+		}
+		return nil, fmt.Errorf("%w: path %q is not a container", ErrPatchConflict, key)
+	})
+	return doc, removed, err
+}
+
+// normalizeJSON converts json.Number into float64, so equal numbers are compared regardless of their text.
+func normalizeJSON(v any) any {
+	switch n := v.(type) {
+	case json.Number:
+		f, err := n.Float64()
+		if err != nil {
+			return n.String()
+		}
+		return f
+	case map[string]any:
+		m := make(map[string]any, len(n))
+		for k, child := range n {
+			m[k] = normalizeJSON(child)
+		}
+		return m
+	case []any:
+		s := make([]any, len(n))
+		for i, child := range n {
+			s[i] = normalizeJSON(child)
+		}
+		return s
+	}
+	return v
+}
@@ -0,0 +1,137 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type patchAddress struct {
+	City string `json:"city"`
+	Zip  string `json:"zip,omitempty"`
+}
+
+type PatchMeta struct {
+	Version int `json:"version"`
+	loaded  bool
+}
+
+type patchUser struct {
+	*PatchMeta
+	Name    string        `json:"name" validate:"required"`
+	Age     int           `json:"age"`
+	Tags    []string      `json:"tags"`
+	Address *patchAddress `json:"address,omitempty"`
+}
+
+func bindPatch(t *testing.T, contentType, body string) (PatchDocument[patchUser], error) {
+	t.Helper()
+	r := httptest.NewRequest(http.MethodPatch, "/users/1", strings.NewReader(body))
+	r.Header.Set(HeaderContentType.String(), contentType)
+	var doc PatchDocument[patchUser]
+	_, err := Bind(r, &doc)
+	return doc, err
+}
+
+func TestMergePatch(t *testing.T) {
+	is := assert.New(t)
+	doc, err := bindPatch(t, MIMEApplicationMergePatchJSON.String(),
+		`{"age":0,"address":{"city":"Bandung"},"tags":null}`)
+	is.NoError(err)
+	is.ElementsMatch([]string{"age", "address/city", "tags"}, doc.Fields())
+	is.True(doc.Touched("address"))
+	is.False(doc.Touched("name"))
+
+	user := patchUser{Name: "Ana", Age: 30, Tags: []string{"a"}, Address: &patchAddress{City: "Jakarta", Zip: "10110"}}
+	is.NoError(doc.Apply(&user))
+	is.Equal(patchUser{Name: "Ana", Address: &patchAddress{City: "Bandung", Zip: "10110"}}, user)
+}
+
+func TestJSONPatch(t *testing.T) {
+	is := assert.New(t)
+	doc, err := bindPatch(t, MIMEApplicationJSONPatchJSON.String(), `[
+		{"op":"test","path":"/name","value":"Ana"},
+		{"op":"add","path":"/tags/0","value":"first"},
+		{"op":"add","path":"/tags/-","value":"last"},
+		{"op":"copy","from":"/name","path":"/address/city"},
+		{"op":"replace","path":"/age","value":31},
+		{"op":"remove","path":"/address/zip"}
+	]`)
+	is.NoError(err)
+	is.Equal([]string{"tags/0", "tags/-", "address/city", "age", "address/zip"}, doc.Fields())
+
+	user := patchUser{Name: "Ana", Age: 30, Tags: []string{"a"}, Address: &patchAddress{City: "Jakarta", Zip: "10110"}}
+	is.NoError(doc.Apply(&user))
+	is.Equal(patchUser{Name: "Ana", Age: 31, Tags: []string{"first", "a", "last"}, Address: &patchAddress{City: "Ana"}}, user)
+}
+
+func TestPatchErrors(t *testing.T) {
+	is := assert.New(t)
+	_, err := bindPatch(t, MIMEApplicationJSONPatchJSON.String(), `[{"op":"jump","path":"/name"}]`)
+	is.True(errors.Is(err, ErrInvalidPatch))
+
+	user := patchUser{Name: "Ana", Age: 30}
+	doc, err := bindPatch(t, MIMEApplicationJSONPatchJSON.String(), `[
+		{"op":"replace","path":"/age","value":1},
+		{"op":"test","path":"/name","value":"Budi"}
+	]`)
+	is.NoError(err)
+	is.True(errors.Is(doc.Apply(&user), ErrPatchConflict))
+	is.Equal(30, user.Age)
+
+	doc, err = bindPatch(t, MIMEApplicationMergePatchJSON.String(), `{"name":null}`)
+	is.NoError(err)
+	is.Error(doc.Apply(&user))
+	is.Equal("Ana", user.Name)
+}
+
+func TestPatchKeepsHiddenFields(t *testing.T) {
+	is := assert.New(t)
+	type resource struct {
+		patchUser
+		Secret string `json:"-"`
+		config string
+	}
+	meta := &PatchMeta{Version: 1, loaded: true}
+	res := resource{patchUser: patchUser{PatchMeta: meta, Name: "Ana", Age: 30}, Secret: "s", config: "c"}
+	r := httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(`{"age":31,"version":2}`))
+	r.Header.Set(HeaderContentType.String(), MIMEApplicationMergePatchJSON.String())
+	var doc PatchDocument[resource]
+	_, err := Bind(r, &doc)
+	is.NoError(err)
+	is.NoError(doc.Apply(&res))
+	is.Equal(31, res.Age)
+	is.Equal("s", res.Secret)
+	is.Equal("c", res.config)
+	is.Equal(&PatchMeta{Version: 2, loaded: true}, res.PatchMeta)
+	is.Equal(1, meta.Version, "the embedded pointer of resource is copied")
+}
+
+func TestPatchStatus(t *testing.T) {
+	is := assert.New(t)
+	h := HandlerAdapter[PatchDocument[patchUser]](func(w http.ResponseWriter, r *http.Request) (any, error) {
+		doc, err := GetBind[PatchDocument[patchUser]](r)
+		if err != nil {
+			return nil, err
+		}
+		user := patchUser{Name: "Ana"}
+		return user, doc.Apply(&user)
+	})
+	serve := func(contentType, body string) int {
+		r := httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(body))
+		r.Header.Set(HeaderContentType.String(), contentType)
+		rec := httptest.NewRecorder()
+		h.JSON(rec, r)
+		return rec.Code
+	}
+	is.Equal(http.StatusOK, serve(MIMEApplicationMergePatchJSON.String(), `{"age":1}`))
+	is.Equal(http.StatusConflict, serve(MIMEApplicationJSONPatchJSON.String(), `[{"op":"test","path":"/name","value":"Budi"}]`))
+	is.Equal(http.StatusConflict, serve(MIMEApplicationJSONPatchJSON.String(), `[{"op":"add","path":"/age","value":"x"}]`))
+	// the document which is not a patch can not be applied.
+	is.Equal(http.StatusUnprocessableEntity, serve(MIMEApplicationJSON.String(), `{"age":1}`))
+	is.Equal(http.StatusBadRequest, serve(MIMEApplicationJSONPatchJSON.String(), `[{"op":"jump","path":"/age"}]`))
+}
@@ -0,0 +1,130 @@
+// Package rest is port adapter via http/s protocol
+// # This manifest was generated by ymir. DO NOT EDIT.
+package rest
+
+import (
+	"errors"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrRateLimited is define error when the request exceeds the rate limit.
+var ErrRateLimited = errors.New("rate limit is exceeded")
+
+// KeyByRemoteIP returns the client ip of request as rate limit key.
+func KeyByRemoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// RateLimit is middleware handler to allow requests per period of every key with token bucket,
+// KeyByRemoteIP is used when keyFunc is nil. The quota is sent by RateLimit-Limit, RateLimit-Remaining
+// and RateLimit-Reset headers, requests over the limit are answered with 429 and Retry-After.
+func RateLimit(requests int, per time.Duration, keyFunc func(r *http.Request) string) func(next http.Handler) http.Handler {
+	if requests < 1 || per <= 0 {
+		panic("rate limit: requests and period must be positive")
+	}
+	if keyFunc == nil {
+		keyFunc = KeyByRemoteIP
+	}
+	l := &rateLimiter{
+		capacity: float64(requests),
+		rate:     float64(requests) / per.Seconds(),
+		idle:     per,
+		buckets:  make(map[string]*tokenBucket),
+		now:      time.Now,
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			quota, ok := l.allow(keyFunc(r))
+			w.Header().Set(HeaderRateLimitLimit.String(), strconv.Itoa(requests))
+			w.Header().Set(HeaderRateLimitRemaining.String(), strconv.Itoa(quota.remaining))
+			w.Header().Set(HeaderRateLimitReset.String(), ceilSeconds(quota.reset))
+			if !ok {
+				w.Header().Set(HeaderRetryAfter.String(), ceilSeconds(quota.wait))
+				errorEnvelope(w, r, ErrTooManyRequests, ErrRateLimited)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// rateLimiter keeps a token bucket per key, idle buckets are swept periodically.
+type rateLimiter struct {
+	mu        sync.Mutex
+	capacity  float64
+	rate      float64 // tokens per second
+	idle      time.Duration
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// rateQuota is the bucket state of key after the request.
+type rateQuota struct {
+	remaining int
+	// reset is the duration until the bucket is full.
+	reset time.Duration
+	// wait is the duration until the next token when the request is limited.
+	wait time.Duration
+}
+
+// allow takes a token of key, false is returned when the bucket is empty.
+func (l *rateLimiter) allow(key string) (rateQuota, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	l.sweep(now)
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: l.capacity, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(l.capacity, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+	ok = b.tokens >= 1
+	quota := rateQuota{}
+	if ok {
+		b.tokens--
+	} else {
+		quota.wait = l.seconds(1 - b.tokens)
+	}
+	quota.remaining = int(b.tokens)
+	quota.reset = l.seconds(l.capacity - b.tokens)
+	return quota, ok
+}
+
+// seconds returns the duration to refill the tokens.
+func (l *rateLimiter) seconds(tokens float64) time.Duration {
+	return time.Duration(tokens / l.rate * float64(time.Second))
+}
+
+// sweep removes the buckets which are refilled completely.
+func (l *rateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.idle {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		if now.Sub(b.last) >= l.idle {
+			delete(l.buckets, key)
+		}
+	}
+}
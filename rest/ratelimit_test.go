@@ -0,0 +1,74 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimit(t *testing.T) {
+	is := assert.New(t)
+	h := RateLimit(2, time.Minute, func(r *http.Request) string {
+		return r.Header.Get("X-Client")
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	serve := func(client string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("X-Client", client)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+		return rec
+	}
+
+	rec := serve("a")
+	is.Equal(http.StatusOK, rec.Code)
+	is.Equal("2", rec.Header().Get(HeaderRateLimitLimit.String()))
+	is.Equal("1", rec.Header().Get(HeaderRateLimitRemaining.String()))
+	is.Equal("30", rec.Header().Get(HeaderRateLimitReset.String()))
+	is.Equal("0", serve("a").Header().Get(HeaderRateLimitRemaining.String()))
+
+	rec = serve("a")
+	is.Equal(http.StatusTooManyRequests, rec.Code)
+	is.Equal("30", rec.Header().Get(HeaderRetryAfter.String()))
+	is.Equal("0", rec.Header().Get(HeaderRateLimitRemaining.String()))
+	is.Equal("60", rec.Header().Get(HeaderRateLimitReset.String()))
+	is.Contains(rec.Body.String(), `"code":"429"`)
+	is.Contains(rec.Body.String(), ErrRateLimited.Error())
+
+	// every key has its own bucket.
+	is.Equal(http.StatusOK, serve("b").Code)
+	is.Panics(func() { RateLimit(0, time.Minute, nil) })
+}
+
+func TestRateLimiter(t *testing.T) {
+	is := assert.New(t)
+	now := time.Unix(0, 0)
+	l := &rateLimiter{
+		capacity: 2,
+		rate:     2.0 / 60,
+		idle:     time.Minute,
+		buckets:  make(map[string]*tokenBucket),
+		now:      func() time.Time { return now },
+	}
+	for i := 0; i < 2; i++ {
+		_, ok := l.allow("a")
+		is.True(ok)
+	}
+	quota, ok := l.allow("a")
+	is.False(ok)
+	is.Equal(30*time.Second, quota.wait)
+
+	// the token is refilled after the wait.
+	now = now.Add(30 * time.Second)
+	quota, ok = l.allow("a")
+	is.True(ok)
+	is.Equal(0, quota.remaining)
+	is.Equal(time.Minute, quota.reset)
+
+	// the idle bucket is swept.
+	now = now.Add(2 * time.Minute)
+	_, _ = l.allow("b")
+	is.NotContains(l.buckets, "a")
+}
@@ -7,18 +7,28 @@ import (
 	"net/http"
 )
 
+// ErrPayloadNotFound is define error when the bound request payload is not on the request context.
+var ErrPayloadNotFound = errors.New("request payload is not found")
+
 // RequestType - Custom type to hold value for find and replace on context value request type.
 type RequestType int
 
 // CtxPayloadRequest Declare related constants for each RequestType starting with index 1.
-const CtxPayloadRequest RequestType = iota
+const (
+	CtxPayloadRequest RequestType = iota
+	CtxUploadConfig
+	CtxUploadFiles
+	CtxStrictJSON
+	CtxRouteInfo
+	CtxLocale
+)
 
 // GetBind send a Pagination data.
 func GetBind[T any](r *http.Request) (T, error) {
 	var zero T
 	payload, ok := r.Context().Value(CtxPayloadRequest).(T)
 	if !ok {
-		return zero, errors.New("request payload is not found")
+		return zero, ErrPayloadNotFound
 	}
 	return payload, nil
 }
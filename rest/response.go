@@ -6,7 +6,6 @@ import (
 	"bytes"
 	"context"
 	"encoding/csv"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"math"
@@ -16,6 +15,13 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
+var (
+	// ErrResourceNotFound is define error when no route matches the request.
+	ErrResourceNotFound = errors.New("resource is not found")
+	// ErrBodyTooLarge is define error when the request body exceeds the http.MaxBytesReader limit.
+	ErrBodyTooLarge = errors.New("request body is too large")
+)
+
 // ResponseCSV - Custom type to hold value from [][]string type to csv format response.
 type ResponseCSV struct {
 	Filename string
@@ -30,14 +36,15 @@ const (
 	CtxPagination ResponseType = iota
 	CtxVersion
 	CtxStatusCode
+	CtxEnvelope
 )
 
 func (r ResponseType) String() string {
 	return [...]string{
 		"pagination-key",
 		"version-key",
-		"payload-key",
 		"status-code-key",
+		"envelope-key",
 	}[r]
 }
 
@@ -91,41 +98,38 @@ func (e *Response[W, R]) processRequest(r *http.Request) error {
 	return nil
 }
 
+// bindError writes the status code of binding error.
+func bindError(w http.ResponseWriter, r *http.Request, err error) error {
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.Is(err, ErrUploadTooLarge):
+		return ErrRequestEntityTooLarge(w, r, err)
+	case errors.As(err, &maxBytesErr):
+		return ErrRequestEntityTooLarge(w, r, fmt.Errorf("%w: %w", ErrBodyTooLarge, err))
+	case errors.Is(err, ErrUploadTypeNotAllowed), errors.Is(err, ErrUnsupportedContentType):
+		return ErrUnsupportedMediaType(w, r, err)
+	case errors.Is(err, ErrStrictJSON), errors.Is(err, ErrInvalidPatch), malformedJSON(err):
+		return ErrBadRequest(w, r, err)
+	}
+	return err
+}
+
 func (e *Response[W, R]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	errFunc := func(err error) {
-		code, ok := r.Context().Value(CtxStatusCode).(int)
-		if !ok || code < 1 {
-			code = http.StatusInternalServerError
-			*r = *r.WithContext(context.WithValue(r.Context(), CtxStatusCode, code))
-		}
-		w.Header().Set(HeaderContentType.String(), MIMEApplicationJSON.String())
-		w.Header().Set(HeaderContentTypeOptions.String(), "nosniff")
-		e.Meta = Meta{
-			Code:    strconv.Itoa(code),
-			Message: err.Error(),
-		}
-		b, err := json.Marshal(e)
-		if err != nil {
-			log.Error().Err(ErrInternalServerError(w, r, err)).Msg("Marshal")
-			return
-		}
-		_, err = w.Write(b)
-		if err != nil {
-			log.Error().Err(ErrInternalServerError(w, r, err)).Msg("Write")
-			return
-		}
-		return
+		e.writeError(w, r, err)
 	}
+	e.Version = Version{}
 	if ver, ok := r.Context().Value(CtxVersion).(Version); ok {
 		e.Version = ver
 	}
+	defer CleanupUploads(r)
 	if err := e.processRequest(r); err != nil {
-		errFunc(err)
+		errFunc(bindError(w, r, err))
 		return
 	}
 	payload, err := e.next(w, r)
 	if err != nil {
-		errFunc(err)
+		errFunc(patchError(w, r, err))
 		return
 	}
 	if pagination, ok := r.Context().Value(CtxPagination).(Pagination); ok {
@@ -134,6 +138,54 @@ func (e *Response[W, R]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	e.Data = payload
 }
 
+// writeError sends the error envelope, status code is taken from request context.
+func (e *Response[W, R]) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	env := EnvelopeOf(r)
+	code, ok := r.Context().Value(CtxStatusCode).(int)
+	if !ok || code < 1 {
+		code = http.StatusInternalServerError
+		*r = *r.WithContext(context.WithValue(r.Context(), CtxStatusCode, code))
+	}
+	w.Header().Set(HeaderContentType.String(), env.ContentType())
+	w.Header().Set(HeaderContentTypeOptions.String(), "nosniff")
+	e.Meta = Meta{
+		Code:    strconv.Itoa(code),
+		Message: Translate(r, err),
+	}
+	b, err := env.Marshal(e.parts(code, err))
+	if err != nil {
+		log.Error().Err(ErrInternalServerError(w, r, err)).Msg("Marshal")
+		return
+	}
+	_, err = w.Write(b)
+	if err != nil {
+		log.Error().Err(ErrInternalServerError(w, r, err)).Msg("Write")
+		return
+	}
+}
+
+// parts returns the envelope parts of response.
+func (e *Response[W, R]) parts(code int, err error) EnvelopeParts {
+	return EnvelopeParts{
+		StatusCode: code,
+		Meta:       e.Meta,
+		Version:    e.Version,
+		Pagination: e.Pagination,
+		Data:       e.Data,
+		Err:        err,
+	}
+}
+
+// errorEnvelope writes the error envelope outside of HandlerAdapter, e.g. from middleware handler.
+func errorEnvelope(w http.ResponseWriter, r *http.Request, status func(http.ResponseWriter, *http.Request, error) error, err error) {
+	w.Header().Set(HeaderContentType.String(), EnvelopeOf(r).ContentType())
+	response := HandlerAdapter[any, any](nil)
+	if ver, ok := r.Context().Value(CtxVersion).(Version); ok {
+		response.Version = ver
+	}
+	response.writeError(w, r, status(w, r, err))
+}
+
 // JSON sends a JSON response with status code.
 func (e *Response[W, R]) JSON(w http.ResponseWriter, r *http.Request) {
 	e.Data = make(map[string]any) // reset data struct
@@ -145,18 +197,18 @@ func (e *Response[W, R]) JSON(w http.ResponseWriter, r *http.Request) {
 	if code >= http.StatusBadRequest {
 		return
 	}
-	w.Header().Set(HeaderContentType.String(), MIMEApplicationJSON.String())
+	env := EnvelopeOf(r)
+	w.Header().Set(HeaderContentType.String(), env.ContentType())
 	e.Meta = Meta{
 		Code: strconv.Itoa(code),
 	}
-	buf := &bytes.Buffer{}
-	enc := json.NewEncoder(buf)
-	enc.SetEscapeHTML(true)
-
-	if err := enc.Encode(e); err != nil {
+	b, err := env.Marshal(e.parts(code, nil))
+	if err != nil {
 		log.Error().Err(ErrInternalServerError(w, r, err)).Msg("JSON")
 		return
 	}
+	buf := bytes.NewBuffer(b)
+	buf.WriteByte('\n')
 
 	w.WriteHeader(code)
 
@@ -228,6 +280,6 @@ type ResponseNotFound struct{}
 // not be found. The default 404 handler is `http.NotFound`.
 func NotFoundDefault() http.HandlerFunc {
 	return HandlerAdapter[RequestNotFound](func(w http.ResponseWriter, r *http.Request) (ResponseNotFound, error) {
-		return ResponseNotFound{}, ErrNotFound(w, r, errors.New("resource is not found"))
+		return ResponseNotFound{}, ErrNotFound(w, r, ErrResourceNotFound)
 	}).JSON
 }
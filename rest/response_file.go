@@ -0,0 +1,176 @@
+// Package rest is port adapter via http/s protocol
+// # This manifest was generated by ymir. DO NOT EDIT.
+package rest
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	// ErrFileNotFound is define error when the file of ResponseFile does not exist.
+	ErrFileNotFound = errors.New("file is not found")
+	// ErrFileNotOpened is define error when the file of ResponseFile can not be opened.
+	ErrFileNotOpened = errors.New("file can not be opened")
+)
+
+// ResponseFile - Custom type to hold value from io.ReadSeeker or file path to download response.
+// Single and multi range requests, If-Range and ETag preconditions are supported.
+type ResponseFile struct {
+	// Filename is the name sent on Content-Disposition, base name of Path is used when it is empty.
+	Filename string
+	// Path is the file to serve when Content is nil.
+	Path string
+	// Content is the file content, it is closed after serving when it implements io.Closer.
+	Content io.ReadSeeker
+	// ContentType is detected from Filename extension or content when it is empty.
+	ContentType string
+	// ModTime is sent as Last-Modified, file modification time is used for Path.
+	ModTime time.Time
+	// ETag is the entity tag, strong tag of size and modification time is generated for Path.
+	ETag string
+	// Inline shows the file in browser instead of downloading it as attachment.
+	Inline bool
+}
+
+// ResponseStream - Custom type to hold value from io.Reader to streaming response, ranges are not supported.
+type ResponseStream struct {
+	// Filename is the name sent on Content-Disposition.
+	Filename string
+	// Reader is the stream content, it is closed after serving when it implements io.Closer.
+	Reader io.Reader
+	// ContentType is application/octet-stream when it is empty.
+	ContentType string
+	// Inline shows the stream in browser instead of downloading it as attachment.
+	Inline bool
+}
+
+// File sends a ResponseFile or ResponseStream response with status code.
+func (e *Response[W, R]) File(w http.ResponseWriter, r *http.Request) {
+	e.Data = make(map[string]any) // reset data struct
+	e.ServeHTTP(w, r)
+	code, ok := r.Context().Value(CtxStatusCode).(int)
+	if !ok || code < 1 {
+		code = http.StatusOK
+	}
+	if code >= http.StatusBadRequest {
+		return
+	}
+	e.Meta = Meta{
+		Code: strconv.Itoa(code),
+	}
+
+	switch data := e.Data.(type) {
+	case ResponseFile:
+		e.serveFile(w, r, &data)
+		return
+	case *ResponseFile:
+		e.serveFile(w, r, data)
+		return
+	case ResponseStream:
+		serveStream(w, r, code, &data)
+		return
+	case *ResponseStream:
+		serveStream(w, r, code, data)
+		return
+	}
+	http.Error(w, http.ErrNotSupported.Error(), http.StatusBadRequest)
+}
+
+func (e *Response[W, R]) serveFile(w http.ResponseWriter, r *http.Request, data *ResponseFile) {
+	content := data.Content
+	if content == nil {
+		f, err := os.Open(data.Path)
+		if err != nil {
+			e.Data = make(map[string]any)
+			if errors.Is(err, os.ErrNotExist) {
+				e.writeError(w, r, ErrNotFound(w, r, ErrFileNotFound))
+				return
+			}
+			log.Error().Err(err).Msg("File")
+			e.writeError(w, r, ErrInternalServerError(w, r, ErrFileNotOpened))
+			return
+		}
+		stat, err := f.Stat()
+		if err != nil {
+			_ = f.Close()
+			e.Data = make(map[string]any)
+			e.writeError(w, r, ErrInternalServerError(w, r, err))
+			return
+		}
+		if data.ModTime.IsZero() {
+			data.ModTime = stat.ModTime()
+		}
+		if data.ETag == "" {
+			data.ETag = fmt.Sprintf(`"%x-%x"`, stat.Size(), stat.ModTime().UnixNano())
+		}
+		if data.Filename == "" {
+			data.Filename = filepath.Base(data.Path)
+		}
+		content = f
+	}
+	if closer, ok := content.(io.Closer); ok {
+		defer func() {
+			_ = closer.Close()
+		}()
+	}
+	if data.ContentType != "" {
+		w.Header().Set(HeaderContentType.String(), data.ContentType)
+	}
+	if data.ETag != "" {
+		w.Header().Set(HeaderETag.String(), data.ETag)
+	}
+	w.Header().Set(HeaderContentDisposition.String(), contentDisposition(data.Filename, data.Inline))
+	// ServeContent handles Range, If-Range, If-Match, If-None-Match and If-Modified-Since.
+	http.ServeContent(w, r, data.Filename, data.ModTime, content)
+}
+
+func serveStream(w http.ResponseWriter, r *http.Request, code int, data *ResponseStream) {
+	if closer, ok := data.Reader.(io.Closer); ok {
+		defer func() {
+			_ = closer.Close()
+		}()
+	}
+	if data.Reader == nil {
+		http.Error(w, http.ErrNotSupported.Error(), http.StatusBadRequest)
+		return
+	}
+	contentType := data.ContentType
+	if contentType == "" {
+		contentType = MIMEOctetStream.String()
+	}
+	w.Header().Set(HeaderContentType.String(), contentType)
+	w.Header().Set(HeaderAcceptRanges.String(), "none")
+	w.Header().Set(HeaderContentDisposition.String(), contentDisposition(data.Filename, data.Inline))
+	w.WriteHeader(code)
+	if r.Method == http.MethodHead {
+		return
+	}
+	if _, err := io.Copy(w, data.Reader); err != nil {
+		log.Error().Err(err).Msg("Stream")
+	}
+}
+
+// contentDisposition returns Content-Disposition value with escaped filename.
+func contentDisposition(filename string, inline bool) string {
+	disposition := "attachment"
+	if inline {
+		disposition = "inline"
+	}
+	if filename == "" {
+		return disposition
+	}
+	if v := mime.FormatMediaType(disposition, map[string]string{"filename": filename}); v != "" {
+		return v
+	}
+	return disposition
+}
@@ -0,0 +1,92 @@
+package rest
+
+import (
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fileRequest struct{}
+
+func TestResponseFile(t *testing.T) {
+	is := assert.New(t)
+	path := filepath.Join(t.TempDir(), "report.txt")
+	is.NoError(os.WriteFile(path, []byte("hello world"), 0o600))
+	h := HandlerAdapter[fileRequest](func(w http.ResponseWriter, r *http.Request) (any, error) {
+		switch r.URL.Path {
+		case "/stream":
+			return ResponseStream{Filename: "report.csv", Reader: io.NopCloser(strings.NewReader("a,b\n"))}, nil
+		case "/missing":
+			return ResponseFile{Path: filepath.Join(filepath.Dir(path), "missing.txt")}, nil
+		}
+		return ResponseFile{Path: path}, nil
+	})
+	serve := func(target string, header map[string]string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, target, nil)
+		for k, v := range header {
+			r.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		h.File(rec, r)
+		return rec
+	}
+
+	rec := serve("/file", nil)
+	is.Equal(http.StatusOK, rec.Code)
+	is.Equal("hello world", rec.Body.String())
+	is.Equal(`attachment; filename=report.txt`, rec.Header().Get(HeaderContentDisposition.String()))
+	etag := rec.Header().Get(HeaderETag.String())
+	is.NotEmpty(etag)
+
+	rec = serve("/file", map[string]string{"Range": "bytes=0-4"})
+	is.Equal(http.StatusPartialContent, rec.Code)
+	is.Equal("hello", rec.Body.String())
+	is.Equal("bytes 0-4/11", rec.Header().Get("Content-Range"))
+
+	rec = serve("/file", map[string]string{"Range": "bytes=0-1,6-10"})
+	is.Equal(http.StatusPartialContent, rec.Code)
+	mediaType, params, err := mime.ParseMediaType(rec.Header().Get(HeaderContentType.String()))
+	is.NoError(err)
+	is.Equal("multipart/byteranges", mediaType)
+	reader := multipart.NewReader(rec.Body, params["boundary"])
+	var parts []string
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			break
+		}
+		b, _ := io.ReadAll(part)
+		parts = append(parts, string(b))
+	}
+	is.Equal([]string{"he", "world"}, parts)
+
+	// the served validator resumes the download, the stale one answers the whole file.
+	is.False(strings.HasPrefix(etag, "W/"), "If-Range requires the strong validator")
+	rec = serve("/file", map[string]string{"Range": "bytes=6-10", "If-Range": etag})
+	is.Equal(http.StatusPartialContent, rec.Code)
+	is.Equal("world", rec.Body.String())
+	rec = serve("/file", map[string]string{"Range": "bytes=0-4", "If-Range": `"stale"`})
+	is.Equal(http.StatusOK, rec.Code)
+	is.Equal("hello world", rec.Body.String())
+	rec = serve("/file", map[string]string{"If-None-Match": etag})
+	is.Equal(http.StatusNotModified, rec.Code)
+	rec = serve("/file", map[string]string{"Range": "bytes=20-30"})
+	is.Equal(http.StatusRequestedRangeNotSatisfiable, rec.Code)
+
+	rec = serve("/missing", nil)
+	is.Equal(http.StatusNotFound, rec.Code)
+	is.Contains(rec.Body.String(), "file is not found")
+
+	rec = serve("/stream", map[string]string{"Range": "bytes=0-1"})
+	is.Equal(http.StatusOK, rec.Code)
+	is.Equal("a,b\n", rec.Body.String())
+	is.Equal("none", rec.Header().Get(HeaderAcceptRanges.String()))
+}
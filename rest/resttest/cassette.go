@@ -0,0 +1,462 @@
+// Package resttest is test harness of rest adapters and routes in process.
+// # This manifest was generated by ymir. DO NOT EDIT.
+package resttest
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrNoInteraction is define error when replayed request does not match any recorded interaction.
+var ErrNoInteraction = errors.New("resttest: no recorded interaction matches the request")
+
+// redacted is the replacement of redacted values.
+const redacted = "REDACTED"
+
+// Mode - Custom type to hold value of recorder mode.
+type Mode int
+
+// Declare related constants for each Mode starting with index 0.
+const (
+	// ModeReplay serves recorded interactions only, unmatched requests fail.
+	ModeReplay Mode = iota
+	// ModeRecord sends requests to the network and records the interactions.
+	ModeRecord
+	// ModeReplayOrRecord replays when the cassette exists, otherwise records it.
+	ModeReplayOrRecord
+)
+
+// String - Creating common behavior - give the type a String function.
+func (m Mode) String() string {
+	return [...]string{
+		"replay",
+		"record",
+		"replay-or-record",
+	}[m]
+}
+
+// Interaction holds the recorded request and response.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request" yaml:"request"`
+	Response RecordedResponse `json:"response" yaml:"response"`
+}
+
+// RecordedRequest holds the recorded request.
+type RecordedRequest struct {
+	Method       string      `json:"method" yaml:"method"`
+	URL          string      `json:"url" yaml:"url"`
+	Header       http.Header `json:"header,omitempty" yaml:"header,omitempty"`
+	Body         string      `json:"body,omitempty" yaml:"body,omitempty"`
+	BodyEncoding string      `json:"body_encoding,omitempty" yaml:"body_encoding,omitempty"`
+}
+
+// RecordedResponse holds the recorded response.
+type RecordedResponse struct {
+	StatusCode   int         `json:"status_code" yaml:"status_code"`
+	Header       http.Header `json:"header,omitempty" yaml:"header,omitempty"`
+	Body         string      `json:"body,omitempty" yaml:"body,omitempty"`
+	BodyEncoding string      `json:"body_encoding,omitempty" yaml:"body_encoding,omitempty"`
+}
+
+// Cassette holds the recorded interactions of a test.
+type Cassette struct {
+	Interactions []*Interaction `json:"interactions" yaml:"interactions"`
+}
+
+// Matcher reports whether the request matches the recorded request. The url and body of request
+// are redacted like the recording, so they compare equal to the recorded values.
+type Matcher func(r *http.Request, body []byte, recorded RecordedRequest) bool
+
+// MatchMethod matches the request method.
+func MatchMethod(r *http.Request, _ []byte, recorded RecordedRequest) bool {
+	return r.Method == recorded.Method
+}
+
+// MatchURL matches the full request url including query string.
+func MatchURL(r *http.Request, _ []byte, recorded RecordedRequest) bool {
+	return r.URL.String() == recorded.URL
+}
+
+// MatchBody matches the request body.
+func MatchBody(_ *http.Request, body []byte, recorded RecordedRequest) bool {
+	b, err := decodeBody(recorded.Body, recorded.BodyEncoding)
+	return err == nil && bytes.Equal(body, b)
+}
+
+// MatchHeaders matches the values of headers, redacted headers never match.
+func MatchHeaders(names ...string) Matcher {
+	return func(r *http.Request, _ []byte, recorded RecordedRequest) bool {
+		for _, name := range names {
+			if strings.Join(r.Header.Values(name), ",") != strings.Join(recorded.Header.Values(name), ",") {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// MatchAll matches when every matcher matches.
+func MatchAll(matchers ...Matcher) Matcher {
+	return func(r *http.Request, body []byte, recorded RecordedRequest) bool {
+		for _, m := range matchers {
+			if !m(r, body, recorded) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// RecorderOption is recorder type return func.
+type RecorderOption = func(r *Recorder) error
+
+// WithMode will assign the recorder mode, ModeReplay is used unless go test runs with -resttest.update.
+func WithMode(mode Mode) RecorderOption {
+	return func(r *Recorder) error {
+		r.mode = mode
+		return nil
+	}
+}
+
+// WithMatcher will assign the request matcher, method and url are matched by default.
+func WithMatcher(matchers ...Matcher) RecorderOption {
+	return func(r *Recorder) error {
+		r.matcher = MatchAll(matchers...)
+		return nil
+	}
+}
+
+// WithRedactHeaders will append the headers redacted before saving,
+// Authorization, Proxy-Authorization, Cookie and Set-Cookie are redacted by default.
+func WithRedactHeaders(names ...string) RecorderOption {
+	return func(r *Recorder) error {
+		r.redactHeaders = append(r.redactHeaders, names...)
+		return nil
+	}
+}
+
+// WithRedactQuery will append the query parameters redacted before saving,
+// access_token, api_key, client_secret, password and token are redacted by default.
+func WithRedactQuery(names ...string) RecorderOption {
+	return func(r *Recorder) error {
+		r.redactQuery = append(r.redactQuery, names...)
+		return nil
+	}
+}
+
+// WithRedactBody will append the body redaction funcs applied on request and response body before saving.
+func WithRedactBody(fn func(body []byte) []byte) RecorderOption {
+	return func(r *Recorder) error {
+		r.redactBody = append(r.redactBody, fn)
+		return nil
+	}
+}
+
+// WithRedactJSONFields will redact the values of object keys in JSON bodies before saving.
+func WithRedactJSONFields(keys ...string) RecorderOption {
+	scrubbers := []Scrubber{replaceFields(redacted, keys)}
+	return WithRedactBody(func(body []byte) []byte {
+		var doc any
+		dec := json.NewDecoder(bytes.NewReader(body))
+		dec.UseNumber()
+		if err := dec.Decode(&doc); err != nil {
+			return body
+		}
+		b, err := json.Marshal(scrub("", doc, scrubbers))
+		if err != nil {
+			return body
+		}
+		return b
+	})
+}
+
+// WithRecorderTransport will assign the transport of recording, http.DefaultTransport is used by default.
+func WithRecorderTransport(rt http.RoundTripper) RecorderOption {
+	return func(r *Recorder) error {
+		r.next = rt
+		return nil
+	}
+}
+
+// WithAllowRepeat will replay the interactions more than once.
+func WithAllowRepeat() RecorderOption {
+	return func(r *Recorder) error {
+		r.repeat = true
+		return nil
+	}
+}
+
+// Recorder is http.RoundTripper to record and replay the cassette of interactions,
+// e.g. rest.NewClient(rest.WithClientTransport(recorder)).
+type Recorder struct {
+	t             testing.TB
+	path          string
+	mode          Mode
+	next          http.RoundTripper
+	matcher       Matcher
+	redactHeaders []string
+	redactQuery   []string
+	redactBody    []func(body []byte) []byte
+	repeat        bool
+
+	mu       sync.Mutex
+	cassette Cassette
+	used     []bool
+}
+
+// NewRecorder creates a recorder of cassette testdata/cassettes/<name>.yaml or the absolute path name,
+// the name with .json extension is saved as JSON. The recorded cassette is saved when the test is finished.
+func NewRecorder(t testing.TB, name string, opts ...RecorderOption) *Recorder {
+	t.Helper()
+	if filepath.Ext(name) == "" {
+		name += ".yaml"
+	}
+	path := name
+	if !filepath.IsAbs(path) {
+		path = filepath.Join("testdata", "cassettes", name)
+	}
+	rec := &Recorder{
+		t:       t,
+		path:    path,
+		mode:    ModeReplay,
+		next:    http.DefaultTransport,
+		matcher: MatchAll(MatchMethod, MatchURL),
+		redactHeaders: []string{
+			"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie",
+		},
+		redactQuery: []string{
+			"access_token", "api_key", "client_secret", "password", "token",
+		},
+	}
+	if *update {
+		rec.mode = ModeRecord
+	}
+	for _, opt := range opts {
+		if err := opt(rec); err != nil {
+			t.Fatalf("resttest: %v", err)
+		}
+	}
+	if rec.mode == ModeReplayOrRecord {
+		rec.mode = ModeRecord
+		if _, err := os.Stat(rec.path); err == nil {
+			rec.mode = ModeReplay
+		}
+	}
+	if rec.mode == ModeReplay {
+		if err := rec.load(); err != nil {
+			t.Fatalf("resttest: load cassette, run go test with -resttest.update to record it: %v", err)
+		}
+		return rec
+	}
+	t.Cleanup(func() {
+		if err := rec.Save(); err != nil {
+			t.Errorf("resttest: save cassette: %v", err)
+		}
+	})
+	return rec
+}
+
+// Mode returns the resolved recorder mode.
+func (r *Recorder) Mode() Mode {
+	return r.mode
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		_ = req.Body.Close()
+		req = req.Clone(req.Context())
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	if r.mode == ModeReplay {
+		return r.replay(req, body)
+	}
+	return r.record(req, body)
+}
+
+func (r *Recorder) replay(req *http.Request, body []byte) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// the request is compared as it is recorded.
+	matched := req.Clone(req.Context())
+	matched.URL = r.redactURL(req.URL)
+	body = r.redact(body)
+	for i, it := range r.cassette.Interactions {
+		if (r.used[i] && !r.repeat) || !r.matcher(matched, body, it.Request) {
+			continue
+		}
+		r.used[i] = true
+		b, err := decodeBody(it.Response.Body, it.Response.BodyEncoding)
+		if err != nil {
+			return nil, err
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", it.Response.StatusCode, http.StatusText(it.Response.StatusCode)),
+			StatusCode:    it.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        it.Response.Header.Clone(),
+			Body:          io.NopCloser(bytes.NewReader(b)),
+			ContentLength: int64(len(b)),
+			Request:       req,
+		}, nil
+	}
+	r.t.Errorf("resttest: unmatched request %s %s in cassette %s", req.Method, matched.URL, r.path)
+	return nil, fmt.Errorf("%w: %s %s", ErrNoInteraction, req.Method, matched.URL)
+}
+
+func (r *Recorder) record(req *http.Request, body []byte) (*http.Response, error) {
+	resp, err := r.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	it := &Interaction{
+		Request: RecordedRequest{
+			Method: req.Method,
+			URL:    r.redactURL(req.URL).String(),
+			Header: r.redactHeader(req.Header),
+		},
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Header:     r.redactHeader(resp.Header),
+		},
+	}
+	redactedBody, redactedRespBody := r.redact(body), r.redact(respBody)
+	it.Request.Body, it.Request.BodyEncoding = encodeBody(redactedBody)
+	it.Response.Body, it.Response.BodyEncoding = encodeBody(redactedRespBody)
+	setContentLength(it.Request.Header, redactedBody)
+	setContentLength(it.Response.Header, redactedRespBody)
+
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, it)
+	r.mu.Unlock()
+	return resp, nil
+}
+
+// Save writes the recorded cassette, it is called when the test is finished.
+func (r *Recorder) Save() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var (
+		b   []byte
+		err error
+	)
+	if filepath.Ext(r.path) == ".json" {
+		b, err = json.MarshalIndent(r.cassette, "", "  ")
+	} else {
+		b, err = yaml.Marshal(r.cassette)
+	}
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(r.path, b, 0o644) //nolint:gosec // cassette is redacted test data.
+}
+
+func (r *Recorder) load() error {
+	b, err := os.ReadFile(r.path)
+	if err != nil {
+		return err
+	}
+	if filepath.Ext(r.path) == ".json" {
+		err = json.Unmarshal(b, &r.cassette)
+	} else {
+		err = yaml.Unmarshal(b, &r.cassette)
+	}
+	if err != nil {
+		return err
+	}
+	r.used = make([]bool, len(r.cassette.Interactions))
+	return nil
+}
+
+func (r *Recorder) redactHeader(h http.Header) http.Header {
+	h = h.Clone()
+	for _, name := range r.redactHeaders {
+		if values := h.Values(name); len(values) > 0 {
+			h.Set(name, redacted)
+		}
+	}
+	return h
+}
+
+// redactURL returns the url of which query parameters are redacted, case is ignored on the names.
+func (r *Recorder) redactURL(u *url.URL) *url.URL {
+	query := u.Query()
+	changed := false
+	for key, values := range query {
+		for _, name := range r.redactQuery {
+			if strings.EqualFold(key, name) {
+				for i := range values {
+					values[i] = redacted
+				}
+				changed = true
+			}
+		}
+	}
+	if !changed {
+		return u
+	}
+	redactedURL := *u
+	redactedURL.RawQuery = query.Encode()
+	return &redactedURL
+}
+
+// setContentLength updates the recorded Content-Length to the redacted body.
+func setContentLength(h http.Header, body []byte) {
+	if h.Get("Content-Length") != "" {
+		h.Set("Content-Length", strconv.Itoa(len(body)))
+	}
+}
+
+func (r *Recorder) redact(body []byte) []byte {
+	for _, fn := range r.redactBody {
+		body = fn(body)
+	}
+	return body
+}
+
+// encodeBody returns the body as text, binary body is base64 encoded.
+func encodeBody(b []byte) (string, string) {
+	if utf8.Valid(b) {
+		return string(b), ""
+	}
+	return base64.StdEncoding.EncodeToString(b), "base64"
+}
+
+func decodeBody(body, encoding string) ([]byte, error) {
+	if encoding == "base64" {
+		return base64.StdEncoding.DecodeString(body)
+	}
+	return []byte(body), nil
+}
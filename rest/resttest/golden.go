@@ -0,0 +1,138 @@
+// Package resttest is test harness of rest adapters and routes in process.
+// # This manifest was generated by ymir. DO NOT EDIT.
+package resttest
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kubuskotak/asgard/rest"
+)
+
+// update is namespaced, so the importing test packages may still define their own -update flag.
+var update = flag.Bool("resttest.update", false, "update resttest golden files and cassettes")
+
+var (
+	timestampPattern = regexp.MustCompile(`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:?\d{2})?`)
+	uuidPattern      = regexp.MustCompile(`(?i)\b[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\b`)
+	ulidPattern      = regexp.MustCompile(`\b[0-7][0-9A-HJKMNP-TV-Z]{25}\b`)
+)
+
+// Scrubber replaces the volatile value of golden snapshot. It is called with every JSON value
+// and its object key, key is empty for array elements and non JSON body.
+type Scrubber func(key string, value any) any
+
+// ScrubFields replaces the values of object keys with "<scrubbed>".
+func ScrubFields(keys ...string) Scrubber {
+	return replaceFields("<scrubbed>", keys)
+}
+
+func replaceFields(replacement string, keys []string) Scrubber {
+	set := make(map[string]struct{}, len(keys))
+	for _, k := range keys {
+		set[k] = struct{}{}
+	}
+	return func(key string, value any) any {
+		if _, ok := set[key]; ok {
+			return replacement
+		}
+		return value
+	}
+}
+
+// ScrubRegexp replaces the matches of string values.
+func ScrubRegexp(re *regexp.Regexp, replacement string) Scrubber {
+	return func(_ string, value any) any {
+		if s, ok := value.(string); ok {
+			return re.ReplaceAllString(s, replacement)
+		}
+		return value
+	}
+}
+
+// DefaultScrubbers returns the scrubbers of timestamps, UUIDs and ULIDs, they are applied on every snapshot.
+func DefaultScrubbers() []Scrubber {
+	return []Scrubber{
+		ScrubRegexp(timestampPattern, "<timestamp>"),
+		ScrubRegexp(uuidPattern, "<uuid>"),
+		ScrubRegexp(ulidPattern, "<ulid>"),
+	}
+}
+
+// Golden asserts the response snapshot equals testdata/<name>.golden, run go test with -resttest.update to write it.
+// The snapshot holds the status code, content type and the indented body with sorted keys.
+func (r *Response) Golden(name string, scrubbers ...Scrubber) *Response {
+	r.t.Helper()
+	got := r.snapshot(append(DefaultScrubbers(), scrubbers...))
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			r.t.Fatalf("resttest: %v", err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil { //nolint:gosec // golden file is not secret.
+			r.t.Fatalf("resttest: %v", err)
+		}
+		return r
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		r.t.Fatalf("resttest: read golden file, run go test with -resttest.update to create it: %v", err)
+	}
+	assert.Equal(r.t, string(want), string(got), "golden file %s", path)
+	return r
+}
+
+func (r *Response) snapshot(scrubbers []Scrubber) []byte {
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "HTTP %d\n", r.Recorder.Code)
+	if ct := r.Recorder.Header().Get(rest.HeaderContentType.String()); ct != "" {
+		fmt.Fprintf(buf, "%s: %s\n", rest.HeaderContentType.String(), ct)
+	}
+	buf.WriteString("\n")
+
+	var doc any
+	dec := json.NewDecoder(bytes.NewReader(r.Body()))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		var body any = string(r.Body())
+		for _, s := range scrubbers {
+			body = s("", body)
+		}
+		buf.WriteString(strings.TrimRight(fmt.Sprint(body), "\n"))
+		buf.WriteString("\n")
+		return buf.Bytes()
+	}
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(scrub("", doc, scrubbers)); err != nil {
+		r.t.Fatalf("resttest: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// scrub walks the JSON tree and applies the scrubbers on every value.
+func scrub(key string, value any, scrubbers []Scrubber) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for k := range v {
+			v[k] = scrub(k, v[k], scrubbers)
+		}
+	case []any:
+		for i := range v {
+			v[i] = scrub("", v[i], scrubbers)
+		}
+	}
+	for _, s := range scrubbers {
+		value = s(key, value)
+	}
+	return value
+}
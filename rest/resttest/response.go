@@ -0,0 +1,116 @@
+// Package resttest is test harness of rest adapters and routes in process.
+// # This manifest was generated by ymir. DO NOT EDIT.
+package resttest
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kubuskotak/asgard/rest"
+)
+
+// Response holds the recorded response with chainable assertions.
+type Response struct {
+	t        testing.TB
+	Recorder *httptest.ResponseRecorder
+}
+
+// Code returns the status code.
+func (r *Response) Code() int {
+	return r.Recorder.Code
+}
+
+// Body returns the response body.
+func (r *Response) Body() []byte {
+	return r.Recorder.Body.Bytes()
+}
+
+// envelope returns the untyped response envelope.
+func (r *Response) envelope() rest.Result[json.RawMessage] {
+	r.t.Helper()
+	var result rest.Result[json.RawMessage]
+	if err := json.Unmarshal(r.Body(), &result); err != nil {
+		r.t.Fatalf("resttest: response is not an envelope: %v\n%s", err, r.Body())
+	}
+	return result
+}
+
+// Status asserts the status code on the wire and on the envelope meta when it is present.
+func (r *Response) Status(code int) *Response {
+	r.t.Helper()
+	assert.Equal(r.t, code, r.Recorder.Code, "status code, body: %s", r.Body())
+	var result rest.Result[json.RawMessage]
+	if err := json.Unmarshal(r.Body(), &result); err == nil && result.Meta.Code != "" {
+		assert.Equal(r.t, strconv.Itoa(code), result.Meta.Code, "meta code")
+	}
+	return r
+}
+
+// Header asserts the response header value.
+func (r *Response) Header(key, value string) *Response {
+	r.t.Helper()
+	assert.Equal(r.t, value, r.Recorder.Header().Get(key), "header %s", key)
+	return r
+}
+
+// Meta asserts the envelope meta.
+func (r *Response) Meta(meta rest.Meta) *Response {
+	r.t.Helper()
+	assert.Equal(r.t, meta, r.envelope().Meta, "meta")
+	return r
+}
+
+// ErrorMessage asserts the envelope error message contains substr.
+func (r *Response) ErrorMessage(substr string) *Response {
+	r.t.Helper()
+	assert.Contains(r.t, r.envelope().Meta.Message, substr, "error message")
+	return r
+}
+
+// Version asserts the envelope version.
+func (r *Response) Version(version rest.Version) *Response {
+	r.t.Helper()
+	assert.Equal(r.t, version, r.envelope().Version, "version")
+	return r
+}
+
+// Pagination asserts the envelope pagination.
+func (r *Response) Pagination(p rest.Pagination) *Response {
+	r.t.Helper()
+	assert.Equal(r.t, p, r.envelope().Pagination, "pagination")
+	return r
+}
+
+// ValidationError asserts the response has validation violation of every field,
+// field is the `json` name of struct field.
+func (r *Response) ValidationError(fields ...string) *Response {
+	r.t.Helper()
+	message := r.envelope().Meta.Message
+	for _, field := range fields {
+		assert.Contains(r.t, message, "input "+field, "validation error of %s", field)
+	}
+	return r
+}
+
+// Data decodes the envelope data into T.
+func Data[T any](r *Response) T {
+	r.t.Helper()
+	return Envelope[T](r).Data
+}
+
+// Envelope decodes the response envelope with typed data.
+func Envelope[T any](r *Response) rest.Result[T] {
+	r.t.Helper()
+	result := rest.Result[T]{
+		StatusCode: r.Recorder.Code,
+		Header:     r.Recorder.Header(),
+	}
+	if err := json.Unmarshal(r.Body(), &result); err != nil {
+		r.t.Fatalf("resttest: decode envelope: %v\n%s", err, r.Body())
+	}
+	return result
+}
@@ -0,0 +1,192 @@
+// Package resttest is test harness of rest adapters and routes in process.
+// # This manifest was generated by ymir. DO NOT EDIT.
+package resttest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/kubuskotak/asgard/rest"
+)
+
+// Harness holds the handler under test.
+type Harness struct {
+	t       testing.TB
+	handler http.Handler
+}
+
+// New creates a harness of handler, e.g. rest.HandlerAdapter(...).JSON, *rest.Router or chi router.
+func New(t testing.TB, handler http.Handler) *Harness {
+	t.Helper()
+	return &Harness{t: t, handler: handler}
+}
+
+// NewFunc creates a harness of handler func.
+func NewFunc(t testing.TB, handler http.HandlerFunc) *Harness {
+	t.Helper()
+	return New(t, handler)
+}
+
+// Get starts GET request of the pattern, see Request.Param.
+func (h *Harness) Get(pattern string) *Request { return h.Request(http.MethodGet, pattern) }
+
+// Post starts POST request of the pattern, see Request.Param.
+func (h *Harness) Post(pattern string) *Request { return h.Request(http.MethodPost, pattern) }
+
+// Put starts PUT request of the pattern, see Request.Param.
+func (h *Harness) Put(pattern string) *Request { return h.Request(http.MethodPut, pattern) }
+
+// Patch starts PATCH request of the pattern, see Request.Param.
+func (h *Harness) Patch(pattern string) *Request { return h.Request(http.MethodPatch, pattern) }
+
+// Delete starts DELETE request of the pattern, see Request.Param.
+func (h *Harness) Delete(pattern string) *Request { return h.Request(http.MethodDelete, pattern) }
+
+// Request starts request of method and pattern.
+func (h *Harness) Request(method, pattern string) *Request {
+	return &Request{
+		h:       h,
+		method:  method,
+		pattern: pattern,
+		query:   url.Values{},
+		header:  http.Header{},
+		ctx:     context.Background(),
+	}
+}
+
+// Request is the fluent builder of test request.
+type Request struct {
+	h           *Harness
+	method      string
+	pattern     string
+	params      []string
+	query       url.Values
+	header      http.Header
+	cookies     []*http.Cookie
+	body        io.Reader
+	contentType string
+	ctx         context.Context
+}
+
+// Param sets the url param of pattern, e.g. {id}. The param is substituted into the path
+// and added into chi route context, so adapters bind it without a router.
+func (r *Request) Param(key, value string) *Request {
+	r.params = append(r.params, key, value)
+	return r
+}
+
+// Query adds the query string value.
+func (r *Request) Query(key, value string) *Request {
+	r.query.Add(key, value)
+	return r
+}
+
+// Header adds the request header.
+func (r *Request) Header(key, value string) *Request {
+	r.header.Add(key, value)
+	return r
+}
+
+// Cookie adds the request cookie.
+func (r *Request) Cookie(c *http.Cookie) *Request {
+	r.cookies = append(r.cookies, c)
+	return r
+}
+
+// Bearer sets the Authorization header of bearer token.
+func (r *Request) Bearer(token string) *Request {
+	r.header.Set(rest.HeaderAuthorization.String(), "Bearer "+token)
+	return r
+}
+
+// JSON sets the JSON body of v.
+func (r *Request) JSON(v any) *Request {
+	r.h.t.Helper()
+	b, err := json.Marshal(v)
+	if err != nil {
+		r.h.t.Fatalf("resttest: marshal json body: %v", err)
+	}
+	return r.Body(rest.MIMEApplicationJSON.String(), b)
+}
+
+// Form sets the url encoded form body.
+func (r *Request) Form(values url.Values) *Request {
+	return r.Body(rest.MIMEApplicationForm.String(), []byte(values.Encode()))
+}
+
+// Body sets the raw body of content type.
+func (r *Request) Body(contentType string, b []byte) *Request {
+	r.contentType = contentType
+	r.body = bytes.NewReader(b)
+	return r
+}
+
+// Context sets the request context, e.g. with authenticated user value.
+func (r *Request) Context(ctx context.Context) *Request {
+	r.ctx = ctx
+	return r
+}
+
+// Build returns the http request.
+func (r *Request) Build() *http.Request {
+	path := r.pattern
+	rctx := chi.NewRouteContext()
+	for i := 0; i+1 < len(r.params); i += 2 {
+		key, value := r.params[i], r.params[i+1]
+		path = strings.Replace(path, "{"+key+"}", url.PathEscape(value), 1)
+		if idx := strings.Index(path, "{"+key+":"); idx >= 0 {
+			if end := strings.Index(path[idx:], "}"); end >= 0 {
+				path = path[:idx] + url.PathEscape(value) + path[idx+end+1:]
+			}
+		}
+		rctx.URLParams.Add(key, value)
+	}
+	if len(r.query) > 0 {
+		path += "?" + r.query.Encode()
+	}
+	req := httptest.NewRequest(r.method, path, r.body)
+	for key, values := range r.header {
+		for _, v := range values {
+			req.Header.Add(key, v)
+		}
+	}
+	if r.contentType != "" {
+		req.Header.Set(rest.HeaderContentType.String(), r.contentType)
+	}
+	for _, c := range r.cookies {
+		req.AddCookie(c)
+	}
+	ctx := r.ctx
+	// chi routers build their own route context, the injected one would make them route as sub-router.
+	if len(r.params) > 0 && !routed(r.h.handler) {
+		rctx.RoutePatterns = []string{r.pattern}
+		ctx = context.WithValue(ctx, chi.RouteCtxKey, rctx)
+	}
+	return req.WithContext(ctx)
+}
+
+// routed reports whether the handler is chi router or rest.Router.
+func routed(h http.Handler) bool {
+	switch h.(type) {
+	case chi.Routes, interface{ Mux() chi.Router }:
+		return true
+	}
+	return false
+}
+
+// Do serves the request and returns the recorded response.
+func (r *Request) Do() *Response {
+	r.h.t.Helper()
+	rec := httptest.NewRecorder()
+	r.h.handler.ServeHTTP(rec, r.Build())
+	return &Response{t: r.h.t, Recorder: rec}
+}
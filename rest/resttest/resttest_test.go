@@ -0,0 +1,170 @@
+package resttest
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kubuskotak/asgard/rest"
+)
+
+type itemRequest struct {
+	ID   string `schema:"id" json:"-"`
+	Name string `json:"name" validate:"required"`
+}
+
+type itemResponse struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	RequestID string    `json:"request_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func putItem(w http.ResponseWriter, r *http.Request) (itemResponse, error) {
+	req, err := rest.GetBind[itemRequest](r)
+	if err != nil {
+		return itemResponse{}, rest.ErrBadRequest(w, r, err)
+	}
+	if req.ID == "0" {
+		return itemResponse{}, rest.ErrNotFound(w, r, errors.New("item is not found"))
+	}
+	rest.Paging(r, rest.Pagination{Page: 1, Limit: 10, Total: 20})
+	return itemResponse{
+		ID:        req.ID,
+		Name:      req.Name,
+		RequestID: "01HBQ7X6M7Y8Z9A0B1C2D3E4F5",
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+func TestHarness(t *testing.T) {
+	is := assert.New(t)
+	h := NewFunc(t, rest.HandlerAdapter[itemRequest](putItem).JSON)
+
+	resp := h.Put("/items/{id}").Param("id", "7").JSON(map[string]string{"name": "book"}).Do().
+		Status(http.StatusOK).
+		Pagination(rest.Pagination{Page: 1, Limit: 10, Size: 2, Total: 20}).
+		Golden("put_item")
+	item := Data[itemResponse](resp)
+	is.Equal("7", item.ID)
+	is.Equal("book", item.Name)
+
+	h.Put("/items/{id}").Param("id", "0").JSON(map[string]string{"name": "book"}).Do().
+		Status(http.StatusNotFound).
+		ErrorMessage("item is not found")
+
+	h.Put("/items/{id}").Param("id", "7").JSON(map[string]string{}).Do().
+		ValidationError("name")
+}
+
+func TestHarnessRouter(t *testing.T) {
+	router := rest.NewRouter()
+	rest.Put[itemRequest, itemResponse](router, "/items/{id}", putItem)
+
+	result := Envelope[itemResponse](New(t, router).Put("/items/{id}").Param("id", "9").
+		JSON(map[string]string{"name": "pen"}).Do().Status(http.StatusOK))
+	assert.Equal(t, "9", result.Data.ID)
+	assert.Equal(t, "200", result.Meta.Code)
+}
+
+func TestHarnessChi(t *testing.T) {
+	is := assert.New(t)
+	router := chi.NewRouter()
+	router.Get("/items/{id}", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(chi.URLParam(r, "id") + " " + chi.RouteContext(r.Context()).RoutePattern()))
+	})
+	rec := New(t, router).Get("/items/{id}").Param("id", "7").Do().Status(http.StatusOK).Recorder
+	is.Equal("7 /items/{id}", rec.Body.String())
+}
+
+func TestRecorder(t *testing.T) {
+	is := assert.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"token":"secret","name":"` + r.URL.Query().Get("name") + `"}`))
+	}))
+	defer srv.Close()
+	path := filepath.Join(t.TempDir(), "items.json")
+
+	t.Run("record", func(t *testing.T) {
+		rec := NewRecorder(t, path, WithMode(ModeRecord), WithRedactJSONFields("token"))
+		client := rest.NewClient(rest.WithClientTransport(rec), rest.WithMaxAttempts(1))
+		resp, err := client.Get(srv.URL + "/items?name=book")
+		is.NoError(err)
+		body, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		is.JSONEq(`{"token":"secret","name":"book"}`, string(body))
+	})
+	srv.Close()
+
+	ft := &fakeTB{TB: t}
+	rec := NewRecorder(ft, path, WithMode(ModeReplay))
+	resp, err := rec.RoundTrip(httptest.NewRequest(http.MethodGet, srv.URL+"/items?name=book", nil))
+	is.NoError(err)
+	body, _ := io.ReadAll(resp.Body)
+	is.JSONEq(`{"token":"REDACTED","name":"book"}`, string(body))
+
+	_, err = rec.RoundTrip(httptest.NewRequest(http.MethodGet, srv.URL+"/items?name=book", nil))
+	is.ErrorIs(err, ErrNoInteraction)
+	is.True(ft.failed)
+}
+
+func TestRecorderRedaction(t *testing.T) {
+	is := assert.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		_, _ = w.Write(body)
+	}))
+	defer srv.Close()
+	path := filepath.Join(t.TempDir(), "login.json")
+	opts := []RecorderOption{
+		WithRedactJSONFields("password"),
+		WithRedactQuery("Signature"),
+		WithMatcher(MatchMethod, MatchURL, MatchBody),
+	}
+	post := func(rec *Recorder, password string) (*http.Response, error) {
+		r := httptest.NewRequest(http.MethodPost, srv.URL+"/login?user=ana&token=t1&signature=s1",
+			strings.NewReader(`{"user":"ana","password":"`+password+`"}`))
+		r.Header.Set("Content-Type", "application/json")
+		return rec.RoundTrip(r)
+	}
+
+	rec := NewRecorder(t, path, append(opts, WithMode(ModeRecord))...)
+	resp, err := post(rec, "secret")
+	is.NoError(err)
+	_ = resp.Body.Close()
+	is.NoError(rec.Save())
+	it := rec.cassette.Interactions[0]
+	is.Equal(srv.URL+"/login?signature=REDACTED&token=REDACTED&user=ana", it.Request.URL)
+	is.Equal(`{"password":"REDACTED","user":"ana"}`, it.Request.Body)
+	is.Equal(strconv.Itoa(len(it.Response.Body)), it.Response.Header.Get("Content-Length"))
+
+	// the replayed request is redacted before it is matched, the secrets may differ.
+	replay := NewRecorder(t, path, append(opts, WithMode(ModeReplay))...)
+	resp, err = post(replay, "other")
+	is.NoError(err)
+	body, _ := io.ReadAll(resp.Body)
+	is.Equal(it.Response.Body, string(body))
+	is.Equal(int64(len(body)), resp.ContentLength)
+}
+
+// fakeTB records the failure of unmatched request instead of failing the test.
+type fakeTB struct {
+	testing.TB
+	failed bool
+}
+
+func (f *fakeTB) Errorf(string, ...any) {
+	f.failed = true
+}
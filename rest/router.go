@@ -0,0 +1,345 @@
+// Package rest is port adapter via http/s protocol
+// # This manifest was generated by ymir. DO NOT EDIT.
+package rest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// RouteOption is route type return func.
+type RouteOption = func(ri *RouteInfo) error
+
+// RouteInfo holds the metadata of registered route, it is usable for docs, metrics labels and route listing.
+type RouteInfo struct {
+	Method   string
+	Pattern  string
+	Request  reflect.Type
+	Response reflect.Type
+	Summary  string
+	Tags     []string
+	// Auth is the name of authentication scheme, empty means public route.
+	Auth      string
+	Timeout   time.Duration
+	RateLimit *RouteRateLimit
+	BodyLimit int64
+
+	auth        func(next http.Handler) http.Handler
+	middlewares []func(next http.Handler) http.Handler
+}
+
+// RouteRateLimit holds the rate limit of route.
+type RouteRateLimit struct {
+	Requests int
+	Per      time.Duration
+	KeyFunc  func(r *http.Request) string
+}
+
+// WithAuth will protect the route by authentication middleware, scheme is recorded on route metadata.
+func WithAuth(scheme string, mw func(next http.Handler) http.Handler) RouteOption {
+	return func(ri *RouteInfo) error {
+		if mw == nil {
+			return errors.New("route: auth middleware is required")
+		}
+		ri.Auth = scheme
+		ri.auth = mw
+		return nil
+	}
+}
+
+// WithRouteTimeout will limit the route handler duration, see Timeout.
+func WithRouteTimeout(d time.Duration) RouteOption {
+	return func(ri *RouteInfo) error {
+		ri.Timeout = d
+		return nil
+	}
+}
+
+// WithRateLimit will limit the route requests per period of every key, see RateLimit.
+func WithRateLimit(requests int, per time.Duration, keyFunc func(r *http.Request) string) RouteOption {
+	return func(ri *RouteInfo) error {
+		if requests < 1 || per <= 0 {
+			return errors.New("route: rate limit requests and period must be positive")
+		}
+		ri.RateLimit = &RouteRateLimit{Requests: requests, Per: per, KeyFunc: keyFunc}
+		return nil
+	}
+}
+
+// WithBodyLimit will limit the route request body size in bytes, see BodyLimit.
+func WithBodyLimit(n int64) RouteOption {
+	return func(ri *RouteInfo) error {
+		ri.BodyLimit = n
+		return nil
+	}
+}
+
+// WithTags will append the tags of route.
+func WithTags(tags ...string) RouteOption {
+	return func(ri *RouteInfo) error {
+		ri.Tags = append(ri.Tags, tags...)
+		return nil
+	}
+}
+
+// WithSummary will assign the summary of route.
+func WithSummary(summary string) RouteOption {
+	return func(ri *RouteInfo) error {
+		ri.Summary = summary
+		return nil
+	}
+}
+
+// WithRouteMiddleware will append the middlewares of route, they run after the built-in route middlewares.
+func WithRouteMiddleware(mw ...func(next http.Handler) http.Handler) RouteOption {
+	return func(ri *RouteInfo) error {
+		ri.middlewares = append(ri.middlewares, mw...)
+		return nil
+	}
+}
+
+// RouteOf returns the metadata of the route serving the request.
+func RouteOf(r *http.Request) (RouteInfo, bool) {
+	ri, ok := r.Context().Value(CtxRouteInfo).(*RouteInfo)
+	if !ok {
+		return RouteInfo{}, false
+	}
+	return *ri, true
+}
+
+// routeRegistry keeps the route metadata of router and its mounted routers.
+type routeRegistry struct {
+	mu     sync.RWMutex
+	routes []*RouteInfo
+	mounts []routeMount
+}
+
+type routeMount struct {
+	prefix   string
+	registry *routeRegistry
+}
+
+func (g *routeRegistry) add(ri *RouteInfo) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.routes = append(g.routes, ri)
+}
+
+func (g *routeRegistry) mount(prefix string, registry *routeRegistry) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.mounts = append(g.mounts, routeMount{prefix: prefix, registry: registry})
+}
+
+func (g *routeRegistry) list(prefix string) []RouteInfo {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	routes := make([]RouteInfo, 0, len(g.routes))
+	for _, ri := range g.routes {
+		info := *ri
+		info.Pattern = joinPattern(prefix, ri.Pattern)
+		routes = append(routes, info)
+	}
+	for _, m := range g.mounts {
+		routes = append(routes, m.registry.list(joinPattern(prefix, m.prefix))...)
+	}
+	return routes
+}
+
+func joinPattern(prefix, pattern string) string {
+	if prefix == "" {
+		return pattern
+	}
+	if pattern == "/" || pattern == "" {
+		return prefix
+	}
+	return strings.TrimSuffix(prefix, "/") + pattern
+}
+
+// Router wraps chi router to register typed routes with metadata.
+type Router struct {
+	mux      chi.Router
+	prefix   string
+	registry *routeRegistry
+	defaults []RouteOption
+}
+
+// NewRouter creates a router, the options are the defaults of every route.
+func NewRouter(opts ...RouteOption) *Router {
+	return &Router{
+		mux:      chi.NewRouter(),
+		registry: &routeRegistry{},
+		defaults: opts,
+	}
+}
+
+// ServeHTTP implements http.Handler.
+func (rt *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rt.mux.ServeHTTP(w, r)
+}
+
+// Mux returns the underlying chi router.
+func (rt *Router) Mux() chi.Router {
+	return rt.mux
+}
+
+// Use appends the middlewares of router, it must be called before routes are registered.
+func (rt *Router) Use(mw ...func(next http.Handler) http.Handler) {
+	rt.mux.Use(mw...)
+}
+
+// NotFound sets the handler of unmatched routes.
+func (rt *Router) NotFound(h http.HandlerFunc) {
+	rt.mux.NotFound(h)
+}
+
+// MethodNotAllowed sets the handler of unmatched methods.
+func (rt *Router) MethodNotAllowed(h http.HandlerFunc) {
+	rt.mux.MethodNotAllowed(h)
+}
+
+// Group creates inline router sharing the prefix, the options are the defaults of its routes.
+func (rt *Router) Group(fn func(r *Router), opts ...RouteOption) {
+	rt.mux.Group(func(m chi.Router) {
+		fn(rt.sub(m, rt.prefix, opts))
+	})
+}
+
+// Route creates sub router of the pattern, the options are the defaults of its routes.
+func (rt *Router) Route(pattern string, fn func(r *Router), opts ...RouteOption) {
+	rt.mux.Route(pattern, func(m chi.Router) {
+		fn(rt.sub(m, joinPattern(rt.prefix, pattern), opts))
+	})
+}
+
+// Mount attaches the handler on the pattern, routes of mounted *Router are listed by Routes.
+func (rt *Router) Mount(pattern string, h http.Handler) {
+	if sub, ok := h.(*Router); ok {
+		rt.registry.mount(joinPattern(rt.prefix, pattern), sub.registry)
+	}
+	rt.mux.Mount(pattern, h)
+}
+
+// Handle registers the untyped handler, e.g. websocket or static files, with route metadata.
+func (rt *Router) Handle(method, pattern string, h http.Handler, opts ...RouteOption) {
+	rt.handle(method, pattern, h, nil, nil, opts)
+}
+
+// Routes returns the metadata of every route ordered by pattern and method.
+func (rt *Router) Routes() []RouteInfo {
+	routes := rt.registry.list("")
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].Pattern == routes[j].Pattern {
+			return routes[i].Method < routes[j].Method
+		}
+		return routes[i].Pattern < routes[j].Pattern
+	})
+	return routes
+}
+
+func (rt *Router) sub(m chi.Router, prefix string, opts []RouteOption) *Router {
+	defaults := make([]RouteOption, 0, len(rt.defaults)+len(opts))
+	defaults = append(defaults, rt.defaults...)
+	defaults = append(defaults, opts...)
+	return &Router{
+		mux:      m,
+		prefix:   prefix,
+		registry: rt.registry,
+		defaults: defaults,
+	}
+}
+
+func (rt *Router) handle(method, pattern string, h http.Handler, req, resp reflect.Type, opts []RouteOption) {
+	ri := &RouteInfo{
+		Method:   method,
+		Pattern:  joinPattern(rt.prefix, pattern),
+		Request:  req,
+		Response: resp,
+	}
+	for _, opt := range append(append([]RouteOption{}, rt.defaults...), opts...) {
+		if err := opt(ri); err != nil {
+			panic(err)
+		}
+	}
+
+	for i := len(ri.middlewares) - 1; i >= 0; i-- {
+		h = ri.middlewares[i](h)
+	}
+	if ri.Timeout > 0 {
+		h = Timeout(ri.Timeout)(h)
+	}
+	if ri.BodyLimit > 0 {
+		h = BodyLimit(ri.BodyLimit)(h)
+	}
+	if ri.auth != nil {
+		h = ri.auth(h)
+	}
+	if ri.RateLimit != nil {
+		h = RateLimit(ri.RateLimit.Requests, ri.RateLimit.Per, ri.RateLimit.KeyFunc)(h)
+	}
+	next := h
+	h = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		info := ri
+		if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != ri.Pattern {
+			// mounted router, the pattern is completed by the parent routers.
+			full := *ri
+			full.Pattern = rctx.RoutePattern()
+			info = &full
+		}
+		*r = *r.WithContext(context.WithValue(r.Context(), CtxRouteInfo, info))
+		next.ServeHTTP(w, r)
+	})
+	rt.registry.add(ri)
+	rt.mux.Method(method, pattern, h)
+}
+
+// adapterHandler returns the writer of HandlerAdapter matching the response type.
+func adapterHandler[Req RequestConstraint, Resp ResponseConstraint](a Adapter[Req, Resp]) http.HandlerFunc {
+	response := HandlerAdapter[Req](a)
+	switch any(*new(Resp)).(type) {
+	case ResponseCSV:
+		return response.CSV
+	case ResponseFile, *ResponseFile, ResponseStream, *ResponseStream:
+		return response.File
+	}
+	return response.JSON
+}
+
+func register[Req RequestConstraint, Resp ResponseConstraint](
+	rt *Router, method, pattern string, a Adapter[Req, Resp], opts []RouteOption) {
+	rt.handle(method, pattern, adapterHandler(a),
+		reflect.TypeOf((*Req)(nil)).Elem(), reflect.TypeOf((*Resp)(nil)).Elem(), opts)
+}
+
+// Get registers the typed adapter on GET pattern.
+func Get[Req RequestConstraint, Resp ResponseConstraint](rt *Router, pattern string, a Adapter[Req, Resp], opts ...RouteOption) {
+	register(rt, http.MethodGet, pattern, a, opts)
+}
+
+// Post registers the typed adapter on POST pattern.
+func Post[Req RequestConstraint, Resp ResponseConstraint](rt *Router, pattern string, a Adapter[Req, Resp], opts ...RouteOption) {
+	register(rt, http.MethodPost, pattern, a, opts)
+}
+
+// Put registers the typed adapter on PUT pattern.
+func Put[Req RequestConstraint, Resp ResponseConstraint](rt *Router, pattern string, a Adapter[Req, Resp], opts ...RouteOption) {
+	register(rt, http.MethodPut, pattern, a, opts)
+}
+
+// Patch registers the typed adapter on PATCH pattern.
+func Patch[Req RequestConstraint, Resp ResponseConstraint](rt *Router, pattern string, a Adapter[Req, Resp], opts ...RouteOption) {
+	register(rt, http.MethodPatch, pattern, a, opts)
+}
+
+// Delete registers the typed adapter on DELETE pattern.
+func Delete[Req RequestConstraint, Resp ResponseConstraint](rt *Router, pattern string, a Adapter[Req, Resp], opts ...RouteOption) {
+	register(rt, http.MethodDelete, pattern, a, opts)
+}
@@ -0,0 +1,152 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type routeRequest struct {
+	ID string `schema:"id" json:"-"`
+}
+
+type routeResponse struct {
+	Pattern string `json:"pattern"`
+	ID      string `json:"id"`
+}
+
+// routePattern answers the pattern of route metadata.
+func routePattern(w http.ResponseWriter, r *http.Request) (routeResponse, error) {
+	req, err := GetBind[routeRequest](r)
+	if err != nil {
+		return routeResponse{}, err
+	}
+	ri, _ := RouteOf(r)
+	return routeResponse{Pattern: ri.Pattern, ID: req.ID}, nil
+}
+
+func requireToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(HeaderAuthorization.String()) == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func TestRouter(t *testing.T) {
+	rt := NewRouter(WithTags("api"))
+	Get[routeRequest, routeResponse](rt, "/items/{id}", routePattern, WithSummary("get item"))
+	rt.Route("/admin", func(r *Router) {
+		Post[routeRequest, routeResponse](r, "/items/{id}", routePattern, WithRouteTimeout(time.Second))
+	}, WithTags("admin"), WithAuth("bearer", requireToken))
+	rt.Group(func(r *Router) {
+		Delete[routeRequest, routeResponse](r, "/items/{id}", routePattern)
+	}, WithBodyLimit(64))
+	v2 := NewRouter()
+	Put[routeRequest, routeResponse](v2, "/items/{id}", routePattern)
+	rt.Mount("/v2", v2)
+	rt.Handle(http.MethodGet, "/health", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ri, _ := RouteOf(r)
+		_, _ = w.Write([]byte(ri.Pattern))
+	}))
+	serve := func(method, target string, header http.Header) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, target, nil)
+		for k, v := range header {
+			r.Header[k] = v
+		}
+		rec := httptest.NewRecorder()
+		rt.ServeHTTP(rec, r)
+		return rec
+	}
+
+	t.Run("routes", func(t *testing.T) {
+		is := assert.New(t)
+		routes := rt.Routes()
+		var listed []string
+		for _, ri := range routes {
+			listed = append(listed, ri.Method+" "+ri.Pattern)
+		}
+		is.Equal([]string{
+			"POST /admin/items/{id}",
+			"GET /health",
+			"DELETE /items/{id}",
+			"GET /items/{id}",
+			"PUT /v2/items/{id}",
+		}, listed)
+
+		admin, item := routes[0], routes[3]
+		is.Equal([]string{"api", "admin"}, admin.Tags)
+		is.Equal("bearer", admin.Auth)
+		is.Equal(time.Second, admin.Timeout)
+		is.Equal("get item", item.Summary)
+		is.Empty(item.Auth)
+		is.Equal(reflect.TypeOf(routeRequest{}), item.Request)
+		is.Equal(reflect.TypeOf(routeResponse{}), item.Response)
+		is.Equal(int64(64), routes[2].BodyLimit)
+		is.Nil(routes[1].Request)
+		is.Empty(routes[4].Tags, "the mounted router keeps its own defaults")
+	})
+
+	t.Run("serve", func(t *testing.T) {
+		is := assert.New(t)
+		for _, tc := range []struct {
+			method, target, pattern string
+			header                  http.Header
+		}{
+			{method: http.MethodGet, target: "/items/7", pattern: "/items/{id}"},
+			{method: http.MethodDelete, target: "/items/7", pattern: "/items/{id}"},
+			{method: http.MethodPost, target: "/admin/items/7", pattern: "/admin/items/{id}",
+				header: http.Header{"Authorization": {"Bearer token"}}},
+			{method: http.MethodPut, target: "/v2/items/7", pattern: "/v2/items/{id}"},
+		} {
+			rec := serve(tc.method, tc.target, tc.header)
+			is.Equal(http.StatusOK, rec.Code, tc.target)
+			is.Contains(rec.Body.String(), `"pattern":"`+tc.pattern+`"`, tc.target)
+			is.Contains(rec.Body.String(), `"id":"7"`, tc.target)
+		}
+		is.Equal(http.StatusUnauthorized, serve(http.MethodPost, "/admin/items/7", nil).Code)
+		is.Equal("/health", serve(http.MethodGet, "/health", nil).Body.String())
+		is.Equal(http.StatusMethodNotAllowed, serve(http.MethodPatch, "/items/7", nil).Code)
+	})
+}
+
+func TestRouteOptions(t *testing.T) {
+	is := assert.New(t)
+	is.Panics(func() { NewRouter().Handle(http.MethodGet, "/", http.NotFoundHandler(), WithAuth("bearer", nil)) })
+	is.Panics(func() {
+		NewRouter().Handle(http.MethodGet, "/", http.NotFoundHandler(), WithRateLimit(0, time.Second, nil))
+	})
+
+	var order []string
+	mw := func(name string) func(next http.Handler) http.Handler {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				order = append(order, name)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+	rt := NewRouter(WithRouteMiddleware(mw("default")))
+	rt.Handle(http.MethodGet, "/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, ok := RouteOf(r)
+		is.True(ok)
+	}), WithRouteMiddleware(mw("route")), WithRateLimit(1, time.Minute, nil))
+	rec := httptest.NewRecorder()
+	rt.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	is.Equal(http.StatusOK, rec.Code)
+	is.Equal([]string{"default", "route"}, order)
+	rec = httptest.NewRecorder()
+	rt.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	is.Equal(http.StatusTooManyRequests, rec.Code)
+
+	_, ok := RouteOf(httptest.NewRequest(http.MethodGet, "/", nil))
+	is.False(ok)
+	is.True(strings.HasPrefix(rec.Header().Get(HeaderContentType.String()), MIMEApplicationJSON.String()))
+}
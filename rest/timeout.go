@@ -0,0 +1,152 @@
+// Package rest is port adapter via http/s protocol
+// # This manifest was generated by ymir. DO NOT EDIT.
+package rest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	// ErrHandlerTimeout is define error when handler does not finish before the route deadline.
+	ErrHandlerTimeout = errors.New("handler timeout")
+	// ErrHandlerCanceled is define error when request context is canceled before the handler finish.
+	ErrHandlerCanceled = errors.New("handler canceled")
+)
+
+// Timeout is middleware handler to bound a route with context deadline.
+// The handler runs with a context that expires after dt, when it does not finish in time
+// the client receives 504 envelope, or 503 envelope when the request context is canceled
+// before the deadline (e.g. server is shutting down). Any write from the handler after
+// that is discarded.
+func Timeout(dt time.Duration) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), dt)
+			defer cancel()
+
+			tw := &timeoutWriter{
+				header: make(http.Header),
+				code:   http.StatusOK,
+			}
+			done := make(chan struct{})
+			panicChan := make(chan any, 1)
+			r2 := r.WithContext(ctx)
+			go func() {
+				defer func() {
+					if p := recover(); p != nil {
+						panicChan <- p
+					}
+				}()
+				next.ServeHTTP(tw, r2)
+				close(done)
+			}()
+
+			select {
+			case p := <-panicChan:
+				panic(p)
+			case <-done:
+				tw.mu.Lock()
+				defer tw.mu.Unlock()
+				dst := w.Header()
+				for k, vv := range tw.header {
+					dst[k] = vv
+				}
+				w.WriteHeader(tw.code)
+				if _, err := w.Write(tw.buf.Bytes()); err != nil {
+					log.Error().Err(err).Msg("Timeout")
+				}
+			case <-ctx.Done():
+				tw.mu.Lock()
+				defer tw.mu.Unlock()
+				tw.timedOut = true
+				timeoutEnvelope(w, r, ctx.Err())
+			}
+		})
+	}
+}
+
+// timeoutEnvelope writes the error envelope when the route deadline is reached.
+func timeoutEnvelope(w http.ResponseWriter, r *http.Request, cause error) {
+	env := EnvelopeOf(r)
+	w.Header().Set(HeaderContentType.String(), env.ContentType())
+	var err error
+	if errors.Is(cause, context.DeadlineExceeded) {
+		err = ErrGatewayTimeout(w, r, ErrHandlerTimeout)
+	} else {
+		err = ErrServiceUnavailable(w, r, ErrHandlerCanceled)
+	}
+	code, _ := r.Context().Value(CtxStatusCode).(int)
+	parts := EnvelopeParts{
+		StatusCode: code,
+		Meta: Meta{
+			Code:    strconv.Itoa(code),
+			Message: Translate(r, err),
+		},
+		Data: make(map[string]any),
+		Err:  err,
+	}
+	if ver, ok := r.Context().Value(CtxVersion).(Version); ok {
+		parts.Version = ver
+	}
+	b, err := env.Marshal(parts)
+	if err != nil {
+		log.Error().Err(err).Msg("Timeout")
+		return
+	}
+	if _, err = w.Write(append(b, '\n')); err != nil {
+		log.Error().Err(err).Msg("Timeout")
+	}
+}
+
+// timeoutWriter buffers the handler response until it is finished,
+// writes after the deadline are discarded.
+type timeoutWriter struct {
+	mu          sync.Mutex
+	header      http.Header
+	buf         bytes.Buffer
+	code        int
+	wroteHeader bool
+	timedOut    bool
+}
+
+// Header returns the buffered header map.
+func (tw *timeoutWriter) Header() http.Header { return tw.header }
+
+// Write buffers the body, it returns http.ErrHandlerTimeout after the deadline.
+func (tw *timeoutWriter) Write(p []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	if !tw.wroteHeader {
+		tw.writeHeaderLocked(http.StatusOK)
+	}
+	return tw.buf.Write(p)
+}
+
+// WriteHeader buffers the status code, it is ignored after the deadline.
+func (tw *timeoutWriter) WriteHeader(code int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut {
+		return
+	}
+	tw.writeHeaderLocked(code)
+}
+
+func (tw *timeoutWriter) writeHeaderLocked(code int) {
+	if tw.wroteHeader {
+		return
+	}
+	tw.wroteHeader = true
+	tw.code = code
+}
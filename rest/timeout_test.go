@@ -0,0 +1,63 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeout(t *testing.T) {
+	is := assert.New(t)
+	late := make(chan error, 1)
+	h := Timeout(20 * time.Millisecond)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/fast":
+			w.Header().Set("X-Handler", "fast")
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte("done"))
+		case "/slow":
+			<-r.Context().Done()
+			// wait the middleware answers before the late write.
+			time.Sleep(10 * time.Millisecond)
+			_, err := w.Write([]byte("late"))
+			late <- err
+		case "/panic":
+			panic("boom")
+		}
+	}))
+	serve := func(r *http.Request) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+		return rec
+	}
+
+	rec := serve(httptest.NewRequest(http.MethodGet, "/fast", nil))
+	is.Equal(http.StatusCreated, rec.Code)
+	is.Equal("fast", rec.Header().Get("X-Handler"))
+	is.Equal("done", rec.Body.String())
+
+	rec = serve(httptest.NewRequest(http.MethodGet, "/slow", nil))
+	is.Equal(http.StatusGatewayTimeout, rec.Code)
+	is.Contains(rec.Body.String(), ErrHandlerTimeout.Error())
+	is.ErrorIs(<-late, http.ErrHandlerTimeout)
+	is.NotContains(rec.Body.String(), "late")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r := httptest.NewRequest(http.MethodGet, "/slow", nil).WithContext(ctx)
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		cancel()
+	}()
+	rec = serve(r)
+	is.Equal(http.StatusServiceUnavailable, rec.Code)
+	is.Contains(rec.Body.String(), ErrHandlerCanceled.Error())
+	<-late
+
+	is.PanicsWithValue("boom", func() {
+		serve(httptest.NewRequest(http.MethodGet, "/panic", nil))
+	})
+}
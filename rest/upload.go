@@ -0,0 +1,295 @@
+// Package rest is port adapter via http/s protocol
+// # This manifest was generated by ymir. DO NOT EDIT.
+package rest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// sniffLen is the number of bytes used to detect the content type, see http.DetectContentType.
+	sniffLen = 512
+	// formFieldsOverhead is the body allowance of non-file fields and multipart boundaries.
+	formFieldsOverhead = 1 << 20 // 1 MB
+)
+
+var (
+	// ErrUploadTooLarge is define error when uploaded file exceeds the size limits.
+	ErrUploadTooLarge = errors.New("upload is too large")
+	// ErrUploadTypeNotAllowed is define error when content type of uploaded file is not allowed.
+	ErrUploadTypeNotAllowed = errors.New("upload content type is not allowed")
+
+	fileHeaderType   = reflect.TypeOf((*multipart.FileHeader)(nil))
+	uploadedFileType = reflect.TypeOf((*UploadedFile)(nil))
+)
+
+// UploadConfig holds the configuration of multipart file upload binding.
+type UploadConfig struct {
+	// MaxMemory is the multipart form bytes stored in memory, the rest is stored on disk.
+	MaxMemory int64
+	// MaxFileSize is the size limit of every file.
+	MaxFileSize int64
+	// MaxTotalSize is the size limit of all files in the request.
+	MaxTotalSize int64
+	// AllowedTypes is the allowlist of sniffed media types, e.g. "image/png" or "image/*".
+	// Empty allowlist permits any type.
+	AllowedTypes []string
+	// TempDir is the directory of UploadedFile, os.TempDir is used when it is empty.
+	TempDir string
+}
+
+// DefaultUploadConfig returns the upload configuration used when Upload middleware is not installed.
+func DefaultUploadConfig() UploadConfig {
+	return UploadConfig{
+		MaxMemory:    defaultMemory,
+		MaxFileSize:  10 << 20, // 10 MB
+		MaxTotalSize: defaultMemory,
+	}
+}
+
+// maxRequestSize is the body limit of multipart request.
+func (c UploadConfig) maxRequestSize() int64 {
+	return c.MaxTotalSize + formFieldsOverhead
+}
+
+// UploadedFile holds the uploaded file which is streamed into temporary directory.
+// The file is removed after the request is served.
+type UploadedFile struct {
+	Filename    string
+	Size        int64
+	ContentType string
+	Path        string
+	Header      *multipart.FileHeader
+}
+
+// Open opens the temporary file for reading.
+func (f *UploadedFile) Open() (*os.File, error) {
+	return os.Open(f.Path)
+}
+
+// Upload is middleware handler to configure multipart file upload binding of the route.
+// The request body is limited by MaxTotalSize and uploaded files are removed after the request.
+func Upload(cfg UploadConfig) func(next http.Handler) http.Handler {
+	def := DefaultUploadConfig()
+	if cfg.MaxMemory <= 0 {
+		cfg.MaxMemory = def.MaxMemory
+	}
+	if cfg.MaxFileSize <= 0 {
+		cfg.MaxFileSize = def.MaxFileSize
+	}
+	if cfg.MaxTotalSize <= 0 {
+		cfg.MaxTotalSize = def.MaxTotalSize
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.Body = http.MaxBytesReader(w, r.Body, cfg.maxRequestSize())
+			ctx := context.WithValue(r.Context(), CtxUploadConfig, cfg)
+			ctx = context.WithValue(ctx, CtxUploadFiles, &uploadRegistry{})
+			*r = *r.WithContext(ctx)
+			defer CleanupUploads(r)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// CleanupUploads removes temporary files of the request.
+func CleanupUploads(r *http.Request) {
+	if registry, ok := r.Context().Value(CtxUploadFiles).(*uploadRegistry); ok {
+		registry.removeAll()
+	}
+	if r.MultipartForm != nil {
+		if err := r.MultipartForm.RemoveAll(); err != nil {
+			log.Error().Err(err).Msg("CleanupUploads")
+		}
+	}
+}
+
+func uploadConfig(r *http.Request) UploadConfig {
+	if cfg, ok := r.Context().Value(CtxUploadConfig).(UploadConfig); ok {
+		return cfg
+	}
+	return DefaultUploadConfig()
+}
+
+// uploadRegistry keeps temporary files of a request.
+type uploadRegistry struct {
+	mu    sync.Mutex
+	paths []string
+}
+
+func (u *uploadRegistry) add(path string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.paths = append(u.paths, path)
+}
+
+func (u *uploadRegistry) removeAll() {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	for _, p := range u.paths {
+		if err := os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Error().Err(err).Str("path", p).Msg("CleanupUploads")
+		}
+	}
+	u.paths = nil
+}
+
+// bindFiles checks the limits of every uploaded file and binds fields tagged by `form`.
+func bindFiles(r *http.Request, v any, cfg UploadConfig) error {
+	var total int64
+	sniffed := make(map[*multipart.FileHeader]string)
+	for _, headers := range r.MultipartForm.File {
+		for _, fh := range headers {
+			if fh.Size > cfg.MaxFileSize {
+				return fmt.Errorf("%w: file %s exceeds %d bytes", ErrUploadTooLarge, fh.Filename, cfg.MaxFileSize)
+			}
+			total += fh.Size
+			if total > cfg.MaxTotalSize {
+				return fmt.Errorf("%w: files exceed %d bytes", ErrUploadTooLarge, cfg.MaxTotalSize)
+			}
+			contentType, err := sniff(fh)
+			if err != nil {
+				return err
+			}
+			if !allowedType(contentType, cfg.AllowedTypes) {
+				return fmt.Errorf("%w: file %s is %s", ErrUploadTypeNotAllowed, fh.Filename, contentType)
+			}
+			sniffed[fh] = contentType
+		}
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	rv = rv.Elem()
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		name := strings.SplitN(field.Tag.Get("form"), ",", 2)[0]
+		headers := r.MultipartForm.File[name]
+		if !field.IsExported() || name == "" || name == "-" || len(headers) < 1 {
+			continue
+		}
+		fv := rv.Field(i)
+		switch {
+		case field.Type == fileHeaderType:
+			fv.Set(reflect.ValueOf(headers[0]))
+		case field.Type == reflect.SliceOf(fileHeaderType):
+			fv.Set(reflect.ValueOf(headers))
+		case field.Type == uploadedFileType:
+			f, err := storeUpload(r, headers[0], sniffed[headers[0]], cfg)
+			if err != nil {
+				return err
+			}
+			fv.Set(reflect.ValueOf(f))
+		case field.Type == reflect.SliceOf(uploadedFileType):
+			files := make([]*UploadedFile, 0, len(headers))
+			for _, fh := range headers {
+				f, err := storeUpload(r, fh, sniffed[fh], cfg)
+				if err != nil {
+					return err
+				}
+				files = append(files, f)
+			}
+			fv.Set(reflect.ValueOf(files))
+		}
+	}
+	return nil
+}
+
+// sniff detects the real content type of file from its magic bytes.
+func sniff(fh *multipart.FileHeader) (string, error) {
+	f, err := fh.Open()
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	buf := make([]byte, sniffLen)
+	n, err := io.ReadFull(f, buf)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	mediaType, _, err := mime.ParseMediaType(http.DetectContentType(buf[:n]))
+	if err != nil {
+		return MIMEOctetStream.String(), nil
+	}
+	return mediaType, nil
+}
+
+func allowedType(contentType string, allowed []string) bool {
+	if len(allowed) < 1 {
+		return true
+	}
+	for _, a := range allowed {
+		if strings.EqualFold(a, contentType) {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(a, "/*"); ok &&
+			strings.HasPrefix(strings.ToLower(contentType), strings.ToLower(prefix)+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// storeUpload streams the uploaded file into temporary directory and registers it for cleanup.
+// The part spooled on disk by ParseMultipartForm is reused when TempDir is not set, it is removed
+// with the multipart form.
+func storeUpload(r *http.Request, fh *multipart.FileHeader, contentType string, cfg UploadConfig) (*UploadedFile, error) {
+	src, err := fh.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = src.Close()
+	}()
+	if spooled, ok := src.(*os.File); ok && cfg.TempDir == "" {
+		return &UploadedFile{
+			Filename:    filepath.Base(fh.Filename),
+			Size:        fh.Size,
+			ContentType: contentType,
+			Path:        spooled.Name(),
+			Header:      fh,
+		}, nil
+	}
+	dst, err := os.CreateTemp(cfg.TempDir, "upload-*"+filepath.Ext(filepath.Base(fh.Filename)))
+	if err != nil {
+		return nil, err
+	}
+	registry, ok := r.Context().Value(CtxUploadFiles).(*uploadRegistry)
+	if !ok {
+		registry = &uploadRegistry{}
+		*r = *r.WithContext(context.WithValue(r.Context(), CtxUploadFiles, registry))
+	}
+	registry.add(dst.Name())
+	size, err := io.Copy(dst, src)
+	if cerr := dst.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &UploadedFile{
+		Filename:    filepath.Base(fh.Filename),
+		Size:        size,
+		ContentType: contentType,
+		Path:        dst.Name(),
+		Header:      fh,
+	}, nil
+}
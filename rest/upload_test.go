@@ -0,0 +1,164 @@
+package rest
+
+import (
+	"bytes"
+	"context"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+type uploadRequest struct {
+	Avatar *UploadedFile   `form:"avatar"`
+	Files  []*UploadedFile `form:"files"`
+}
+
+// multipartRequest builds the request of files keyed by form field.
+func multipartRequest(t *testing.T, files map[string][]byte) *http.Request {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for name, content := range files {
+		field, filename, _ := strings.Cut(name, ":")
+		fw, err := mw.CreateFormFile(field, filename)
+		assert.NoError(t, err)
+		_, err = fw.Write(content)
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, mw.Close())
+	r := httptest.NewRequest(http.MethodPost, "/", &body)
+	r.Header.Set(HeaderContentType.String(), mw.FormDataContentType())
+	return r
+}
+
+func TestUpload(t *testing.T) {
+	var (
+		bound   uploadRequest
+		existed []bool
+	)
+	h := HandlerAdapter[uploadRequest](func(w http.ResponseWriter, r *http.Request) (any, error) {
+		b, err := GetBind[uploadRequest](r)
+		if err != nil {
+			return nil, err
+		}
+		bound, existed = b, nil
+		for _, f := range append([]*UploadedFile{b.Avatar}, b.Files...) {
+			if f == nil {
+				continue
+			}
+			_, err := os.Stat(f.Path)
+			existed = append(existed, err == nil)
+		}
+		return nil, nil
+	})
+	serve := func(cfg UploadConfig, files map[string][]byte) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		Upload(cfg)(http.HandlerFunc(h.JSON)).ServeHTTP(rec, multipartRequest(t, files))
+		return rec
+	}
+
+	t.Run("temp storage", func(t *testing.T) {
+		is := assert.New(t)
+		dir := t.TempDir()
+		rec := serve(UploadConfig{TempDir: dir}, map[string][]byte{
+			"avatar:me.png": pngHeader,
+			"files:a.txt":   []byte("hello"),
+		})
+		is.Equal(http.StatusOK, rec.Code)
+		is.Equal("me.png", bound.Avatar.Filename)
+		is.Equal("image/png", bound.Avatar.ContentType)
+		is.Equal(int64(len(pngHeader)), bound.Avatar.Size)
+		is.Equal(dir, filepath.Dir(bound.Avatar.Path))
+		is.Equal(".png", filepath.Ext(bound.Avatar.Path))
+		is.Len(bound.Files, 1)
+		is.Equal("text/plain", bound.Files[0].ContentType)
+		is.Equal([]bool{true, true}, existed)
+		entries, err := os.ReadDir(dir)
+		is.NoError(err)
+		is.Empty(entries, "the files are removed after the request")
+	})
+
+	t.Run("spooled part is reused", func(t *testing.T) {
+		is := assert.New(t)
+		rec := serve(UploadConfig{MaxMemory: 1}, map[string][]byte{
+			"avatar:big.txt": bytes.Repeat([]byte("a"), 4096),
+		})
+		is.Equal(http.StatusOK, rec.Code)
+		is.True(strings.HasPrefix(filepath.Base(bound.Avatar.Path), "multipart-"))
+		is.Equal(int64(4096), bound.Avatar.Size)
+		is.Equal([]bool{true}, existed)
+		_, err := os.Stat(bound.Avatar.Path)
+		is.True(os.IsNotExist(err))
+	})
+
+	t.Run("size limits", func(t *testing.T) {
+		is := assert.New(t)
+		rec := serve(UploadConfig{MaxFileSize: 10}, map[string][]byte{
+			"avatar:big.txt": bytes.Repeat([]byte("a"), 11),
+		})
+		is.Equal(http.StatusRequestEntityTooLarge, rec.Code)
+		rec = serve(UploadConfig{MaxFileSize: 10, MaxTotalSize: 15}, map[string][]byte{
+			"files:a.txt": bytes.Repeat([]byte("a"), 8),
+			"files:b.txt": bytes.Repeat([]byte("b"), 8),
+		})
+		is.Equal(http.StatusRequestEntityTooLarge, rec.Code)
+		rec = serve(UploadConfig{MaxTotalSize: 10}, map[string][]byte{
+			"avatar:huge.txt": bytes.Repeat([]byte("a"), 2*formFieldsOverhead),
+		})
+		is.Equal(http.StatusRequestEntityTooLarge, rec.Code)
+	})
+
+	t.Run("sniffed types", func(t *testing.T) {
+		is := assert.New(t)
+		cfg := UploadConfig{AllowedTypes: []string{"image/*"}}
+		is.Equal(http.StatusOK, serve(cfg, map[string][]byte{"avatar:me.png": pngHeader}).Code)
+		// the extension is not trusted, the content is sniffed.
+		rec := serve(cfg, map[string][]byte{"avatar:fake.png": []byte("plain text")})
+		is.Equal(http.StatusUnsupportedMediaType, rec.Code)
+		cfg.AllowedTypes = []string{"TEXT/PLAIN"}
+		is.Equal(http.StatusOK, serve(cfg, map[string][]byte{"avatar:a.txt": []byte("plain text")}).Code)
+	})
+}
+
+func TestUploadWithoutMiddleware(t *testing.T) {
+	is := assert.New(t)
+	h := HandlerAdapter[uploadRequest](func(w http.ResponseWriter, r *http.Request) (any, error) {
+		_, err := GetBind[uploadRequest](r)
+		return nil, err
+	})
+	serve := func(size int64) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.JSON(rec, multipartRequest(t, map[string][]byte{
+			"avatar:big.txt": bytes.Repeat([]byte("a"), int(size)),
+		}))
+		return rec
+	}
+	is.Equal(http.StatusOK, serve(1024).Code)
+	// the body is limited before it is spooled.
+	rec := serve(DefaultUploadConfig().maxRequestSize())
+	is.Equal(http.StatusRequestEntityTooLarge, rec.Code)
+	is.Contains(rec.Body.String(), ErrUploadTooLarge.Error())
+}
+
+func TestCleanupUploads(t *testing.T) {
+	is := assert.New(t)
+	path := filepath.Join(t.TempDir(), "upload.txt")
+	is.NoError(os.WriteFile(path, []byte("a"), 0o600))
+	registry := &uploadRegistry{}
+	registry.add(path)
+	registry.add(filepath.Join(t.TempDir(), "missing.txt"))
+	r := httptest.NewRequest(http.MethodPost, "/", nil)
+	*r = *r.WithContext(context.WithValue(r.Context(), CtxUploadFiles, registry))
+	CleanupUploads(r)
+	_, err := os.Stat(path)
+	is.True(os.IsNotExist(err))
+	is.Empty(registry.paths)
+	CleanupUploads(httptest.NewRequest(http.MethodGet, "/", nil))
+}
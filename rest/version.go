@@ -0,0 +1,255 @@
+// Package rest is port adapter via http/s protocol
+// # This manifest was generated by ymir. DO NOT EDIT.
+package rest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const (
+	serverMeterName = "rest.server"
+	// versionParam is the media type parameter of Accept header, e.g. application/vnd.api+json;version=2.
+	versionParam = "version"
+	// vendorPrefix is the media type prefix which carries versionParam.
+	vendorPrefix = "application/vnd."
+)
+
+var (
+	// ErrVersionNotSupported is define error when the requested api version is unknown.
+	ErrVersionNotSupported = errors.New("api version is not supported")
+	// ErrVersionRemoved is define error when the requested api version has been removed.
+	ErrVersionRemoved = errors.New("api version has been removed")
+)
+
+// RequestVersion request data of versioning error.
+type RequestVersion struct{}
+
+// ResponseVersion response data of versioning error.
+type ResponseVersion struct{}
+
+// VersionPolicy holds the lifecycle of an api version.
+type VersionPolicy struct {
+	// Label is the version name on path prefix and envelope, e.g. "v1".
+	Label string
+	// Number is the semantic version on envelope, e.g. "1.4.0".
+	Number string
+	// Deprecated is the deprecation date sent as Deprecation header, zero means not deprecated.
+	Deprecated time.Time
+	// Sunset is the retirement date sent as Sunset header.
+	Sunset time.Time
+	// Removed answers 410 Gone to every request of the version.
+	Removed bool
+	// Link is the migration guide sent as Link header of deprecated version.
+	Link string
+}
+
+// VersionConfig holds the configuration of api version resolution.
+// The version is resolved from path prefix, then Accept vendor media type, then Header.
+type VersionConfig struct {
+	// Versions is the list of known versions.
+	Versions []VersionPolicy
+	// Default is the version label used when the request does not ask for one.
+	Default string
+	// Header is the custom header of requested version, X-API-Version is used when it is empty.
+	Header string
+	// StripPrefix removes the version path prefix, so every version shares the same routes.
+	StripPrefix bool
+}
+
+// Versioning is middleware handler to resolve the api version of the request.
+// The resolved version is stored on the request context, see VersionHandler to route it.
+func Versioning(cfg VersionConfig) func(next http.Handler) http.Handler {
+	if cfg.Header == "" {
+		cfg.Header = HeaderAPIVersion.String()
+	}
+	policies := make(map[string]VersionPolicy, len(cfg.Versions))
+	for _, p := range cfg.Versions {
+		if p.Label == "" {
+			panic("versioning: version label is required")
+		}
+		policies[strings.ToLower(p.Label)] = p
+	}
+	def, ok := policies[strings.ToLower(cfg.Default)]
+	if !ok {
+		panic(fmt.Sprintf("versioning: default version %q is not in versions", cfg.Default))
+	}
+	usage := &versionUsage{}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			policy, prefix, requested := resolveVersion(r, cfg.Header, policies)
+			if policy == nil && requested != "" {
+				versionError(w, r, ErrNotAcceptable, fmt.Errorf("%w: %s", ErrVersionNotSupported, requested))
+				return
+			}
+			if policy == nil {
+				policy = &def
+			}
+			usage.add(r.Context(), policy)
+
+			w.Header().Set(cfg.Header, policy.Label)
+			if !policy.Deprecated.IsZero() || policy.Removed {
+				deprecated := policy.Deprecated
+				if deprecated.IsZero() {
+					deprecated = policy.Sunset
+				}
+				if !deprecated.IsZero() {
+					w.Header().Set(HeaderDeprecation.String(), "@"+strconv.FormatInt(deprecated.Unix(), 10))
+				}
+				if policy.Link != "" {
+					w.Header().Add(HeaderLink.String(), fmt.Sprintf(`<%s>; rel="deprecation"`, policy.Link))
+				}
+			}
+			if !policy.Sunset.IsZero() {
+				w.Header().Set(HeaderSunset.String(), policy.Sunset.UTC().Format(http.TimeFormat))
+			}
+
+			*r = *r.WithContext(context.WithValue(r.Context(), CtxVersion, Version{
+				Label:  policy.Label,
+				Number: policy.Number,
+			}))
+			if policy.Removed {
+				versionError(w, r, ErrGone, fmt.Errorf("%w: %s", ErrVersionRemoved, policy.Label))
+				return
+			}
+			if cfg.StripPrefix && prefix != "" {
+				stripVersionPrefix(r, prefix)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// VersionHandler routes the request to handler of the resolved version label,
+// the handler of empty label is the fallback.
+func VersionHandler(handlers map[string]http.Handler) http.Handler {
+	routes := make(map[string]http.Handler, len(handlers))
+	for label, h := range handlers {
+		routes[strings.ToLower(label)] = h
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var label string
+		if ver, ok := r.Context().Value(CtxVersion).(Version); ok {
+			label = strings.ToLower(ver.Label)
+		}
+		if h, ok := routes[label]; ok {
+			h.ServeHTTP(w, r)
+			return
+		}
+		if h, ok := routes[""]; ok {
+			h.ServeHTTP(w, r)
+			return
+		}
+		NotFoundDefault()(w, r)
+	})
+}
+
+// resolveVersion returns the policy and path prefix of the requested version.
+// The requested value is returned when it is unknown.
+func resolveVersion(r *http.Request, header string, policies map[string]VersionPolicy) (*VersionPolicy, string, string) {
+	segment := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 2)[0]
+	if p, ok := policies[strings.ToLower(segment)]; ok && segment != "" {
+		return &p, "/" + segment, ""
+	}
+	if requested := acceptVersion(r.Header.Get(HeaderAccept.String())); requested != "" {
+		return lookupVersion(requested, policies), "", requested
+	}
+	if requested := strings.TrimSpace(r.Header.Get(header)); requested != "" {
+		return lookupVersion(requested, policies), "", requested
+	}
+	return nil, "", ""
+}
+
+// acceptVersion returns version parameter of the vendor media type in Accept header.
+func acceptVersion(accept string) string {
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil || !strings.HasPrefix(mediaType, vendorPrefix) {
+			continue
+		}
+		if v := strings.TrimSpace(params[versionParam]); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// lookupVersion matches the requested value against label, e.g. "v2" or "2", and semantic version number.
+func lookupVersion(requested string, policies map[string]VersionPolicy) *VersionPolicy {
+	requested = strings.ToLower(requested)
+	if p, ok := policies[requested]; ok {
+		return &p
+	}
+	if p, ok := policies["v"+requested]; ok {
+		return &p
+	}
+	for _, p := range policies {
+		if p.Number != "" && strings.EqualFold(p.Number, requested) {
+			return &p
+		}
+	}
+	return nil
+}
+
+// stripVersionPrefix removes the version prefix from request path and chi routing path.
+func stripVersionPrefix(r *http.Request, prefix string) {
+	path := strings.TrimPrefix(r.URL.Path, prefix)
+	if path == "" {
+		path = "/"
+	}
+	r.URL.Path = path
+	if r.URL.RawPath != "" {
+		r.URL.RawPath = strings.TrimPrefix(r.URL.RawPath, prefix)
+	}
+	if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePath != "" {
+		rctx.RoutePath = strings.TrimPrefix(rctx.RoutePath, prefix)
+	}
+}
+
+// versionError writes the error envelope of versioning.
+func versionError(w http.ResponseWriter, r *http.Request, status func(http.ResponseWriter, *http.Request, error) error, err error) {
+	response := HandlerAdapter[RequestVersion, ResponseVersion](nil)
+	if ver, ok := r.Context().Value(CtxVersion).(Version); ok {
+		response.Version = ver
+	}
+	response.writeError(w, r, status(w, r, err))
+}
+
+// versionUsage counts requests per api version, so unused versions can be deleted safely.
+type versionUsage struct {
+	once     sync.Once
+	requests metric.Int64Counter
+}
+
+func (u *versionUsage) add(ctx context.Context, p *VersionPolicy) {
+	u.once.Do(func() {
+		var err error
+		u.requests, err = otel.Meter(serverMeterName).Int64Counter("http.server.api_version.requests",
+			metric.WithDescription("Number of requests per api version"))
+		if err != nil {
+			log.Error().Err(err).Msg("Versioning")
+		}
+	})
+	if u.requests == nil {
+		return
+	}
+	u.requests.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("api.version", p.Label),
+		attribute.String("api.version.number", p.Number),
+		attribute.Bool("api.version.deprecated", !p.Deprecated.IsZero() || p.Removed),
+	))
+}
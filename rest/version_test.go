@@ -0,0 +1,114 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersioning(t *testing.T) {
+	deprecated := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	sunset := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	cfg := VersionConfig{
+		Versions: []VersionPolicy{
+			{Label: "v1", Number: "1.4.0", Deprecated: deprecated, Sunset: sunset, Link: "https://docs/migrate"},
+			{Label: "v2", Number: "2.0.0"},
+			{Label: "v0", Number: "0.9.0", Sunset: deprecated, Removed: true},
+		},
+		Default:     "v2",
+		StripPrefix: true,
+	}
+	router := chi.NewRouter()
+	router.Use(Versioning(cfg))
+	router.Get("/items", VersionHandler(map[string]http.Handler{
+		"v1": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("v1 " + r.URL.Path))
+		}),
+		"": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ver, _ := r.Context().Value(CtxVersion).(Version)
+			_, _ = w.Write([]byte(ver.Label + " " + ver.Number))
+		}),
+	}).ServeHTTP)
+	serve := func(path string, header http.Header) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		for k, v := range header {
+			r.Header[k] = v
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, r)
+		return rec
+	}
+	vnd := func(v string) string {
+		return "application/vnd.asgard+json;version=" + v
+	}
+
+	t.Run("precedence", func(t *testing.T) {
+		is := assert.New(t)
+		for _, tc := range []struct {
+			name   string
+			path   string
+			header http.Header
+			body   string
+		}{
+			{name: "default", path: "/items", body: "v2 2.0.0"},
+			{name: "path prefix", path: "/v1/items", body: "v1 /items"},
+			{name: "path before accept", path: "/v1/items",
+				header: http.Header{"Accept": {vnd("2")}}, body: "v1 /items"},
+			{name: "accept vendor", path: "/items",
+				header: http.Header{"Accept": {"text/html, " + vnd("1")}}, body: "v1 /items"},
+			{name: "accept before header", path: "/items",
+				header: http.Header{"Accept": {vnd("v2")}, "X-Api-Version": {"v1"}}, body: "v2 2.0.0"},
+			{name: "header", path: "/items",
+				header: http.Header{"X-Api-Version": {"1"}}, body: "v1 /items"},
+			{name: "header by number", path: "/items",
+				header: http.Header{"X-Api-Version": {"2.0.0"}}, body: "v2 2.0.0"},
+			{name: "accept without vendor is ignored", path: "/items",
+				header: http.Header{"Accept": {"application/json;version=1"}}, body: "v2 2.0.0"},
+		} {
+			rec := serve(tc.path, tc.header)
+			is.Equal(http.StatusOK, rec.Code, tc.name)
+			is.Equal(tc.body, rec.Body.String(), tc.name)
+		}
+	})
+
+	t.Run("deprecation headers", func(t *testing.T) {
+		is := assert.New(t)
+		rec := serve("/v1/items", nil)
+		is.Equal("v1", rec.Header().Get(HeaderAPIVersion.String()))
+		is.Equal("@"+strconv.FormatInt(deprecated.Unix(), 10), rec.Header().Get(HeaderDeprecation.String()))
+		is.Equal("Mon, 01 Jun 2026 00:00:00 GMT", rec.Header().Get(HeaderSunset.String()))
+		is.Equal(`<https://docs/migrate>; rel="deprecation"`, rec.Header().Get(HeaderLink.String()))
+
+		rec = serve("/items", nil)
+		is.Equal("v2", rec.Header().Get(HeaderAPIVersion.String()))
+		is.Empty(rec.Header().Get(HeaderDeprecation.String()))
+		is.Empty(rec.Header().Get(HeaderSunset.String()))
+		is.Empty(rec.Header().Get(HeaderLink.String()))
+	})
+
+	t.Run("removed and unknown versions", func(t *testing.T) {
+		is := assert.New(t)
+		rec := serve("/v0/items", nil)
+		is.Equal(http.StatusGone, rec.Code)
+		is.Contains(rec.Body.String(), ErrVersionRemoved.Error())
+		// the removed version without deprecation date is deprecated since its sunset.
+		is.Equal("@"+strconv.FormatInt(deprecated.Unix(), 10), rec.Header().Get(HeaderDeprecation.String()))
+		is.Equal(http.StatusGone, serve("/items", http.Header{"X-Api-Version": {"0.9.0"}}).Code)
+
+		rec = serve("/items", http.Header{"X-Api-Version": {"v9"}})
+		is.Equal(http.StatusNotAcceptable, rec.Code)
+		is.Contains(rec.Body.String(), ErrVersionNotSupported.Error())
+		is.Equal(http.StatusNotAcceptable, serve("/items", http.Header{"Accept": {vnd("3")}}).Code)
+	})
+}
+
+func TestVersioningConfig(t *testing.T) {
+	is := assert.New(t)
+	is.Panics(func() { Versioning(VersionConfig{Versions: []VersionPolicy{{Label: ""}}}) })
+	is.Panics(func() { Versioning(VersionConfig{Versions: []VersionPolicy{{Label: "v1"}}, Default: "v2"}) })
+}
@@ -0,0 +1,376 @@
+// Package rest is port adapter via http/s protocol
+// # This manifest was generated by ymir. DO NOT EDIT.
+package rest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/rs/zerolog/log"
+	"github.com/vmihailenco/msgpack/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semConv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	otelTrace "go.opentelemetry.io/otel/trace"
+)
+
+const (
+	webSocketTracerName = "otelWebSocket"
+)
+
+var (
+	// ErrSendBufferFull is define error when the peer does not read fast enough to drain the send buffer.
+	ErrSendBufferFull = errors.New("websocket send buffer is full")
+	// ErrConnClosed is define error when sending to closed connection.
+	ErrConnClosed = errors.New("websocket connection is closed")
+)
+
+// MessageCodec encodes and decodes websocket messages.
+type MessageCodec interface {
+	// MessageType returns websocket.TextMessage or websocket.BinaryMessage.
+	MessageType() int
+	Marshal(v any) ([]byte, error)
+	Unmarshal(b []byte, v any) error
+}
+
+// JSONCodec is the message codec of JSON text messages.
+type JSONCodec struct{}
+
+// MessageType returns websocket.TextMessage.
+func (JSONCodec) MessageType() int { return websocket.TextMessage }
+
+// Marshal encodes v as JSON.
+func (JSONCodec) Marshal(v any) ([]byte, error) { return json.Marshal(v) }
+
+// Unmarshal decodes JSON into v.
+func (JSONCodec) Unmarshal(b []byte, v any) error { return json.Unmarshal(b, v) }
+
+// MsgpackCodec is the message codec of msgpack binary messages, fields are matched by `json` tags.
+type MsgpackCodec struct{}
+
+// MessageType returns websocket.BinaryMessage.
+func (MsgpackCodec) MessageType() int { return websocket.BinaryMessage }
+
+// Marshal encodes v as msgpack.
+func (MsgpackCodec) Marshal(v any) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	enc := msgpack.NewEncoder(buf)
+	enc.SetCustomStructTag("json")
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Unmarshal decodes msgpack into v.
+func (MsgpackCodec) Unmarshal(b []byte, v any) error { return DecodeMsgpack(bytes.NewReader(b), v) }
+
+// WebSocketAdapter is func type to handle every inbound message of the connection.
+// Returning error closes the connection.
+type WebSocketAdapter[In any, Out any] func(c *Conn[In, Out], msg In) error
+
+// WebSocketHandler is http handler to upgrade the request into websocket connection.
+type WebSocketHandler[In any, Out any] struct {
+	cfg       WebSocketConfig
+	upgrader  websocket.Upgrader
+	next      WebSocketAdapter[In, Out]
+	onConnect func(c *Conn[In, Out]) error
+	onClose   func(c *Conn[In, Out])
+
+	mu         sync.Mutex
+	conns      map[*Conn[In, Out]]struct{}
+	registered sync.Map // *http.Server already has the shutdown hook.
+}
+
+// WebSocket creates a websocket handler of typed inbound and outbound messages.
+// Connections are closed with going away status when the http server is shutdown.
+func WebSocket[In any, Out any](a WebSocketAdapter[In, Out], opts ...WebSocketOption) *WebSocketHandler[In, Out] {
+	cfg := WebSocketConfig{
+		codec:        JSONCodec{},
+		sendBuffer:   16,
+		pingInterval: 30 * time.Second,
+		pongWait:     60 * time.Second,
+		writeWait:    10 * time.Second,
+		readLimit:    1 << 20, // 1 MB
+	}
+	for _, opt := range opts {
+		if err := opt(&cfg); err != nil {
+			panic(err)
+		}
+	}
+	return &WebSocketHandler[In, Out]{
+		cfg: cfg,
+		upgrader: websocket.Upgrader{
+			HandshakeTimeout: cfg.writeWait,
+			CheckOrigin:      cfg.checkOrigin,
+			Subprotocols:     cfg.subprotocols,
+		},
+		next:  a,
+		conns: make(map[*Conn[In, Out]]struct{}),
+	}
+}
+
+// OnConnect sets the func called after the upgrade, returning error closes the connection.
+func (h *WebSocketHandler[In, Out]) OnConnect(fn func(c *Conn[In, Out]) error) *WebSocketHandler[In, Out] {
+	h.onConnect = fn
+	return h
+}
+
+// OnClose sets the func called after the connection is closed.
+func (h *WebSocketHandler[In, Out]) OnClose(fn func(c *Conn[In, Out])) *WebSocketHandler[In, Out] {
+	h.onClose = fn
+	return h
+}
+
+// CloseAll closes every open connection with the status code.
+func (h *WebSocketHandler[In, Out]) CloseAll(code int, text string) {
+	h.mu.Lock()
+	conns := make([]*Conn[In, Out], 0, len(h.conns))
+	for c := range h.conns {
+		conns = append(conns, c)
+	}
+	h.mu.Unlock()
+	for _, c := range conns {
+		c.Close(code, text)
+	}
+}
+
+func (h *WebSocketHandler[In, Out]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ws, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade has replied to the client already.
+		log.Error().Err(err).Msg("WebSocket")
+		return
+	}
+	if srv, ok := r.Context().Value(http.ServerContextKey).(*http.Server); ok {
+		if _, loaded := h.registered.LoadOrStore(srv, struct{}{}); !loaded {
+			srv.RegisterOnShutdown(func() {
+				h.CloseAll(websocket.CloseGoingAway, "server is shutting down")
+			})
+		}
+	}
+
+	ctx, span := otel.Tracer(webSocketTracerName).Start(r.Context(), "WS "+r.URL.Path,
+		otelTrace.WithSpanKind(otelTrace.SpanKindServer),
+		otelTrace.WithAttributes(
+			semConv.HTTPTarget(r.URL.Path),
+			attribute.String("websocket.subprotocol", ws.Subprotocol()),
+		),
+	)
+	ctx, cancel := context.WithCancel(ctx)
+	c := &Conn[In, Out]{
+		ws:        ws,
+		request:   r.WithContext(ctx),
+		ctx:       ctx,
+		cancel:    cancel,
+		span:      span,
+		codec:     h.cfg.codec,
+		send:      make(chan []byte, h.cfg.sendBuffer),
+		writeWait: h.cfg.writeWait,
+		closeCode: websocket.CloseNormalClosure,
+	}
+	h.mu.Lock()
+	h.conns[c] = struct{}{}
+	h.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		c.writePump(h.cfg.pingInterval)
+	}()
+	if h.onConnect != nil {
+		if err = h.onConnect(c); err != nil {
+			c.fail(err)
+		}
+	}
+	h.readPump(c)
+	c.Close(websocket.CloseNormalClosure, "")
+	<-done
+
+	h.mu.Lock()
+	delete(h.conns, c)
+	h.mu.Unlock()
+	if h.onClose != nil {
+		h.onClose(c)
+	}
+	span.SetAttributes(attribute.Int("websocket.close_code", c.closeCode))
+	span.End()
+}
+
+func (h *WebSocketHandler[In, Out]) readPump(c *Conn[In, Out]) {
+	defer func() {
+		// the hijacked connection is not closed by net/http, so the panic is answered here.
+		if rec := recover(); rec != nil {
+			log.Error().Interface("panic", rec).Str("path", c.request.URL.Path).Msg("WebSocket")
+			err := fmt.Errorf("websocket: handler panic: %v", rec)
+			c.span.RecordError(err)
+			c.span.SetStatus(codes.Error, err.Error())
+			c.Close(websocket.CloseInternalServerErr, "internal error")
+		}
+	}()
+	c.ws.SetReadLimit(h.cfg.readLimit)
+	_ = c.ws.SetReadDeadline(time.Now().Add(h.cfg.pongWait))
+	c.ws.SetPongHandler(func(string) error {
+		return c.ws.SetReadDeadline(time.Now().Add(h.cfg.pongWait))
+	})
+	for {
+		_, b, err := c.ws.ReadMessage()
+		if err != nil {
+			var closeErr *websocket.CloseError
+			if errors.As(err, &closeErr) {
+				c.closeWith(closeErr.Code, closeErr.Text)
+			} else if c.ctx.Err() == nil {
+				c.span.RecordError(err)
+			}
+			return
+		}
+		c.span.AddEvent("message.received", otelTrace.WithAttributes(attribute.Int("message.size", len(b))))
+		var msg In
+		if err = c.codec.Unmarshal(b, &msg); err != nil {
+			c.span.RecordError(err)
+			c.Close(websocket.CloseUnsupportedData, "message can not be decoded")
+			return
+		}
+		if err = h.next(c, msg); err != nil {
+			c.fail(err)
+			return
+		}
+	}
+}
+
+// Conn is the websocket connection of typed inbound and outbound messages.
+type Conn[In any, Out any] struct {
+	ws        *websocket.Conn
+	request   *http.Request
+	ctx       context.Context
+	cancel    context.CancelFunc
+	span      otelTrace.Span
+	codec     MessageCodec
+	send      chan []byte
+	writeWait time.Duration
+
+	closeOnce sync.Once
+	closeCode int
+	closeText string
+}
+
+// Context returns the connection context, it is canceled once the connection is closing.
+func (c *Conn[In, Out]) Context() context.Context {
+	return c.ctx
+}
+
+// Request returns the upgraded http request.
+func (c *Conn[In, Out]) Request() *http.Request {
+	return c.request
+}
+
+// Send queues the outbound message. It waits up to the write wait when the send buffer is full,
+// then the connection is closed as slow consumer and ErrSendBufferFull is returned.
+func (c *Conn[In, Out]) Send(msg Out) error {
+	b, err := c.codec.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	if c.ctx.Err() != nil {
+		return ErrConnClosed
+	}
+	select {
+	case c.send <- b:
+		return nil
+	default:
+	}
+	timer := time.NewTimer(c.writeWait)
+	defer timer.Stop()
+	select {
+	case c.send <- b:
+		return nil
+	case <-c.ctx.Done():
+		return ErrConnClosed
+	case <-timer.C:
+		c.span.AddEvent("message.dropped", otelTrace.WithAttributes(attribute.Int("message.size", len(b))))
+		c.Close(websocket.CloseTryAgainLater, ErrSendBufferFull.Error())
+		return ErrSendBufferFull
+	}
+}
+
+// Close closes the connection with the status code, queued messages are flushed first.
+func (c *Conn[In, Out]) Close(code int, text string) {
+	c.closeWith(code, text)
+	c.cancel()
+}
+
+func (c *Conn[In, Out]) closeWith(code int, text string) {
+	c.closeOnce.Do(func() {
+		c.closeCode = code
+		c.closeText = text
+	})
+}
+
+// fail records the error and closes the connection as internal error.
+func (c *Conn[In, Out]) fail(err error) {
+	c.span.RecordError(err)
+	c.span.SetStatus(codes.Error, err.Error())
+	c.Close(websocket.CloseInternalServerErr, err.Error())
+}
+
+// writePump is the only writer of the connection, it sends queued messages and pings.
+func (c *Conn[In, Out]) writePump(pingInterval time.Duration) {
+	ticker := time.NewTicker(pingInterval)
+	defer func() {
+		ticker.Stop()
+		_ = c.ws.Close()
+	}()
+	for {
+		select {
+		case b := <-c.send:
+			if err := c.write(b); err != nil {
+				c.Close(websocket.CloseAbnormalClosure, "")
+				return
+			}
+		case <-ticker.C:
+			if err := c.ws.WriteControl(websocket.PingMessage, nil, time.Now().Add(c.writeWait)); err != nil {
+				c.Close(websocket.CloseAbnormalClosure, "")
+				return
+			}
+		case <-c.ctx.Done():
+			c.flush()
+			if c.closeCode != websocket.CloseAbnormalClosure {
+				msg := websocket.FormatCloseMessage(c.closeCode, c.closeText)
+				_ = c.ws.WriteControl(websocket.CloseMessage, msg, time.Now().Add(c.writeWait))
+			}
+			return
+		}
+	}
+}
+
+func (c *Conn[In, Out]) write(b []byte) error {
+	_ = c.ws.SetWriteDeadline(time.Now().Add(c.writeWait))
+	if err := c.ws.WriteMessage(c.codec.MessageType(), b); err != nil {
+		c.span.RecordError(err)
+		return err
+	}
+	c.span.AddEvent("message.sent", otelTrace.WithAttributes(attribute.Int("message.size", len(b))))
+	return nil
+}
+
+// flush writes the queued messages before closing.
+func (c *Conn[In, Out]) flush() {
+	for {
+		select {
+		case b := <-c.send:
+			if err := c.write(b); err != nil {
+				return
+			}
+		default:
+			return
+		}
+	}
+}
@@ -0,0 +1,90 @@
+// Package rest is port adapter via http/s protocol
+// # This manifest was generated by ymir. DO NOT EDIT.
+package rest
+
+import (
+	"errors"
+	"net/http"
+	"time"
+)
+
+// WebSocketOption is websocket handler type return func.
+type WebSocketOption = func(c *WebSocketConfig) error
+
+// WebSocketConfig holds the configuration of websocket handler.
+type WebSocketConfig struct {
+	codec        MessageCodec
+	sendBuffer   int
+	pingInterval time.Duration
+	pongWait     time.Duration
+	writeWait    time.Duration
+	readLimit    int64
+	checkOrigin  func(r *http.Request) bool
+	subprotocols []string
+}
+
+// WithCodec will assign the message codec, JSONCodec is used by default.
+func WithCodec(codec MessageCodec) WebSocketOption {
+	return func(c *WebSocketConfig) error {
+		if codec == nil {
+			return errors.New("websocket: codec is required")
+		}
+		c.codec = codec
+		return nil
+	}
+}
+
+// WithSendBuffer will assign the outbound message buffer of every connection.
+func WithSendBuffer(size int) WebSocketOption {
+	return func(c *WebSocketConfig) error {
+		if size < 1 {
+			return errors.New("websocket: send buffer must be at least 1")
+		}
+		c.sendBuffer = size
+		return nil
+	}
+}
+
+// WithKeepalive will assign the ping interval and the pong wait, pongWait must be greater than pingInterval.
+func WithKeepalive(pingInterval, pongWait time.Duration) WebSocketOption {
+	return func(c *WebSocketConfig) error {
+		if pingInterval <= 0 || pongWait <= pingInterval {
+			return errors.New("websocket: pong wait must be greater than ping interval")
+		}
+		c.pingInterval = pingInterval
+		c.pongWait = pongWait
+		return nil
+	}
+}
+
+// WithWriteWait will assign the time allowed to write a message, it is also the backpressure wait of Send.
+func WithWriteWait(d time.Duration) WebSocketOption {
+	return func(c *WebSocketConfig) error {
+		c.writeWait = d
+		return nil
+	}
+}
+
+// WithReadLimit will assign the maximum size of inbound message in bytes.
+func WithReadLimit(limit int64) WebSocketOption {
+	return func(c *WebSocketConfig) error {
+		c.readLimit = limit
+		return nil
+	}
+}
+
+// WithCheckOrigin will assign the origin check of handshake, same origin is required by default.
+func WithCheckOrigin(fn func(r *http.Request) bool) WebSocketOption {
+	return func(c *WebSocketConfig) error {
+		c.checkOrigin = fn
+		return nil
+	}
+}
+
+// WithSubprotocols will assign the supported subprotocols in order of preference.
+func WithSubprotocols(protocols ...string) WebSocketOption {
+	return func(c *WebSocketConfig) error {
+		c.subprotocols = protocols
+		return nil
+	}
+}
@@ -0,0 +1,175 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	otelTrace "go.opentelemetry.io/otel/trace"
+)
+
+type chatMessage struct {
+	Text string `json:"text"`
+}
+
+func dialWebSocket(t *testing.T, srv *httptest.Server) *websocket.Conn {
+	ws, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return ws
+}
+
+// echo answers every message with its text.
+func echo(c *Conn[chatMessage, chatMessage], msg chatMessage) error {
+	return c.Send(msg)
+}
+
+func TestWebSocket(t *testing.T) {
+	is := assert.New(t)
+	closed := make(chan int, 1)
+	h := WebSocket[chatMessage, chatMessage](echo).OnClose(func(c *Conn[chatMessage, chatMessage]) {
+		closed <- c.closeCode
+	})
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	ws := dialWebSocket(t, srv)
+	is.NoError(ws.WriteJSON(chatMessage{Text: "hi"}))
+	var got chatMessage
+	is.NoError(ws.ReadJSON(&got))
+	is.Equal("hi", got.Text)
+
+	// the message which can not be decoded closes the connection.
+	is.NoError(ws.WriteMessage(websocket.TextMessage, []byte("{")))
+	_, _, err := ws.ReadMessage()
+	is.True(websocket.IsCloseError(err, websocket.CloseUnsupportedData))
+	is.Equal(websocket.CloseUnsupportedData, <-closed)
+}
+
+func TestWebSocketPanic(t *testing.T) {
+	is := assert.New(t)
+	h := WebSocket[chatMessage, chatMessage](func(c *Conn[chatMessage, chatMessage], msg chatMessage) error {
+		if msg.Text == "panic" {
+			panic("boom")
+		}
+		return c.Send(msg)
+	})
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	ws := dialWebSocket(t, srv)
+	defer ws.Close()
+	is.NoError(ws.WriteJSON(chatMessage{Text: "panic"}))
+	_ = ws.SetReadDeadline(time.Now().Add(time.Second))
+	_, _, err := ws.ReadMessage()
+	is.True(websocket.IsCloseError(err, websocket.CloseInternalServerErr), "%v", err)
+	is.Eventually(func() bool {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		return len(h.conns) == 0
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestWebSocketKeepalive(t *testing.T) {
+	h := WebSocket[chatMessage, chatMessage](echo, WithKeepalive(20*time.Millisecond, 60*time.Millisecond))
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	t.Run("pong extends the read deadline", func(t *testing.T) {
+		is := assert.New(t)
+		ws := dialWebSocket(t, srv)
+		defer ws.Close()
+		var pings int32
+		ws.SetPingHandler(func(data string) error {
+			atomic.AddInt32(&pings, 1)
+			return ws.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(time.Second))
+		})
+		go func() {
+			for {
+				if _, _, err := ws.ReadMessage(); err != nil {
+					return
+				}
+			}
+		}()
+		time.Sleep(200 * time.Millisecond)
+		is.GreaterOrEqual(atomic.LoadInt32(&pings), int32(3))
+		is.NoError(ws.WriteJSON(chatMessage{Text: "alive"}), "the connection outlives the pong wait")
+	})
+
+	t.Run("missing pong closes the connection", func(t *testing.T) {
+		is := assert.New(t)
+		ws := dialWebSocket(t, srv)
+		defer ws.Close()
+		ws.SetPingHandler(func(string) error { return nil })
+		_ = ws.SetReadDeadline(time.Now().Add(time.Second))
+		start := time.Now()
+		for {
+			if _, _, err := ws.ReadMessage(); err != nil {
+				var netErr interface{ Timeout() bool }
+				is.False(errors.As(err, &netErr) && netErr.Timeout(), "the server closes the connection")
+				break
+			}
+		}
+		is.Less(time.Since(start), time.Second)
+	})
+}
+
+func TestWebSocketBackpressure(t *testing.T) {
+	is := assert.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &Conn[chatMessage, chatMessage]{
+		ctx:       ctx,
+		cancel:    cancel,
+		span:      otelTrace.SpanFromContext(ctx),
+		codec:     JSONCodec{},
+		send:      make(chan []byte, 1),
+		writeWait: 20 * time.Millisecond,
+	}
+	is.NoError(c.Send(chatMessage{Text: "a"}))
+
+	// the buffer drained within the write wait accepts the message.
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		<-c.send
+	}()
+	is.NoError(c.Send(chatMessage{Text: "b"}))
+
+	// the slow consumer is closed once the write wait elapses.
+	is.ErrorIs(c.Send(chatMessage{Text: "c"}), ErrSendBufferFull)
+	is.Equal(websocket.CloseTryAgainLater, c.closeCode)
+	is.Equal(ErrSendBufferFull.Error(), c.closeText)
+	is.Error(c.Context().Err())
+	is.ErrorIs(c.Send(chatMessage{Text: "d"}), ErrConnClosed)
+}
+
+func TestWebSocketShutdown(t *testing.T) {
+	is := assert.New(t)
+	h := WebSocket[chatMessage, chatMessage](echo)
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	ws := dialWebSocket(t, srv)
+	defer ws.Close()
+	is.NoError(ws.WriteJSON(chatMessage{Text: "hi"}))
+	var got chatMessage
+	is.NoError(ws.ReadJSON(&got))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	is.NoError(srv.Config.Shutdown(ctx))
+	_ = ws.SetReadDeadline(time.Now().Add(time.Second))
+	_, _, err := ws.ReadMessage()
+	is.True(websocket.IsCloseError(err, websocket.CloseGoingAway), "%v", err)
+	is.Eventually(func() bool {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		return len(h.conns) == 0
+	}, time.Second, 10*time.Millisecond)
+}
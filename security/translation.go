@@ -0,0 +1,155 @@
+// Package security is func library that implement security standard.
+// # This manifest was generated by ymir. DO NOT EDIT.
+package security
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/go-playground/locales"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/id"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	enTranslations "github.com/go-playground/validator/v10/translations/en"
+	idTranslations "github.com/go-playground/validator/v10/translations/id"
+)
+
+// DefaultLocale is the locale of ValidateLocale when the requested locale is not supported.
+const DefaultLocale = "en"
+
+// messages is the catalog of custom rules per locale, {0} is the field and {1} is the rule param.
+var messages = map[string]map[string]string{
+	"en": {
+		"date":      "{0} must be a valid date in YYYY-MM-DD format",
+		"datetime":  "{0} must be a valid RFC3339 datetime",
+		"daterange": "{0} must be a date between 1900-01-01 and 2100-01-01",
+		"enum":      "{0} must be one of [{1}]",
+		"default":   "{0} has an invalid default value {1}",
+	},
+	"id": {
+		"date":      "{0} harus berupa tanggal yang valid dengan format YYYY-MM-DD",
+		"datetime":  "{0} harus berupa tanggal dan waktu RFC3339 yang valid",
+		"daterange": "{0} harus berupa tanggal antara 1900-01-01 dan 2100-01-01",
+		"enum":      "{0} harus salah satu dari [{1}]",
+		"default":   "{0} memiliki nilai bawaan {1} yang tidak valid",
+	},
+}
+
+var translation struct {
+	once      sync.Once
+	mu        sync.Mutex
+	validate  *validator.Validate
+	universal *ut.UniversalTranslator
+}
+
+// localeTranslator registers the built-in messages of validator per locale.
+type localeTranslator struct {
+	locale   locales.Translator
+	register func(v *validator.Validate, trans ut.Translator) error
+}
+
+var localeTranslators = []localeTranslator{
+	{locale: en.New(), register: enTranslations.RegisterDefaultTranslations},
+	{locale: id.New(), register: idTranslations.RegisterDefaultTranslations},
+}
+
+// initTranslation builds the validator with translations of every supported locale.
+func initTranslation() {
+	translation.once.Do(func() {
+		supported := make([]locales.Translator, 0, len(localeTranslators))
+		for _, lt := range localeTranslators {
+			supported = append(supported, lt.locale)
+		}
+		translation.validate = newValidator()
+		translation.universal = ut.New(supported[0], supported...)
+		for _, lt := range localeTranslators {
+			trans, _ := translation.universal.GetTranslator(lt.locale.Locale())
+			if err := lt.register(translation.validate, trans); err != nil {
+				panic(err)
+			}
+			for tag, text := range messages[lt.locale.Locale()] {
+				if err := registerMessage(trans, tag, text); err != nil {
+					panic(err)
+				}
+			}
+		}
+	})
+}
+
+func registerMessage(trans ut.Translator, tag, text string) error {
+	return translation.validate.RegisterTranslation(tag, trans,
+		func(ut ut.Translator) error {
+			return ut.Add(tag, text, true)
+		},
+		func(ut ut.Translator, fe validator.FieldError) string {
+			msg, err := ut.T(fe.Tag(), fe.Field(), fe.Param())
+			if err != nil {
+				return fe.Error()
+			}
+			return msg
+		})
+}
+
+// Locales returns the supported locales of ValidateLocale.
+func Locales() []string {
+	supported := make([]string, 0, len(localeTranslators))
+	for _, lt := range localeTranslators {
+		supported = append(supported, lt.locale.Locale())
+	}
+	return supported
+}
+
+// RegisterMessages adds or overrides the messages of rule tags for the supported locale, {0} is the field
+// and {1} is the rule param, e.g. RegisterMessages("id", map[string]string{"required": "{0} wajib diisi"}).
+// It must be called before validation, e.g. on init.
+func RegisterMessages(locale string, tagMessages map[string]string) error {
+	initTranslation()
+	translation.mu.Lock()
+	defer translation.mu.Unlock()
+	trans, found := translation.universal.GetTranslator(locale)
+	if !found {
+		return fmt.Errorf("validator: locale %s is not supported", locale)
+	}
+	for tag, text := range tagMessages {
+		if err := registerMessage(trans, tag, text); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateLocale returns ErrorValidator as Validate does, the messages are translated into locale,
+// e.g. "id" or "en-US". DefaultLocale is used when the locale is not supported.
+func ValidateLocale(s any, locale string) (errors []ErrorValidator) {
+	initTranslation()
+	trans := translator(locale)
+	err := translation.validate.Struct(s)
+	if err == nil {
+		return nil
+	}
+	for _, err := range err.(validator.ValidationErrors) {
+		message := err.Translate(trans)
+		if message == err.Error() {
+			// the tag has no message in catalog.
+			message = fmt.Sprintf("Invalid Type %v for input %s", err.Value(), err.Field())
+		}
+		errors = append(errors, ErrorValidator{
+			Tag:     err.Tag(),
+			Value:   fmt.Sprintf("%v", err.Value()),
+			Field:   err.Field(),
+			Type:    err.Type().String(),
+			Message: message,
+		})
+	}
+	return errors
+}
+
+// translator returns the translator of locale or its base language.
+func translator(locale string) ut.Translator {
+	locale = strings.ReplaceAll(strings.ToLower(strings.TrimSpace(locale)), "-", "_")
+	base := strings.SplitN(locale, "_", 2)[0]
+	trans, _ := translation.universal.FindTranslator(locale, base, DefaultLocale)
+	return trans
+}
@@ -24,20 +24,7 @@ type ErrorValidator struct {
 
 // Validate returns ErrorValidator implements value validations for structs and individual fields based on tags.
 func Validate(s any) (errors []ErrorValidator) {
-	validate := validator.New()
-	_ = validate.RegisterValidation("date", DateValidation)
-	_ = validate.RegisterValidation("datetime", DatetimeValidation)
-	_ = validate.RegisterValidation("daterange", DateRangeValidation)
-	_ = validate.RegisterValidation("enum", ParseTags)
-	_ = validate.RegisterValidation("default", ParseDefault)
-	validate.RegisterTagNameFunc(func(fld reflect.StructField) string {
-		name := strings.SplitN(fld.Tag.Get("json"), ",", 2)[0]
-		if name == "-" {
-			return ""
-		}
-		return name
-	})
-
+	validate := newValidator()
 	if err := validate.Struct(s); err != nil {
 		for _, err := range err.(validator.ValidationErrors) {
 			errors = append(errors, ErrorValidator{
@@ -53,6 +40,24 @@ func Validate(s any) (errors []ErrorValidator) {
 	return nil
 }
 
+// newValidator returns the validator of custom rules, field names are taken from `json` tag.
+func newValidator() *validator.Validate {
+	validate := validator.New()
+	_ = validate.RegisterValidation("date", DateValidation)
+	_ = validate.RegisterValidation("datetime", DatetimeValidation)
+	_ = validate.RegisterValidation("daterange", DateRangeValidation)
+	_ = validate.RegisterValidation("enum", ParseTags)
+	_ = validate.RegisterValidation("default", ParseDefault)
+	validate.RegisterTagNameFunc(func(fld reflect.StructField) string {
+		name := strings.SplitN(fld.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	return validate
+}
+
 // DateValidation custom validator for `datetime` tag.
 func DateValidation(fl validator.FieldLevel) bool {
 	if _, err := time.Parse("2006-01-02", fl.Field().String()); err != nil {
@@ -121,3 +121,37 @@ func TestParseDatetime(t *testing.T) {
 	dt = ParseDatetime("2019-09-01T16:18:22Z00:00")
 	assert.Equal(t, time.Time{}, dt)
 }
+
+func TestValidateLocale(t *testing.T) {
+	is := assert.New(t)
+	in := DataTransferObject{
+		Email:         "nanang.jobs@gmail.com",
+		Password:      "sekret",
+		Today:         "2019-09",
+		CreateDate:    "2019-09-01T16:18:22+00:00",
+		CourierID:     0,
+		PaymentMethod: "cash",
+	}
+	messages := func(errs []ErrorValidator) []string {
+		out := make([]string, 0, len(errs))
+		for _, e := range errs {
+			out = append(out, e.Message)
+		}
+		return out
+	}
+	is.Equal([]string{
+		"today must be a valid date in YYYY-MM-DD format",
+		"courier_id is a required field",
+		"payment_method must be one of [ovo gopay virtual]",
+	}, messages(ValidateLocale(in, "en-US")))
+	is.Equal([]string{
+		"today harus berupa tanggal yang valid dengan format YYYY-MM-DD",
+		"courier_id wajib diisi",
+		"payment_method harus salah satu dari [ovo gopay virtual]",
+	}, messages(ValidateLocale(in, "id")))
+	is.Equal(messages(ValidateLocale(in, "en")), messages(ValidateLocale(in, "fr")))
+
+	is.Error(RegisterMessages("fr", map[string]string{"required": "{0} est requis"}))
+	is.NoError(RegisterMessages("id", map[string]string{"enum": "{0} tidak dikenal"}))
+	is.Equal("payment_method tidak dikenal", ValidateLocale(in, "id")[2].Message)
+}
@@ -0,0 +1,65 @@
+// Package session is implements the server sessions of encrypted cookie or stored data.
+// # This manifest was generated by ymir. DO NOT EDIT.
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/kubuskotak/asgard/security"
+)
+
+// CookieStore is the Store which keeps the whole session in the cookie encrypted by AES-GCM.
+// The cookie is bound to its name, and stays valid until it expires even after Regenerate or Destroy,
+// use the server side store when the sessions must be revoked.
+type CookieStore struct {
+	keys []string
+}
+
+// NewCookieStore creates the store of keys, each key is 16, 24 or 32 bytes. The first key encrypts
+// the cookies and every key decrypts them, so the new key is prepended on rotation and the old key
+// is removed once its cookies are expired.
+func NewCookieStore(keys ...string) (*CookieStore, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("session: cookie store requires a key")
+	}
+	for i, key := range keys {
+		switch len(key) {
+		case 16, 24, 32:
+		default:
+			return nil, fmt.Errorf("session: key %d must be 16, 24 or 32 bytes", i)
+		}
+	}
+	return &CookieStore{keys: keys}, nil
+}
+
+// Load decrypts the session of cookie value.
+func (s *CookieStore) Load(_ context.Context, name, value string) (*Session, error) {
+	for _, key := range s.keys {
+		b, err := security.Decrypt(value, key)
+		if err != nil {
+			continue
+		}
+		sess, err := decode(b, name)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return nil, ErrInvalidCookie
+		}
+		return sess, err
+	}
+	return nil, ErrInvalidCookie
+}
+
+// Save encrypts the session by the first key.
+func (s *CookieStore) Save(_ context.Context, name string, sess *Session) (string, error) {
+	b, err := sess.encode(name)
+	if err != nil {
+		return "", err
+	}
+	return security.Encrypt(b, s.keys[0])
+}
+
+// Delete does nothing, the cookie is expired by Manager.
+func (s *CookieStore) Delete(context.Context, string) error {
+	return nil
+}
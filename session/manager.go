@@ -0,0 +1,362 @@
+// Package session is implements the server sessions of encrypted cookie or stored data.
+// # This manifest was generated by ymir. DO NOT EDIT.
+package session
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/felixge/httpsnoop"
+	"github.com/rs/zerolog/log"
+)
+
+// chunkSize is the max value size of one cookie, browsers limit the cookie with its attributes to 4096 bytes.
+const chunkSize = 3800
+
+// Option is the option of Manager.
+type Option = func(m *Manager) error
+
+// WithCookieName will set the cookie name, "session" by default. The chunks are named "<name>_1", "<name>_2", ...
+func WithCookieName(name string) Option {
+	return func(m *Manager) error {
+		if name == "" {
+			return errors.New("session: cookie name is empty")
+		}
+		m.name = name
+		return nil
+	}
+}
+
+// WithCookiePath will set the cookie path, "/" by default.
+func WithCookiePath(path string) Option {
+	return func(m *Manager) error {
+		m.path = path
+		return nil
+	}
+}
+
+// WithCookieDomain will set the cookie domain, the host only cookie by default.
+func WithCookieDomain(domain string) Option {
+	return func(m *Manager) error {
+		m.domain = domain
+		return nil
+	}
+}
+
+// WithSecure will set the cookie Secure attribute, true by default.
+func WithSecure(secure bool) Option {
+	return func(m *Manager) error {
+		m.secure = secure
+		return nil
+	}
+}
+
+// WithSameSite will set the cookie SameSite attribute, http.SameSiteLaxMode by default.
+func WithSameSite(mode http.SameSite) Option {
+	return func(m *Manager) error {
+		m.sameSite = mode
+		return nil
+	}
+}
+
+// WithIdleTimeout will expire the session unused during d, the expiry slides on every request.
+// It is 30m by default, 0 disables it.
+func WithIdleTimeout(d time.Duration) Option {
+	return func(m *Manager) error {
+		if d < 0 {
+			return errors.New("session: idle timeout is negative")
+		}
+		m.idle = d
+		return nil
+	}
+}
+
+// WithAbsoluteTimeout will expire the session d after its creation regardless of use, 24h by default.
+func WithAbsoluteTimeout(d time.Duration) Option {
+	return func(m *Manager) error {
+		if d <= 0 {
+			return errors.New("session: absolute timeout must be positive")
+		}
+		m.absolute = d
+		return nil
+	}
+}
+
+// WithMaxSize will limit the cookie value of session to n bytes across its chunks, 7600 by default.
+func WithMaxSize(n int) Option {
+	return func(m *Manager) error {
+		if n <= 0 {
+			return errors.New("session: max size must be positive")
+		}
+		m.maxSize = n
+		return nil
+	}
+}
+
+// Manager loads the session of request cookie and saves it into the store before the response is written.
+type Manager struct {
+	store    Store
+	name     string
+	path     string
+	domain   string
+	secure   bool
+	sameSite http.SameSite
+	idle     time.Duration
+	absolute time.Duration
+	maxSize  int
+}
+
+// New creates the session manager of store.
+func New(store Store, opts ...Option) *Manager {
+	m := &Manager{
+		store:    store,
+		name:     "session",
+		path:     "/",
+		secure:   true,
+		sameSite: http.SameSiteLaxMode,
+		idle:     30 * time.Minute,
+		absolute: 24 * time.Hour,
+		maxSize:  2 * chunkSize,
+	}
+	for _, opt := range opts {
+		if err := opt(m); err != nil {
+			panic(err)
+		}
+	}
+	return m
+}
+
+// Middleware puts the session into request context, handlers read it by FromContext. The changed
+// session is saved when the response header is written, use Save to handle its error.
+func (m *Manager) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s, err := m.load(r)
+		if err != nil {
+			log.Error().Err(err).Msg("session")
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		var (
+			mu    sync.Mutex
+			wrote bool
+		)
+		commit := func(ctx context.Context) error {
+			mu.Lock()
+			defer mu.Unlock()
+			if wrote {
+				return nil
+			}
+			return m.commit(ctx, w, s)
+		}
+		before := func() {
+			mu.Lock()
+			defer mu.Unlock()
+			if wrote {
+				return
+			}
+			wrote = true
+			if err := m.commit(r.Context(), w, s); err != nil {
+				log.Error().Err(err).Msg("session")
+			}
+		}
+		ww := httpsnoop.Wrap(w, httpsnoop.Hooks{
+			Write: func(next httpsnoop.WriteFunc) httpsnoop.WriteFunc {
+				return func(b []byte) (int, error) {
+					before()
+					return next(b)
+				}
+			},
+			WriteHeader: func(next httpsnoop.WriteHeaderFunc) httpsnoop.WriteHeaderFunc {
+				return func(code int) {
+					before()
+					next(code)
+				}
+			},
+			ReadFrom: func(next httpsnoop.ReadFromFunc) httpsnoop.ReadFromFunc {
+				return func(src io.Reader) (int64, error) {
+					before()
+					return next(src)
+				}
+			},
+			Flush: func(next httpsnoop.FlushFunc) httpsnoop.FlushFunc {
+				return func() {
+					before()
+					next()
+				}
+			},
+		})
+		ctx := context.WithValue(NewContext(r.Context(), s), ctxCommit, commit)
+		next.ServeHTTP(ww, r.WithContext(ctx))
+		before()
+	})
+}
+
+// Save saves the changed session of request now, so its error, e.g. ErrTooLarge, is handled before
+// the response is written.
+func Save(r *http.Request) error {
+	commit, ok := r.Context().Value(ctxCommit).(func(context.Context) error)
+	if !ok {
+		return ErrNoSession
+	}
+	return commit(r.Context())
+}
+
+// load returns the session of request cookie or the new session when it is missing, invalid or expired.
+func (m *Manager) load(r *http.Request) (*Session, error) {
+	value, chunks := m.readCookie(r)
+	var s *Session
+	if value != "" {
+		loaded, err := m.store.Load(r.Context(), m.name, value)
+		now := time.Now()
+		switch {
+		case err == nil && m.expiresAt(loaded).After(now):
+			s = loaded
+			if m.idle > 0 {
+				s.LastSeenAt, s.dirty = now.UTC(), true
+			}
+		case err == nil:
+			if err = m.store.Delete(r.Context(), loaded.ID); err != nil {
+				return nil, err
+			}
+		case !errors.Is(err, ErrNotFound) && !errors.Is(err, ErrInvalidCookie):
+			return nil, err
+		}
+	}
+	if s == nil {
+		var err error
+		if s, err = newSession(); err != nil {
+			return nil, err
+		}
+	}
+	s.chunks = chunks
+	return s, nil
+}
+
+// commit saves or destroys the changed session and writes its cookies.
+func (m *Manager) commit(ctx context.Context, w http.ResponseWriter, s *Session) error {
+	s.mu.Lock()
+	if !s.dirty {
+		s.mu.Unlock()
+		return nil
+	}
+	id, oldID, isNew, destroyed, chunks := s.ID, s.oldID, s.isNew, s.destroyed, s.chunks
+	s.ExpiresAt = m.expiresAt(s)
+	s.mu.Unlock()
+
+	if oldID != "" {
+		if err := m.store.Delete(ctx, oldID); err != nil {
+			return err
+		}
+	}
+	if destroyed {
+		if !isNew {
+			if err := m.store.Delete(ctx, id); err != nil {
+				return err
+			}
+		}
+		m.expireCookie(w, 0, chunks)
+		s.mu.Lock()
+		s.dirty, s.oldID, s.chunks = false, "", 0
+		s.mu.Unlock()
+		return nil
+	}
+	value, err := m.store.Save(ctx, m.name, s)
+	if errors.Is(err, ErrNotFound) {
+		// the session is destroyed or regenerated by concurrent request, its cookie is not written back.
+		m.expireCookie(w, 0, chunks)
+		s.mu.Lock()
+		s.dirty, s.oldID, s.chunks = false, "", 0
+		s.mu.Unlock()
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if len(value) > m.maxSize {
+		return ErrTooLarge
+	}
+	n := m.writeCookie(w, value, s.ExpiresAt)
+	m.expireCookie(w, n, chunks)
+	s.mu.Lock()
+	s.dirty, s.oldID, s.isNew, s.chunks = false, "", false, n
+	s.mu.Unlock()
+	return nil
+}
+
+// expiresAt returns the earlier of absolute and idle expiry.
+func (m *Manager) expiresAt(s *Session) time.Time {
+	expires := s.CreatedAt.Add(m.absolute)
+	if m.idle > 0 {
+		if idle := s.LastSeenAt.Add(m.idle); idle.Before(expires) {
+			expires = idle
+		}
+	}
+	return expires
+}
+
+// readCookie joins the chunks of session cookie.
+func (m *Manager) readCookie(r *http.Request) (string, int) {
+	var (
+		b strings.Builder
+		n int
+	)
+	for ; n*chunkSize < m.maxSize; n++ {
+		c, err := r.Cookie(m.chunkName(n))
+		if err != nil {
+			break
+		}
+		b.WriteString(c.Value)
+	}
+	return b.String(), n
+}
+
+// writeCookie splits value into chunks and returns their count.
+func (m *Manager) writeCookie(w http.ResponseWriter, value string, expires time.Time) int {
+	n := 0
+	for start := 0; start < len(value); start += chunkSize {
+		end := start + chunkSize
+		if end > len(value) {
+			end = len(value)
+		}
+		c := m.cookie(m.chunkName(n), value[start:end])
+		c.Expires = expires
+		c.MaxAge = int(time.Until(expires).Seconds()) + 1
+		http.SetCookie(w, c)
+		n++
+	}
+	return n
+}
+
+// expireCookie removes the chunks from index from until to.
+func (m *Manager) expireCookie(w http.ResponseWriter, from, to int) {
+	for i := from; i < to; i++ {
+		c := m.cookie(m.chunkName(i), "")
+		c.MaxAge = -1
+		http.SetCookie(w, c)
+	}
+}
+
+func (m *Manager) cookie(name, value string) *http.Cookie {
+	return &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     m.path,
+		Domain:   m.domain,
+		Secure:   m.secure,
+		HttpOnly: true,
+		SameSite: m.sameSite,
+	}
+}
+
+func (m *Manager) chunkName(i int) string {
+	if i == 0 {
+		return m.name
+	}
+	return m.name + "_" + strconv.Itoa(i)
+}
@@ -0,0 +1,236 @@
+// Package session is implements the server sessions of encrypted cookie or stored data.
+// # This manifest was generated by ymir. DO NOT EDIT.
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kubuskotak/asgard/security"
+)
+
+const idLength = 32
+
+var (
+	// ErrNotFound is define error when the session does not exist or is expired.
+	ErrNotFound = errors.New("session is not found")
+	// ErrInvalidCookie is define error when the session cookie can not be decrypted.
+	ErrInvalidCookie = errors.New("session cookie is invalid")
+	// ErrTooLarge is define error when the session cookie exceeds the max size.
+	ErrTooLarge = errors.New("session cookie is too large")
+	// ErrNoSession is define error when the context has no session, the Manager middleware is missing.
+	ErrNoSession = errors.New("session is missing in context")
+)
+
+// Session is the data of client across requests, the values are stored as JSON.
+type Session struct {
+	ID         string
+	CreatedAt  time.Time
+	LastSeenAt time.Time
+	ExpiresAt  time.Time
+
+	mu        sync.Mutex
+	values    map[string]json.RawMessage
+	isNew     bool
+	dirty     bool
+	destroyed bool
+	oldID     string
+	chunks    int
+}
+
+func newSession() (*Session, error) {
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	return &Session{
+		ID:         id,
+		CreatedAt:  now,
+		LastSeenAt: now,
+		values:     make(map[string]json.RawMessage),
+		isNew:      true,
+	}, nil
+}
+
+func newID() (string, error) {
+	b, err := security.GenerateRandomBytes(idLength)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// IsNew reports whether the session is not stored yet, the regenerated session is new until it is saved.
+func (s *Session) IsNew() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.isNew
+}
+
+// Set stores the JSON of v by key.
+func (s *Session) Set(key string, v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values[key] = b
+	s.dirty = true
+	return nil
+}
+
+// Get decodes the value of key into v, false is returned when the key does not exist.
+func (s *Session) Get(key string, v any) (bool, error) {
+	s.mu.Lock()
+	b, ok := s.values[key]
+	s.mu.Unlock()
+	if !ok {
+		return false, nil
+	}
+	return true, json.Unmarshal(b, v)
+}
+
+// Has reports whether the key exists.
+func (s *Session) Has(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.values[key]
+	return ok
+}
+
+// Keys returns the sorted keys.
+func (s *Session) Keys() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keys := make([]string, 0, len(s.values))
+	for key := range s.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Delete removes the key.
+func (s *Session) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.values[key]; ok {
+		delete(s.values, key)
+		s.dirty = true
+	}
+}
+
+// Clear removes all keys.
+func (s *Session) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values = make(map[string]json.RawMessage)
+	s.dirty = true
+}
+
+// Regenerate replaces the session id and keeps the values, call it on privilege change, e.g. login,
+// so the id known before is useless. The stored session of previous id is deleted.
+func (s *Session) Regenerate() error {
+	id, err := newID()
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.isNew && s.oldID == "" {
+		s.oldID = s.ID
+	}
+	s.ID = id
+	s.isNew = true
+	s.dirty = true
+	return nil
+}
+
+// Destroy removes the values, the stored session and its cookie at the end of request, e.g. logout.
+func (s *Session) Destroy() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values = make(map[string]json.RawMessage)
+	s.destroyed = true
+	s.dirty = true
+}
+
+// Value returns the value of key decoded as T.
+func Value[T any](s *Session, key string) (T, bool) {
+	var v T
+	ok, err := s.Get(key, &v)
+	return v, ok && err == nil
+}
+
+type ctxKey int
+
+const (
+	ctxSession ctxKey = iota
+	ctxCommit
+)
+
+// NewContext returns the context with session.
+func NewContext(ctx context.Context, s *Session) context.Context {
+	return context.WithValue(ctx, ctxSession, s)
+}
+
+// FromContext returns the session of context.
+func FromContext(ctx context.Context) (*Session, bool) {
+	s, ok := ctx.Value(ctxSession).(*Session)
+	return s, ok
+}
+
+// record is the serialized session of stores.
+type record struct {
+	Name       string                     `json:"n,omitempty"`
+	ID         string                     `json:"i"`
+	Values     map[string]json.RawMessage `json:"v,omitempty"`
+	CreatedAt  int64                      `json:"c"`
+	LastSeenAt int64                      `json:"s"`
+	ExpiresAt  int64                      `json:"e"`
+}
+
+// encode serializes the session, name binds the cookie name of encrypted cookie.
+func (s *Session) encode(name string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return json.Marshal(record{
+		Name:       name,
+		ID:         s.ID,
+		Values:     s.values,
+		CreatedAt:  s.CreatedAt.UnixNano(),
+		LastSeenAt: s.LastSeenAt.UnixNano(),
+		ExpiresAt:  s.ExpiresAt.UnixNano(),
+	})
+}
+
+// decode deserializes the session of name, ErrNotFound is returned when it is expired.
+func decode(b []byte, name string) (*Session, error) {
+	var rec record
+	if err := json.Unmarshal(b, &rec); err != nil {
+		return nil, err
+	}
+	if rec.Name != name {
+		return nil, ErrInvalidCookie
+	}
+	s := &Session{
+		ID:         rec.ID,
+		CreatedAt:  time.Unix(0, rec.CreatedAt).UTC(),
+		LastSeenAt: time.Unix(0, rec.LastSeenAt).UTC(),
+		ExpiresAt:  time.Unix(0, rec.ExpiresAt).UTC(),
+		values:     rec.Values,
+	}
+	if s.values == nil {
+		s.values = make(map[string]json.RawMessage)
+	}
+	if !s.ExpiresAt.After(time.Now()) {
+		return nil, ErrNotFound
+	}
+	return s, nil
+}
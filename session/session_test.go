@@ -0,0 +1,227 @@
+package session
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	_ "github.com/kubuskotak/asgard/sqlite"
+)
+
+const (
+	oldKey = "abcdabcdabcdabcdabcdabcdabcdabcd"
+	newKey = "efghefghefghefghefghefghefghefgh"
+)
+
+func handler(m *Manager) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/set", func(w http.ResponseWriter, r *http.Request) {
+		s, _ := FromContext(r.Context())
+		_ = s.Set("user", r.URL.Query().Get("user"))
+	})
+	mux.HandleFunc("/get", func(w http.ResponseWriter, r *http.Request) {
+		s, _ := FromContext(r.Context())
+		user, _ := Value[string](s, "user")
+		_, _ = w.Write([]byte(user))
+	})
+	mux.HandleFunc("/big", func(w http.ResponseWriter, r *http.Request) {
+		s, _ := FromContext(r.Context())
+		n, _ := strconv.Atoi(r.URL.Query().Get("n"))
+		_ = s.Set("big", strings.Repeat("x", n))
+		if errors.Is(Save(r), ErrTooLarge) {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+		}
+	})
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		s, _ := FromContext(r.Context())
+		_ = s.Regenerate()
+	})
+	mux.HandleFunc("/logout", func(w http.ResponseWriter, r *http.Request) {
+		s, _ := FromContext(r.Context())
+		s.Destroy()
+	})
+	return m.Middleware(mux)
+}
+
+// client keeps the cookies across requests.
+type client struct {
+	h       http.Handler
+	cookies map[string]*http.Cookie
+}
+
+func (c *client) do(path string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	for _, cookie := range c.cookies {
+		req.AddCookie(cookie)
+	}
+	rec := httptest.NewRecorder()
+	c.h.ServeHTTP(rec, req)
+	for _, cookie := range rec.Result().Cookies() {
+		if cookie.MaxAge < 0 {
+			delete(c.cookies, cookie.Name)
+			continue
+		}
+		c.cookies[cookie.Name] = cookie
+	}
+	return rec
+}
+
+// with copies the cookies of other client.
+func (c *client) with(cookies map[string]*http.Cookie) *client {
+	for name, cookie := range cookies {
+		c.cookies[name] = cookie
+	}
+	return c
+}
+
+func newClient(h http.Handler) *client {
+	return &client{h: h, cookies: make(map[string]*http.Cookie)}
+}
+
+func TestCookieStore(t *testing.T) {
+	is := assert.New(t)
+	_, err := NewCookieStore("short")
+	is.Error(err)
+	oldStore, err := NewCookieStore(oldKey)
+	is.NoError(err)
+	c := newClient(handler(New(oldStore)))
+	is.Empty(c.do("/get").Result().Cookies())
+	c.do("/set?user=alice")
+	is.Equal("alice", c.do("/get").Body.String())
+	cookie := c.cookies["session"]
+	is.True(cookie.HttpOnly)
+	is.True(cookie.Secure)
+	is.Equal(http.SameSiteLaxMode, cookie.SameSite)
+
+	// the rotated store reads the old cookie and writes it by the new key.
+	rotated, _ := NewCookieStore(newKey, oldKey)
+	c.h = handler(New(rotated))
+	is.Equal("alice", c.do("/get").Body.String())
+	newOnly, _ := NewCookieStore(newKey)
+	c.h = handler(New(newOnly))
+	is.Equal("alice", c.do("/get").Body.String())
+
+	// the cookie is bound to its name.
+	other := newClient(handler(New(newOnly, WithCookieName("other"))))
+	other.cookies["other"] = &http.Cookie{Name: "other", Value: c.cookies["session"].Value}
+	is.Empty(other.do("/get").Body.String())
+	other.cookies["other"] = &http.Cookie{Name: "other", Value: "tampered"}
+	is.Empty(other.do("/get").Body.String())
+
+	// the large session is chunked up to the max size.
+	c.do("/big?n=5000")
+	is.Contains(c.cookies, "session_1")
+	is.Equal("alice", c.do("/get").Body.String())
+	c.do("/big?n=10")
+	is.NotContains(c.cookies, "session_1")
+	is.Equal(http.StatusRequestEntityTooLarge, c.do("/big?n=9000").Code)
+
+	c.do("/logout")
+	is.Empty(c.cookies)
+}
+
+func TestServerStore(t *testing.T) {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "sessions.db"))
+	assert.NoError(t, err)
+	defer db.Close()
+	sqliteStore, err := NewSQLiteStore(context.Background(), db)
+	assert.NoError(t, err)
+
+	for name, store := range map[string]Store{"memory": NewMemoryStore(), "sqlite": sqliteStore} {
+		t.Run(name, func(t *testing.T) {
+			is := assert.New(t)
+			c := newClient(handler(New(store)))
+			c.do("/set?user=alice")
+			id := c.cookies["session"].Value
+			is.Len(id, idLength)
+			is.Equal("alice", c.do("/get").Body.String())
+
+			// the regenerated id keeps the values and revokes the previous id.
+			c.do("/login")
+			is.NotEqual(id, c.cookies["session"].Value)
+			is.Equal("alice", c.do("/get").Body.String())
+			stale := newClient(c.h)
+			stale.cookies["session"] = &http.Cookie{Name: "session", Value: id}
+			is.Empty(stale.do("/get").Body.String())
+
+			id = c.cookies["session"].Value
+			c.do("/logout")
+			is.Empty(c.cookies)
+			_, err := store.Load(context.Background(), "session", id)
+			is.ErrorIs(err, ErrNotFound)
+		})
+	}
+}
+
+func TestConcurrentLogout(t *testing.T) {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "sessions.db"))
+	assert.NoError(t, err)
+	defer db.Close()
+	sqliteStore, err := NewSQLiteStore(context.Background(), db)
+	assert.NoError(t, err)
+
+	for name, store := range map[string]Store{"memory": NewMemoryStore(), "sqlite": sqliteStore} {
+		t.Run(name, func(t *testing.T) {
+			is := assert.New(t)
+			loaded, release := make(chan struct{}), make(chan struct{})
+			mux := http.NewServeMux()
+			mux.Handle("/", handler(New(store)))
+			mux.Handle("/slow", New(store).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				close(loaded)
+				<-release
+			})))
+			c := newClient(mux)
+			c.do("/set?user=alice")
+			id := c.cookies["session"].Value
+
+			// the request in flight during logout does not store the session again.
+			slow := make(chan *httptest.ResponseRecorder)
+			go func() {
+				slow <- newClient(mux).with(c.cookies).do("/slow")
+			}()
+			<-loaded
+			c.do("/logout")
+			close(release)
+			rec := <-slow
+			if is.Len(rec.Result().Cookies(), 1) {
+				is.Equal(-1, rec.Result().Cookies()[0].MaxAge)
+			}
+			_, err := store.Load(context.Background(), "session", id)
+			is.ErrorIs(err, ErrNotFound)
+		})
+	}
+}
+
+func TestExpiry(t *testing.T) {
+	is := assert.New(t)
+	store := NewMemoryStore()
+
+	idle := newClient(handler(New(store, WithIdleTimeout(50*time.Millisecond))))
+	idle.do("/set?user=alice")
+	for i := 0; i < 3; i++ {
+		time.Sleep(30 * time.Millisecond)
+		is.Equal("alice", idle.do("/get").Body.String(), "the expiry slides on use")
+	}
+	time.Sleep(60 * time.Millisecond)
+	is.Empty(idle.do("/get").Body.String())
+
+	absolute := newClient(handler(New(store, WithIdleTimeout(time.Minute), WithAbsoluteTimeout(80*time.Millisecond))))
+	absolute.do("/set?user=bob")
+	time.Sleep(50 * time.Millisecond)
+	is.Equal("bob", absolute.do("/get").Body.String())
+	time.Sleep(50 * time.Millisecond)
+	is.Empty(absolute.do("/get").Body.String())
+
+	n, err := store.DeleteExpired(context.Background())
+	is.NoError(err)
+	is.Equal(2, n)
+}
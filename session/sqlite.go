@@ -0,0 +1,87 @@
+// Package session is implements the server sessions of encrypted cookie or stored data.
+// # This manifest was generated by ymir. DO NOT EDIT.
+package session
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+const sqliteSchema = `CREATE TABLE IF NOT EXISTS sessions (
+	id         TEXT PRIMARY KEY,
+	data       BLOB NOT NULL,
+	expires_at INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS sessions_expires_at ON sessions (expires_at);`
+
+// SQLiteStore is the Store keyed by session id in sqlite database, e.g. opened by the sqlite3 driver of asgard/sqlite.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteStore creates the store and migrates the sessions table.
+func NewSQLiteStore(ctx context.Context, db *sql.DB) (*SQLiteStore, error) {
+	if _, err := db.ExecContext(ctx, sqliteSchema); err != nil {
+		return nil, err
+	}
+	return &SQLiteStore{db: db}, nil
+}
+
+// Load returns the session of id.
+func (s *SQLiteStore) Load(ctx context.Context, _, id string) (*Session, error) {
+	var data []byte
+	err := s.db.QueryRowContext(ctx, `SELECT data FROM sessions WHERE id = ? AND expires_at > ?`,
+		id, time.Now().UnixNano()).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return decode(data, "")
+}
+
+// Save stores the session and returns its id.
+func (s *SQLiteStore) Save(ctx context.Context, _ string, sess *Session) (string, error) {
+	b, err := sess.encode("")
+	if err != nil {
+		return "", err
+	}
+	if sess.IsNew() {
+		_, err = s.db.ExecContext(ctx, `INSERT INTO sessions (id, data, expires_at) VALUES (?, ?, ?)`,
+			sess.ID, b, sess.ExpiresAt.UnixNano())
+		if err != nil {
+			return "", err
+		}
+		return sess.ID, nil
+	}
+	res, err := s.db.ExecContext(ctx, `UPDATE sessions SET data = ?, expires_at = ? WHERE id = ?`,
+		b, sess.ExpiresAt.UnixNano(), sess.ID)
+	if err != nil {
+		return "", err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return "", err
+	} else if n < 1 {
+		return "", ErrNotFound
+	}
+	return sess.ID, nil
+}
+
+// Delete removes the session of id.
+func (s *SQLiteStore) Delete(ctx context.Context, id string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM sessions WHERE id = ?`, id)
+	return err
+}
+
+// DeleteExpired removes the expired sessions.
+func (s *SQLiteStore) DeleteExpired(ctx context.Context) (int, error) {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM sessions WHERE expires_at <= ?`, time.Now().UnixNano())
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
@@ -0,0 +1,84 @@
+// Package session is implements the server sessions of encrypted cookie or stored data.
+// # This manifest was generated by ymir. DO NOT EDIT.
+package session
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Store persists the sessions, the cookie holds the value returned by Save.
+type Store interface {
+	// Load returns the session of cookie value, ErrNotFound is returned when it does not exist or is expired.
+	Load(ctx context.Context, name, value string) (*Session, error)
+	// Save persists the session and returns the cookie value. The session which is not new is only updated,
+	// ErrNotFound is returned when it is not stored anymore, e.g. destroyed by concurrent request.
+	Save(ctx context.Context, name string, s *Session) (string, error)
+	// Delete removes the session of id.
+	Delete(ctx context.Context, id string) error
+}
+
+// MemoryStore is the in memory Store keyed by session id, the sessions are lost on restart.
+type MemoryStore struct {
+	mu       sync.RWMutex
+	sessions map[string]memoryEntry
+}
+
+type memoryEntry struct {
+	data      []byte
+	expiresAt time.Time
+}
+
+// NewMemoryStore creates the memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{sessions: make(map[string]memoryEntry)}
+}
+
+// Load returns the session of id.
+func (s *MemoryStore) Load(_ context.Context, _, id string) (*Session, error) {
+	s.mu.RLock()
+	entry, ok := s.sessions[id]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return decode(entry.data, "")
+}
+
+// Save stores the session and returns its id.
+func (s *MemoryStore) Save(_ context.Context, _ string, sess *Session) (string, error) {
+	b, err := sess.encode("")
+	if err != nil {
+		return "", err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.sessions[sess.ID]; !ok && !sess.IsNew() {
+		return "", ErrNotFound
+	}
+	s.sessions[sess.ID] = memoryEntry{data: b, expiresAt: sess.ExpiresAt}
+	return sess.ID, nil
+}
+
+// Delete removes the session of id.
+func (s *MemoryStore) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, id)
+	return nil
+}
+
+// DeleteExpired removes the expired sessions.
+func (s *MemoryStore) DeleteExpired(_ context.Context) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now, n := time.Now(), 0
+	for id, entry := range s.sessions {
+		if !entry.expiresAt.After(now) {
+			delete(s.sessions, id)
+			n++
+		}
+	}
+	return n, nil
+}
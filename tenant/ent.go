@@ -0,0 +1,76 @@
+// Package tenant is implements multi-tenancy context propagation and tenant isolation.
+// # This manifest was generated by ymir. DO NOT EDIT.
+package tenant
+
+import (
+	"context"
+	"fmt"
+
+	"entgo.io/ent"
+	"entgo.io/ent/dialect/sql"
+)
+
+// wherer is implemented by the generated ent queries and mutations.
+type wherer interface {
+	WhereP(ps ...func(*sql.Selector))
+}
+
+// fieldEQ returns the predicate of tenant column.
+func fieldEQ(field, id string) func(*sql.Selector) {
+	return func(s *sql.Selector) {
+		s.Where(sql.EQ(s.C(field), id))
+	}
+}
+
+// EntInterceptor defines a query traverser which scopes the ent queries to the tenant of context,
+// alongside tracer.EntInterceptor. It is registered on the schemas which have the tenant string field,
+// queries without tenant are rejected with ErrTenantRequired, see SkipIsolation.
+func EntInterceptor(field string) ent.Interceptor {
+	return ent.TraverseFunc(func(ctx context.Context, q ent.Query) error {
+		if skipped(ctx) {
+			return nil
+		}
+		w, ok := q.(wherer)
+		if !ok {
+			return nil
+		}
+		id := IDFromContext(ctx)
+		if id == "" {
+			return ErrTenantRequired
+		}
+		w.WhereP(fieldEQ(field, id))
+		return nil
+	})
+}
+
+// EntHook defines the mutation middleware which isolates the ent mutations to the tenant of context,
+// alongside tracer.EntHook. The tenant field is set on create, update and delete are scoped by tenant
+// predicate and setting the field of other tenant is rejected with ErrCrossTenant.
+func EntHook(field string) ent.Hook {
+	return func(next ent.Mutator) ent.Mutator {
+		return ent.MutateFunc(func(ctx context.Context, m ent.Mutation) (ent.Value, error) {
+			if skipped(ctx) {
+				return next.Mutate(ctx, m)
+			}
+			id := IDFromContext(ctx)
+			if id == "" {
+				return nil, ErrTenantRequired
+			}
+			if v, ok := m.Field(field); ok && fmt.Sprint(v) != id {
+				return nil, ErrCrossTenant
+			}
+			if m.Op().Is(ent.OpCreate) {
+				if err := m.SetField(field, id); err != nil {
+					return nil, err
+				}
+				return next.Mutate(ctx, m)
+			}
+			w, ok := m.(wherer)
+			if !ok {
+				return nil, fmt.Errorf("%w: %s mutation can not be scoped", ErrCrossTenant, m.Type())
+			}
+			w.WhereP(fieldEQ(field, id))
+			return next.Mutate(ctx, m)
+		})
+	}
+}
@@ -0,0 +1,25 @@
+// Package tenant is implements multi-tenancy context propagation and tenant isolation.
+// # This manifest was generated by ymir. DO NOT EDIT.
+package tenant
+
+import (
+	"context"
+
+	"github.com/rs/zerolog"
+)
+
+// ContextHook returns a zerolog.Hook that will add the tenant ID of ctx to log events,
+// e.g. log.Hook(tracer.TraceContextHook(ctx)).Hook(tenant.ContextHook(ctx)).
+func ContextHook(ctx context.Context) zerolog.Hook {
+	return &contextHook{ctx: ctx}
+}
+
+type contextHook struct {
+	ctx context.Context
+}
+
+func (h *contextHook) Run(e *zerolog.Event, _ zerolog.Level, _ string) {
+	if id := IDFromContext(h.ctx); id != "" {
+		e.Str(IDFieldName, id)
+	}
+}
@@ -0,0 +1,83 @@
+// Package tenant is implements multi-tenancy context propagation and tenant isolation.
+// # This manifest was generated by ymir. DO NOT EDIT.
+package tenant
+
+import (
+	"errors"
+	"net/http"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/kubuskotak/asgard/rest"
+)
+
+// MiddlewareConfig holds the configuration of Middleware.
+type MiddlewareConfig struct {
+	// Optional lets the request without tenant pass through, the request context has no tenant.
+	Optional bool
+}
+
+// MiddlewareOption is the option of Middleware.
+type MiddlewareOption = func(c *MiddlewareConfig) error
+
+// WithOptional will let the request without tenant pass through.
+func WithOptional() MiddlewareOption {
+	return func(c *MiddlewareConfig) error {
+		c.Optional = true
+		return nil
+	}
+}
+
+// Middleware is middleware handler to resolve the tenant of request, validate it against registry
+// and store it in the request context. The tenant ID is set on the span attributes.
+// The request is answered with 400 when the tenant is required, 404 when it is not registered
+// and 403 when it is disabled.
+func Middleware(resolver Resolver, registry Registry, opts ...MiddlewareOption) func(next http.Handler) http.Handler {
+	cfg := &MiddlewareConfig{}
+	for _, opt := range opts {
+		if err := opt(cfg); err != nil {
+			panic(err)
+		}
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id, err := resolver(r)
+			if err != nil {
+				reject(rest.ErrBadRequest, err).ServeHTTP(w, r)
+				return
+			}
+			if id == "" {
+				if cfg.Optional {
+					next.ServeHTTP(w, r)
+					return
+				}
+				reject(rest.ErrBadRequest, ErrTenantRequired).ServeHTTP(w, r)
+				return
+			}
+			t, err := registry.Lookup(r.Context(), id)
+			switch {
+			case errors.Is(err, ErrTenantNotFound):
+				reject(rest.ErrNotFound, err).ServeHTTP(w, r)
+				return
+			case err != nil:
+				reject(rest.ErrInternalServerError, err).ServeHTTP(w, r)
+				return
+			case t.Disabled:
+				reject(rest.ErrForbidden, ErrTenantDisabled).ServeHTTP(w, r)
+				return
+			}
+			trace.SpanFromContext(r.Context()).SetAttributes(attribute.String(IDFieldName, t.ID))
+			*r = *r.WithContext(NewContext(r.Context(), t))
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// reject writes the error envelope of status.
+func reject(status func(http.ResponseWriter, *http.Request, error) error, err error) http.Handler {
+	return http.HandlerFunc(rest.HandlerAdapter[rest.RequestNotFound](
+		func(w http.ResponseWriter, r *http.Request) (rest.ResponseNotFound, error) {
+			return rest.ResponseNotFound{}, status(w, r, err)
+		}).JSON)
+}
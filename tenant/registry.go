@@ -0,0 +1,61 @@
+// Package tenant is implements multi-tenancy context propagation and tenant isolation.
+// # This manifest was generated by ymir. DO NOT EDIT.
+package tenant
+
+import (
+	"context"
+	"sync"
+)
+
+// Registry returns the registered tenant, ErrTenantNotFound is returned when it is not registered.
+type Registry interface {
+	Lookup(ctx context.Context, id string) (Tenant, error)
+}
+
+// RegistryFunc is the Registry of func, e.g. backed by database or config service.
+type RegistryFunc func(ctx context.Context, id string) (Tenant, error)
+
+// Lookup calls f(ctx, id).
+func (f RegistryFunc) Lookup(ctx context.Context, id string) (Tenant, error) {
+	return f(ctx, id)
+}
+
+// MemoryRegistry is the in memory Registry.
+type MemoryRegistry struct {
+	mu      sync.RWMutex
+	tenants map[string]Tenant
+}
+
+// NewMemoryRegistry creates the registry of tenants.
+func NewMemoryRegistry(tenants ...Tenant) *MemoryRegistry {
+	m := &MemoryRegistry{tenants: make(map[string]Tenant, len(tenants))}
+	for _, t := range tenants {
+		m.tenants[t.ID] = t
+	}
+	return m
+}
+
+// Add registers or replaces the tenant.
+func (m *MemoryRegistry) Add(t Tenant) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tenants[t.ID] = t
+}
+
+// Remove unregisters the tenant.
+func (m *MemoryRegistry) Remove(id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.tenants, id)
+}
+
+// Lookup returns the registered tenant.
+func (m *MemoryRegistry) Lookup(_ context.Context, id string) (Tenant, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	t, ok := m.tenants[id]
+	if !ok {
+		return Tenant{}, ErrTenantNotFound
+	}
+	return t, nil
+}
@@ -0,0 +1,87 @@
+// Package tenant is implements multi-tenancy context propagation and tenant isolation.
+// # This manifest was generated by ymir. DO NOT EDIT.
+package tenant
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Resolver returns the tenant ID of request, it is empty when the request has no tenant.
+type Resolver func(r *http.Request) (string, error)
+
+// ClaimsFunc returns the claims of verified token, e.g. stored in context by the authentication middleware.
+type ClaimsFunc func(r *http.Request) (map[string]any, bool)
+
+// FromSubdomain resolves the tenant from the first label of host under domain,
+// e.g. acme.example.com with domain example.com resolves acme.
+func FromSubdomain(domain string) Resolver {
+	suffix := "." + strings.Trim(strings.ToLower(domain), ".")
+	return func(r *http.Request) (string, error) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		host = strings.ToLower(host)
+		if !strings.HasSuffix(host, suffix) {
+			return "", nil
+		}
+		labels := strings.Split(strings.TrimSuffix(host, suffix), ".")
+		return labels[len(labels)-1], nil
+	}
+}
+
+// FromHeader resolves the tenant from request header, see HeaderTenantID.
+func FromHeader(name string) Resolver {
+	return func(r *http.Request) (string, error) {
+		return strings.TrimSpace(r.Header.Get(name)), nil
+	}
+}
+
+// FromClaim resolves the tenant from the claim of verified token, the token is never parsed here,
+// so claims must come from the authentication middleware which runs before.
+func FromClaim(claim string, claims ClaimsFunc) Resolver {
+	return func(r *http.Request) (string, error) {
+		values, ok := claims(r)
+		if !ok {
+			return "", nil
+		}
+		switch v := values[claim].(type) {
+		case nil:
+			return "", nil
+		case string:
+			return v, nil
+		case fmt.Stringer:
+			return v.String(), nil
+		default:
+			return fmt.Sprint(v), nil
+		}
+	}
+}
+
+// FromPath resolves the tenant from chi url param, e.g. /tenants/{tenant}/items.
+func FromPath(param string) Resolver {
+	return func(r *http.Request) (string, error) {
+		return chi.URLParam(r, param), nil
+	}
+}
+
+// Chain resolves the tenant by the first resolver which returns a tenant ID.
+func Chain(resolvers ...Resolver) Resolver {
+	return func(r *http.Request) (string, error) {
+		for _, resolve := range resolvers {
+			id, err := resolve(r)
+			if err != nil {
+				return "", err
+			}
+			if id != "" {
+				return id, nil
+			}
+		}
+		return "", nil
+	}
+}
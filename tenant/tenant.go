@@ -0,0 +1,69 @@
+// Package tenant is implements multi-tenancy context propagation and tenant isolation.
+// # This manifest was generated by ymir. DO NOT EDIT.
+package tenant
+
+import (
+	"context"
+	"errors"
+)
+
+const (
+	// IDFieldName is the field name of tenant ID on logs and span attributes.
+	IDFieldName = "tenant.id"
+
+	// HeaderTenantID is the default header of tenant ID.
+	HeaderTenantID = "X-Tenant-ID"
+)
+
+var (
+	// ErrTenantRequired is define error when the tenant can not be resolved.
+	ErrTenantRequired = errors.New("tenant is required")
+	// ErrTenantNotFound is define error when the tenant is not registered.
+	ErrTenantNotFound = errors.New("tenant is not found")
+	// ErrTenantDisabled is define error when the tenant is disabled.
+	ErrTenantDisabled = errors.New("tenant is disabled")
+	// ErrCrossTenant is define error when the mutation touches the data of other tenant.
+	ErrCrossTenant = errors.New("cross tenant mutation is not allowed")
+)
+
+// Tenant holds the definition of tenant entity.
+type Tenant struct {
+	ID       string
+	Name     string
+	Disabled bool
+	Metadata map[string]string
+}
+
+type ctxKey int
+
+const (
+	ctxTenant ctxKey = iota
+	ctxSkip
+)
+
+// NewContext returns the context with tenant.
+func NewContext(ctx context.Context, t Tenant) context.Context {
+	return context.WithValue(ctx, ctxTenant, t)
+}
+
+// FromContext returns the tenant of context.
+func FromContext(ctx context.Context) (Tenant, bool) {
+	t, ok := ctx.Value(ctxTenant).(Tenant)
+	return t, ok
+}
+
+// IDFromContext returns the tenant ID of context, it is empty when there is no tenant.
+func IDFromContext(ctx context.Context) string {
+	t, _ := FromContext(ctx)
+	return t.ID
+}
+
+// SkipIsolation returns the context that bypasses the ent tenant isolation, e.g. for migrations and admin jobs.
+func SkipIsolation(ctx context.Context) context.Context {
+	return context.WithValue(ctx, ctxSkip, true)
+}
+
+func skipped(ctx context.Context) bool {
+	skip, _ := ctx.Value(ctxSkip).(bool)
+	return skip
+}
@@ -0,0 +1,142 @@
+package tenant
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"entgo.io/ent"
+	"entgo.io/ent/dialect/sql"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kubuskotak/asgard/rest"
+)
+
+type ctxClaims int
+
+func TestResolvers(t *testing.T) {
+	is := assert.New(t)
+	r := httptest.NewRequest(http.MethodGet, "http://acme.example.com:8080/items", nil)
+	id, _ := FromSubdomain("example.com")(r)
+	is.Equal("acme", id)
+
+	r.Header.Set(HeaderTenantID, "globex")
+	claims := func(r *http.Request) (map[string]any, bool) {
+		c, ok := r.Context().Value(ctxClaims(0)).(map[string]any)
+		return c, ok
+	}
+	resolve := Chain(FromClaim("tid", claims), FromHeader(HeaderTenantID), FromSubdomain("example.com"))
+	id, _ = resolve(r)
+	is.Equal("globex", id)
+	id, _ = resolve(r.WithContext(context.WithValue(r.Context(), ctxClaims(0), map[string]any{"tid": "initech"})))
+	is.Equal("initech", id)
+}
+
+func TestMiddleware(t *testing.T) {
+	is := assert.New(t)
+	registry := NewMemoryRegistry(Tenant{ID: "acme"}, Tenant{ID: "umbrella", Disabled: true})
+	var got string
+	h := Middleware(FromHeader(HeaderTenantID), registry)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = IDFromContext(r.Context())
+	}))
+	serve := func(id string) int {
+		r := httptest.NewRequest(http.MethodGet, "/items", nil)
+		if id != "" {
+			r.Header.Set(HeaderTenantID, id)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+		return rec.Code
+	}
+	is.Equal(http.StatusOK, serve("acme"))
+	is.Equal("acme", got)
+	is.Equal(http.StatusBadRequest, serve(""))
+	is.Equal(http.StatusNotFound, serve("globex"))
+	is.Equal(http.StatusForbidden, serve("umbrella"))
+}
+
+func TestPropagation(t *testing.T) {
+	is := assert.New(t)
+	ctx := NewContext(context.Background(), Tenant{ID: "acme"})
+
+	buf := &bytes.Buffer{}
+	logger := zerolog.New(buf).Hook(ContextHook(ctx))
+	logger.Info().Msg("test")
+	is.Contains(buf.String(), `"tenant.id":"acme"`)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Header.Get(HeaderTenantID)))
+	}))
+	defer srv.Close()
+	client := rest.NewClient(rest.WithClientMiddleware(Transport("")), rest.WithMaxAttempts(1))
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	resp, err := client.Do(req)
+	is.NoError(err)
+	defer resp.Body.Close()
+	body := &bytes.Buffer{}
+	_, _ = body.ReadFrom(resp.Body)
+	is.Equal("acme", body.String())
+}
+
+type fakeQuery struct {
+	preds []func(*sql.Selector)
+}
+
+func (q *fakeQuery) WhereP(ps ...func(*sql.Selector)) { q.preds = append(q.preds, ps...) }
+
+type fakeMutation struct {
+	ent.Mutation
+	fakeQuery
+	op     ent.Op
+	fields map[string]ent.Value
+}
+
+func (m *fakeMutation) Op() ent.Op   { return m.op }
+func (m *fakeMutation) Type() string { return "Item" }
+func (m *fakeMutation) Field(name string) (ent.Value, bool) {
+	v, ok := m.fields[name]
+	return v, ok
+}
+func (m *fakeMutation) SetField(name string, value ent.Value) error {
+	m.fields[name] = value
+	return nil
+}
+
+func selectQuery(preds []func(*sql.Selector)) string {
+	s := sql.Select("*").From(sql.Table("items"))
+	for _, p := range preds {
+		p(s)
+	}
+	query, _ := s.Query()
+	return query
+}
+
+func TestEntIsolation(t *testing.T) {
+	is := assert.New(t)
+	ctx := NewContext(context.Background(), Tenant{ID: "acme"})
+
+	q := &fakeQuery{}
+	is.NoError(EntInterceptor("tenant_id").(ent.TraverseFunc).Traverse(ctx, q))
+	is.Equal("SELECT * FROM `items` WHERE `items`.`tenant_id` = ?", selectQuery(q.preds))
+	is.ErrorIs(EntInterceptor("tenant_id").(ent.TraverseFunc).Traverse(context.Background(), &fakeQuery{}), ErrTenantRequired)
+	is.NoError(EntInterceptor("tenant_id").(ent.TraverseFunc).Traverse(SkipIsolation(context.Background()), &fakeQuery{}))
+
+	mutate := EntHook("tenant_id")(ent.MutateFunc(func(ctx context.Context, m ent.Mutation) (ent.Value, error) {
+		return nil, nil
+	}))
+	create := &fakeMutation{op: ent.OpCreate, fields: map[string]ent.Value{}}
+	_, err := mutate.Mutate(ctx, create)
+	is.NoError(err)
+	is.Equal("acme", create.fields["tenant_id"])
+
+	update := &fakeMutation{op: ent.OpUpdate, fields: map[string]ent.Value{}}
+	_, err = mutate.Mutate(ctx, update)
+	is.NoError(err)
+	is.Len(update.preds, 1)
+
+	_, err = mutate.Mutate(ctx, &fakeMutation{op: ent.OpUpdateOne, fields: map[string]ent.Value{"tenant_id": "globex"}})
+	is.ErrorIs(err, ErrCrossTenant)
+}
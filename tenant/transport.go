@@ -0,0 +1,39 @@
+// Package tenant is implements multi-tenancy context propagation and tenant isolation.
+// # This manifest was generated by ymir. DO NOT EDIT.
+package tenant
+
+import (
+	"net/http"
+
+	"github.com/kubuskotak/asgard/rest"
+)
+
+// Transport sets up a round tripper to propagate the tenant ID of request context into header,
+// HeaderTenantID is used when header is empty, e.g. rest.WithClientMiddleware(tenant.Transport("")).
+func Transport(header string) rest.Tripperware {
+	if header == "" {
+		header = HeaderTenantID
+	}
+	return func(next http.RoundTripper) http.RoundTripper {
+		if next == nil {
+			next = http.DefaultTransport
+		}
+		return &tenantTransport{next: next, header: header}
+	}
+}
+
+type tenantTransport struct {
+	next   http.RoundTripper
+	header string
+}
+
+// RoundTrip sets the tenant header on the clone of request.
+func (t *tenantTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	id := IDFromContext(r.Context())
+	if id == "" || r.Header.Get(t.header) == id {
+		return t.next.RoundTrip(r)
+	}
+	req := r.Clone(r.Context())
+	req.Header.Set(t.header, id)
+	return t.next.RoundTrip(req)
+}
@@ -14,6 +14,7 @@ type config struct {
 	TracerProvider    otelTrace.TracerProvider
 	Propagators       propagation.TextMapPropagator
 	spanNameFormatter func(string, *http.Request) string
+	capturedHeaders   []string
 }
 
 // Option specifies instrumentation configuration options.
@@ -57,3 +58,12 @@ func WithSpanNameFormatter(fn func(routeName string, r *http.Request) string) Op
 		cfg.spanNameFormatter = fn
 	})
 }
+
+// WithCapturedHeaders specifies the allowlist of request and response headers
+// recorded as span attributes by Transport. Nothing is captured by default,
+// so sensitive headers are never leaked unless they are listed.
+func WithCapturedHeaders(headers ...string) Option {
+	return optionFunc(func(cfg *config) {
+		cfg.capturedHeaders = append(cfg.capturedHeaders, headers...)
+	})
+}
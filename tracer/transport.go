@@ -0,0 +1,141 @@
+// Package tracer is implements an adapter to talks low-level trace observability.
+// # This manifest was generated by ymir. DO NOT EDIT.
+package tracer
+
+import (
+	"io"
+	"net/http"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semConv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/semconv/v1.17.0/httpconv"
+	otelTrace "go.opentelemetry.io/otel/trace"
+)
+
+const (
+	transportTracerName = "otelHttpClient"
+)
+
+// Transport sets up a round tripper to start client span of the outgoing
+// requests and inject the trace context into the request headers.
+// The span is ended once the response body is closed.
+func Transport(opts ...Option) func(next http.RoundTripper) http.RoundTripper {
+	cfg := config{}
+	for _, opt := range opts {
+		opt.apply(&cfg)
+	}
+	if cfg.TracerProvider == nil {
+		cfg.TracerProvider = otel.GetTracerProvider()
+	}
+	tracer := cfg.TracerProvider.Tracer(
+		transportTracerName,
+		otelTrace.WithInstrumentationVersion("v1.0.0"),
+	)
+	if cfg.Propagators == nil {
+		cfg.Propagators = otel.GetTextMapPropagator()
+	}
+	if cfg.spanNameFormatter == nil {
+		cfg.spanNameFormatter = defaultClientSpanNameFunc
+	}
+	return func(next http.RoundTripper) http.RoundTripper {
+		if next == nil {
+			next = http.DefaultTransport
+		}
+		return &tracingTransport{
+			next:              next,
+			tracer:            tracer,
+			propagators:       cfg.Propagators,
+			spanNameFormatter: cfg.spanNameFormatter,
+			capturedHeaders:   cfg.capturedHeaders,
+		}
+	}
+}
+
+type tracingTransport struct {
+	next              http.RoundTripper
+	tracer            otelTrace.Tracer
+	propagators       propagation.TextMapPropagator
+	spanNameFormatter func(string, *http.Request) string
+	capturedHeaders   []string
+}
+
+// defaultClientSpanNameFunc names the client span by http method.
+func defaultClientSpanNameFunc(_ string, r *http.Request) string {
+	return addPrefixToSpanName(true, "HTTP", r.Method)
+}
+
+// RoundTrip implements the http.RoundTripper interface. It does the actual
+// tracing of the outgoing request.
+func (t *tracingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	opts := []otelTrace.SpanStartOption{
+		otelTrace.WithAttributes(httpconv.ClientRequest(r)...),
+		otelTrace.WithSpanKind(otelTrace.SpanKindClient),
+	}
+	if headers := t.captured(r.Header); len(headers) > 0 {
+		opts = append(opts, otelTrace.WithAttributes(httpconv.RequestHeader(headers)...))
+	}
+	ctx, span := t.tracer.Start(r.Context(), t.spanNameFormatter(r.URL.Path, r), opts...)
+
+	r2 := r.Clone(ctx)
+	t.propagators.Inject(ctx, propagation.HeaderCarrier(r2.Header))
+	resp, err := t.next.RoundTrip(r2)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		span.End()
+		return nil, err
+	}
+	span.SetAttributes(semConv.HTTPStatusCode(resp.StatusCode))
+	if headers := t.captured(resp.Header); len(headers) > 0 {
+		span.SetAttributes(httpconv.ResponseHeader(headers)...)
+	}
+	span.SetStatus(httpconv.ClientStatus(resp.StatusCode))
+	if resp.Body == nil || resp.Body == http.NoBody {
+		span.End()
+		return resp, nil
+	}
+	resp.Body = &spanBody{ReadCloser: resp.Body, span: span}
+	return resp, nil
+}
+
+// captured returns the allowlisted headers only.
+func (t *tracingTransport) captured(h http.Header) http.Header {
+	if len(t.capturedHeaders) < 1 {
+		return nil
+	}
+	headers := http.Header{}
+	for _, name := range t.capturedHeaders {
+		if values := h.Values(name); len(values) > 0 {
+			headers[http.CanonicalHeaderKey(name)] = values
+		}
+	}
+	return headers
+}
+
+// spanBody ends the client span once the response body is closed.
+type spanBody struct {
+	io.ReadCloser
+	span otelTrace.Span
+	once sync.Once
+}
+
+// Read records the read error into the span.
+func (b *spanBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil && err != io.EOF {
+		b.span.RecordError(err)
+	}
+	return n, err
+}
+
+// Close closes the body and ends the span.
+func (b *spanBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() {
+		b.span.End()
+	})
+	return err
+}
@@ -0,0 +1,119 @@
+package tracer
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdkTrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	otelTrace "go.opentelemetry.io/otel/trace"
+)
+
+type roundTripFunc func(r *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func spanAttributes(span sdkTrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attrs := make(map[attribute.Key]attribute.Value)
+	for _, kv := range span.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	return attrs
+}
+
+func TestTransport(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		code, _ := strconv.Atoi(r.URL.Query().Get("code"))
+		w.Header().Set("X-Request-Id", "req-1")
+		w.Header().Set("X-Traceparent", r.Header.Get("Traceparent"))
+		w.WriteHeader(code)
+		if code != http.StatusNoContent {
+			_, _ = w.Write([]byte("ok"))
+		}
+	}))
+	defer srv.Close()
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdkTrace.NewTracerProvider(sdkTrace.WithSpanProcessor(recorder))
+	client := &http.Client{Transport: Transport(
+		WithTracerProvider(provider),
+		WithPropagators(propagation.TraceContext{}),
+		WithCapturedHeaders("X-Tenant-Id", "x-request-id"),
+	)(nil)}
+
+	t.Run("injects traceparent and ends on close", func(t *testing.T) {
+		is := require.New(t)
+		req, _ := http.NewRequest(http.MethodGet, srv.URL+"?code=200", http.NoBody)
+		req.Header.Set("X-Tenant-Id", "acme")
+		req.Header.Set("Authorization", "secret")
+		resp, err := client.Do(req)
+		is.NoError(err)
+		is.Empty(recorder.Ended(), "the span is open until the body is closed")
+		is.Empty(req.Header.Get("Traceparent"), "the caller request is not mutated")
+
+		b, err := io.ReadAll(resp.Body)
+		is.NoError(err)
+		is.Equal("ok", string(b))
+		is.NoError(resp.Body.Close())
+		is.NoError(resp.Body.Close())
+		is.Len(recorder.Ended(), 1)
+
+		span := recorder.Ended()[0]
+		is.Equal("HTTP GET", span.Name())
+		is.Equal(otelTrace.SpanKindClient, span.SpanKind())
+		is.Equal(codes.Unset, span.Status().Code)
+		is.Equal("00-"+span.SpanContext().TraceID().String()+"-"+span.SpanContext().SpanID().String()+"-01",
+			resp.Header.Get("X-Traceparent"))
+		attrs := spanAttributes(span)
+		is.Equal(int64(200), attrs["http.status_code"].AsInt64())
+		is.Equal([]string{"acme"}, attrs["http.request.header.x_tenant_id"].AsStringSlice())
+		is.Equal([]string{"req-1"}, attrs["http.response.header.x_request_id"].AsStringSlice())
+		for key := range attrs {
+			is.NotContains(string(key), "authorization")
+		}
+	})
+
+	t.Run("5xx sets error status", func(t *testing.T) {
+		is := require.New(t)
+		resp, err := client.Get(srv.URL + "?code=503")
+		is.NoError(err)
+		is.NoError(resp.Body.Close())
+		span := recorder.Ended()[len(recorder.Ended())-1]
+		is.Equal(codes.Error, span.Status().Code)
+		is.Equal(int64(503), spanAttributes(span)["http.status_code"].AsInt64())
+	})
+
+	t.Run("response without body ends the span", func(t *testing.T) {
+		is := require.New(t)
+		ended := len(recorder.Ended())
+		resp, err := client.Get(srv.URL + "?code=204")
+		is.NoError(err)
+		is.Equal(http.NoBody, resp.Body)
+		is.Len(recorder.Ended(), ended+1)
+	})
+
+	t.Run("transport error is recorded", func(t *testing.T) {
+		is := require.New(t)
+		rt := Transport(WithTracerProvider(provider))(roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			return nil, errors.New("connection refused")
+		}))
+		req, _ := http.NewRequest(http.MethodPost, "http://downstream/items", http.NoBody)
+		_, err := rt.RoundTrip(req)
+		is.EqualError(err, "connection refused")
+		span := recorder.Ended()[len(recorder.Ended())-1]
+		is.Equal("HTTP POST", span.Name())
+		is.Equal(codes.Error, span.Status().Code)
+		is.Equal("connection refused", span.Status().Description)
+		is.Len(span.Events(), 1)
+		is.Equal("exception", span.Events()[0].Name)
+	})
+}
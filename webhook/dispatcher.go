@@ -0,0 +1,312 @@
+// Package webhook is implements the outbound webhook delivery of signed events.
+// # This manifest was generated by ymir. DO NOT EDIT.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/kubuskotak/asgard/internal/xcontext"
+	"github.com/kubuskotak/asgard/rest"
+)
+
+const (
+	tracerName = "otelWebhook"
+	meterName  = "webhook"
+)
+
+// Option is the option of Dispatcher.
+type Option = func(d *Dispatcher) error
+
+// WithClient will send the webhooks by client, the client of 10s timeout by default.
+func WithClient(client *http.Client) Option {
+	return func(d *Dispatcher) error {
+		if client == nil {
+			return errors.New("webhook: client is nil")
+		}
+		d.client = client
+		return nil
+	}
+}
+
+// WithMaxAttempts will mark the delivery dead after n failed attempts, 8 by default.
+func WithMaxAttempts(n int) Option {
+	return func(d *Dispatcher) error {
+		if n < 1 {
+			return errors.New("webhook: max attempts must be positive")
+		}
+		d.maxAttempts = n
+		return nil
+	}
+}
+
+// WithBackoff will delay the retry attempt n by base*2^(n-1) with jitter up to max, 10s and 1h by default.
+func WithBackoff(base, max time.Duration) Option {
+	return func(d *Dispatcher) error {
+		if base <= 0 || max < base {
+			return errors.New("webhook: backoff requires 0 < base <= max")
+		}
+		d.baseDelay, d.maxDelay = base, max
+		return nil
+	}
+}
+
+// WithPollInterval will poll the due deliveries every interval, 5s by default.
+func WithPollInterval(interval time.Duration) Option {
+	return func(d *Dispatcher) error {
+		if interval <= 0 {
+			return errors.New("webhook: poll interval must be positive")
+		}
+		d.interval = interval
+		return nil
+	}
+}
+
+// WithBatchSize will claim up to n due deliveries per poll, 50 by default.
+func WithBatchSize(n int) Option {
+	return func(d *Dispatcher) error {
+		if n < 1 {
+			return errors.New("webhook: batch size must be positive")
+		}
+		d.batchSize = n
+		return nil
+	}
+}
+
+// WithConcurrency will send up to n deliveries at once, 8 by default.
+func WithConcurrency(n int) Option {
+	return func(d *Dispatcher) error {
+		if n < 1 {
+			return errors.New("webhook: concurrency must be positive")
+		}
+		d.concurrency = n
+		return nil
+	}
+}
+
+// WithLease will hide the claimed deliveries from the other dispatchers during d, 1m by default.
+// The lease should outlast the client timeout, the expired lease is claimed again.
+func WithLease(lease time.Duration) Option {
+	return func(d *Dispatcher) error {
+		if lease <= 0 {
+			return errors.New("webhook: lease must be positive")
+		}
+		d.lease = lease
+		return nil
+	}
+}
+
+// Dispatcher sends the due deliveries of Store, several dispatchers may share one store.
+type Dispatcher struct {
+	store       *Store
+	client      *http.Client
+	maxAttempts int
+	baseDelay   time.Duration
+	maxDelay    time.Duration
+	interval    time.Duration
+	batchSize   int
+	concurrency int
+	lease       time.Duration
+	tracer      trace.Tracer
+
+	once       sync.Once
+	deliveries metric.Int64Counter
+	duration   metric.Int64Histogram
+}
+
+// NewDispatcher creates the dispatcher of store.
+func NewDispatcher(store *Store, opts ...Option) *Dispatcher {
+	d := &Dispatcher{
+		store:       store,
+		client:      &http.Client{Timeout: 10 * time.Second},
+		maxAttempts: 8,
+		baseDelay:   10 * time.Second,
+		maxDelay:    time.Hour,
+		interval:    5 * time.Second,
+		batchSize:   50,
+		concurrency: 8,
+		lease:       time.Minute,
+		tracer:      otel.GetTracerProvider().Tracer(tracerName, trace.WithInstrumentationVersion("v1.0.0")),
+	}
+	for _, opt := range opts {
+		if err := opt(d); err != nil {
+			panic(err)
+		}
+	}
+	return d
+}
+
+// Run sends the due deliveries every poll interval until ctx is done, e.g.
+//
+//	go dispatcher.Run(ctx)
+func (d *Dispatcher) Run(ctx context.Context) error {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+	for {
+		n, err := d.RunOnce(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Error().Err(err).Msg("webhook dispatcher")
+		}
+		// the full batch means more deliveries are due.
+		if n == d.batchSize && err == nil {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce claims the due deliveries, sends them and returns their count.
+func (d *Dispatcher) RunOnce(ctx context.Context) (int, error) {
+	attempts, err := d.store.claim(ctx, time.Now().UTC(), d.lease, d.batchSize)
+	if err != nil {
+		return 0, err
+	}
+	var (
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, d.concurrency)
+	)
+	for _, a := range attempts {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(a attempt) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			d.deliver(ctx, a)
+		}(a)
+	}
+	wg.Wait()
+	return len(attempts), nil
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, a attempt) {
+	d.once.Do(d.instruments)
+	ctx, span := d.tracer.Start(ctx, "webhook "+a.Event.Type,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("webhook.event.id", a.Event.ID),
+			attribute.String("webhook.event.type", a.Event.Type),
+			attribute.String("webhook.delivery.id", a.Delivery.ID),
+			attribute.String("webhook.subscription.id", a.Subscription.ID),
+			attribute.Int("webhook.attempt", a.Delivery.Attempts+1),
+		))
+	defer span.End()
+
+	start := time.Now()
+	code, err := d.send(ctx, a)
+	elapsed := time.Since(start)
+
+	del := a.Delivery
+	now := time.Now().UTC()
+	del.Attempts++
+	del.LastStatusCode, del.LastError, del.UpdatedAt = code, "", now
+	switch {
+	case err == nil:
+		del.Status, del.DeliveredAt = StatusDelivered, &now
+	case errors.Is(err, ErrSubscriptionInactive) || del.Attempts >= d.maxAttempts:
+		del.Status, del.LastError = StatusDead, err.Error()
+	default:
+		del.Status, del.LastError = StatusRetrying, err.Error()
+		del.NextAttemptAt = now.Add(d.backoff(del.Attempts))
+	}
+	if code > 0 {
+		span.SetAttributes(semconv.HTTPStatusCode(code))
+	}
+	span.SetAttributes(attribute.String("webhook.delivery.status", string(del.Status)))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	// the result outlives the cancellation of dispatcher.
+	switch rErr := d.store.record(xcontext.WithoutCancel(ctx), del, a.Lease); {
+	case errors.Is(rErr, ErrLeaseExpired):
+		log.Warn().Err(rErr).Str("webhook.delivery.id", del.ID).Msg("webhook delivery result is dropped")
+	case rErr != nil:
+		log.Error().Err(rErr).Str("webhook.delivery.id", del.ID).Msg("webhook delivery")
+	}
+
+	attrs := metric.WithAttributes(
+		attribute.String("webhook.event.type", a.Event.Type),
+		attribute.String("webhook.delivery.status", string(del.Status)),
+	)
+	if d.deliveries != nil {
+		d.deliveries.Add(ctx, 1, attrs)
+	}
+	if d.duration != nil {
+		d.duration.Record(ctx, elapsed.Milliseconds(), attrs)
+	}
+}
+
+// send posts the signed event to the subscription, 2xx response acknowledges it.
+func (d *Dispatcher) send(ctx context.Context, a attempt) (int, error) {
+	if !a.Subscription.Active {
+		return 0, ErrSubscriptionInactive
+	}
+	body, err := json.Marshal(a.Event)
+	if err != nil {
+		return 0, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.Subscription.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set(rest.HeaderContentType.String(), rest.MIMEApplicationJSON.String())
+	req.Header.Set(HeaderID, a.Event.ID)
+	req.Header.Set(HeaderEvent, a.Event.Type)
+	req.Header.Set(HeaderSignature, Sign(a.Subscription.Secret, time.Now(), body))
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return resp.StatusCode, fmt.Errorf("webhook: unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff returns exponential delay of attempt with equal jitter.
+func (d *Dispatcher) backoff(attempt int) time.Duration {
+	delay := d.baseDelay << uint(attempt-1)
+	if delay <= 0 || delay > d.maxDelay {
+		delay = d.maxDelay
+	}
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1)) //nolint:gosec // jitter does not need crypto random.
+}
+
+func (d *Dispatcher) instruments() {
+	meter := otel.Meter(meterName)
+	var err error
+	d.deliveries, err = meter.Int64Counter("webhook.deliveries",
+		metric.WithDescription("Number of webhook delivery attempts by resulting status"))
+	if err != nil {
+		log.Error().Err(err).Msg("webhook metrics")
+	}
+	d.duration, err = meter.Int64Histogram("webhook.delivery.duration",
+		metric.WithDescription("Duration of webhook delivery attempts"),
+		metric.WithUnit("ms"))
+	if err != nil {
+		log.Error().Err(err).Msg("webhook metrics")
+	}
+}
@@ -0,0 +1,94 @@
+// Package webhook is implements the outbound webhook delivery of signed events.
+// # This manifest was generated by ymir. DO NOT EDIT.
+package webhook
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/kubuskotak/asgard/rest"
+)
+
+// RequestDeliveries request of listing deliveries.
+type RequestDeliveries struct {
+	Status         Status `schema:"status" json:"-" validate:"omitempty,oneof=pending retrying delivered dead"`
+	EventID        string `schema:"event_id" json:"-"`
+	SubscriptionID string `schema:"subscription_id" json:"-"`
+	Limit          int    `schema:"limit" json:"-" validate:"omitempty,min=1,max=1000"`
+}
+
+// RequestDelivery request of delivery resource.
+type RequestDelivery struct {
+	ID string `schema:"id" json:"-" validate:"required"`
+}
+
+// RequestEvent request of event resource.
+type RequestEvent struct {
+	ID string `schema:"id" json:"-" validate:"required"`
+}
+
+// RegisterRoutes registers the delivery resources under prefix on router, they should be guarded
+// by the admin authentication:
+//   - GET {prefix}/deliveries, the deliveries filtered by status, event_id and subscription_id;
+//   - GET {prefix}/deliveries/{id}, the delivery;
+//   - POST {prefix}/deliveries/{id}/replay, the replay of finished delivery;
+//   - POST {prefix}/events/{id}/replay, the replay of finished deliveries of event.
+func (s *Store) RegisterRoutes(rt *rest.Router, prefix string, opts ...rest.RouteOption) {
+	prefix = strings.TrimSuffix(prefix, "/")
+	routeOpts := func(summary string) []rest.RouteOption {
+		return append([]rest.RouteOption{rest.WithSummary(summary), rest.WithTags("webhooks")}, opts...)
+	}
+	rest.Get[RequestDeliveries, []Delivery](rt, prefix+"/deliveries",
+		func(w http.ResponseWriter, r *http.Request) ([]Delivery, error) {
+			req, err := rest.GetBind[RequestDeliveries](r)
+			if err != nil {
+				return nil, rest.ErrBadRequest(w, r, err)
+			}
+			return s.Deliveries(r.Context(), DeliveryFilter{
+				Status:         req.Status,
+				EventID:        req.EventID,
+				SubscriptionID: req.SubscriptionID,
+				Limit:          req.Limit,
+			})
+		}, routeOpts("list webhook deliveries")...)
+	rest.Get[RequestDelivery, Delivery](rt, prefix+"/deliveries/{id}",
+		func(w http.ResponseWriter, r *http.Request) (Delivery, error) {
+			req, err := rest.GetBind[RequestDelivery](r)
+			if err != nil {
+				return Delivery{}, rest.ErrBadRequest(w, r, err)
+			}
+			d, err := s.Delivery(r.Context(), req.ID)
+			if errors.Is(err, ErrDeliveryNotFound) {
+				return d, rest.ErrNotFound(w, r, err)
+			}
+			return d, err
+		}, routeOpts("webhook delivery")...)
+	rest.Post[RequestDelivery, Delivery](rt, prefix+"/deliveries/{id}/replay",
+		func(w http.ResponseWriter, r *http.Request) (Delivery, error) {
+			req, err := rest.GetBind[RequestDelivery](r)
+			if err != nil {
+				return Delivery{}, rest.ErrBadRequest(w, r, err)
+			}
+			d, err := s.Replay(r.Context(), req.ID)
+			switch {
+			case errors.Is(err, ErrDeliveryNotFound):
+				return d, rest.ErrNotFound(w, r, err)
+			case errors.Is(err, ErrDeliveryPending):
+				return d, rest.ErrStatusConflict(w, r, err)
+			}
+			return d, err
+		}, routeOpts("replay webhook delivery")...)
+	rest.Post[RequestEvent, []Delivery](rt, prefix+"/events/{id}/replay",
+		func(w http.ResponseWriter, r *http.Request) ([]Delivery, error) {
+			req, err := rest.GetBind[RequestEvent](r)
+			if err != nil {
+				return nil, rest.ErrBadRequest(w, r, err)
+			}
+			deliveries, err := s.ReplayEvent(r.Context(), req.ID)
+			if errors.Is(err, ErrEventNotFound) {
+				return nil, rest.ErrNotFound(w, r, err)
+			}
+			return deliveries, err
+		}, routeOpts("replay webhook event")...)
+}
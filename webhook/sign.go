@@ -0,0 +1,93 @@
+// Package webhook is implements the outbound webhook delivery of signed events.
+// # This manifest was generated by ymir. DO NOT EDIT.
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Headers of webhook request.
+const (
+	// HeaderID is the event id, receivers use it to drop the duplicated deliveries.
+	HeaderID = "Webhook-Id"
+	// HeaderEvent is the event type.
+	HeaderEvent = "Webhook-Event"
+	// HeaderSignature is the signature of body, "t=<unix timestamp>,v1=<hex hmac-sha256>".
+	HeaderSignature = "Webhook-Signature"
+)
+
+var (
+	// ErrInvalidSignature is define error when the signature does not match the body.
+	ErrInvalidSignature = errors.New("webhook signature is invalid")
+	// ErrSignatureExpired is define error when the signature timestamp is out of tolerance.
+	ErrSignatureExpired = errors.New("webhook signature is expired")
+)
+
+// NewSecret returns the random secret of subscription.
+func NewSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+// Sign returns the signature header of body at timestamp t, the HMAC-SHA256 is computed over "<t>.<body>".
+func Sign(secret string, t time.Time, body []byte) string {
+	ts := strconv.FormatInt(t.Unix(), 10)
+	return "t=" + ts + ",v1=" + signature(secret, ts, body)
+}
+
+// Verify checks the signature header of body by one of secrets, several secrets allow the rotation.
+// The timestamp must be within tolerance of now, tolerance 0 skips the check.
+func Verify(header string, body []byte, tolerance time.Duration, secrets ...string) error {
+	var (
+		ts         string
+		signatures []string
+	)
+	for _, part := range strings.Split(header, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "t":
+			ts = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil || len(signatures) == 0 {
+		return ErrInvalidSignature
+	}
+	if tolerance > 0 {
+		if age := time.Since(time.Unix(unix, 0)); age > tolerance || age < -tolerance {
+			return ErrSignatureExpired
+		}
+	}
+	for _, secret := range secrets {
+		expected := signature(secret, ts, body)
+		for _, sig := range signatures {
+			if hmac.Equal([]byte(expected), []byte(sig)) {
+				return nil
+			}
+		}
+	}
+	return ErrInvalidSignature
+}
+
+func signature(secret, ts string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
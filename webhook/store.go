@@ -0,0 +1,472 @@
+// Package webhook is implements the outbound webhook delivery of signed events.
+// # This manifest was generated by ymir. DO NOT EDIT.
+package webhook
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/kubuskotak/asgard/security"
+)
+
+const sqliteSchema = `CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+	id         TEXT PRIMARY KEY,
+	url        TEXT NOT NULL,
+	secret     TEXT NOT NULL,
+	events     TEXT NOT NULL,
+	active     INTEGER NOT NULL DEFAULT 1,
+	created_at INTEGER NOT NULL,
+	updated_at INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS webhook_events (
+	id         TEXT PRIMARY KEY,
+	type       TEXT NOT NULL,
+	payload    BLOB NOT NULL,
+	created_at INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+	id               TEXT PRIMARY KEY,
+	event_id         TEXT NOT NULL REFERENCES webhook_events (id) ON DELETE CASCADE,
+	subscription_id  TEXT NOT NULL REFERENCES webhook_subscriptions (id) ON DELETE CASCADE,
+	status           TEXT NOT NULL,
+	attempts         INTEGER NOT NULL DEFAULT 0,
+	next_attempt_at  INTEGER NOT NULL,
+	lease_until      INTEGER NOT NULL DEFAULT 0,
+	last_status_code INTEGER NOT NULL DEFAULT 0,
+	last_error       TEXT NOT NULL DEFAULT '',
+	delivered_at     INTEGER,
+	created_at       INTEGER NOT NULL,
+	updated_at       INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS webhook_deliveries_due ON webhook_deliveries (status, next_attempt_at);
+CREATE INDEX IF NOT EXISTS webhook_deliveries_event ON webhook_deliveries (event_id);`
+
+const deliveryColumns = `d.id, d.event_id, e.type, d.subscription_id, d.status, d.attempts, d.next_attempt_at,
+	d.last_status_code, d.last_error, d.delivered_at, d.created_at, d.updated_at`
+
+// Execer is the database handle of the outbox writes, it is satisfied by *sql.DB, *sql.Tx and *sql.Conn.
+type Execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// DeliveryFilter filters the listed deliveries, the zero value lists the latest deliveries.
+type DeliveryFilter struct {
+	Status         Status
+	EventID        string
+	SubscriptionID string
+	Limit          int
+}
+
+// Store is the transactional outbox of webhook in sqlite database, e.g. opened by the sqlite3 driver
+// of asgard/sqlite. sqlite allows one writer, so db should wait on lock by busy_timeout pragma
+// or limit its open connections.
+type Store struct {
+	db *sql.DB
+}
+
+// NewStore creates the store and migrates the webhook tables.
+func NewStore(ctx context.Context, db *sql.DB) (*Store, error) {
+	if _, err := db.ExecContext(ctx, sqliteSchema); err != nil {
+		return nil, err
+	}
+	return &Store{db: db}, nil
+}
+
+// CreateSubscription stores the active subscription, its id and secret are generated when they are empty.
+func (s *Store) CreateSubscription(ctx context.Context, sub Subscription) (Subscription, error) {
+	if sub.URL == "" || len(sub.Events) == 0 {
+		return Subscription{}, ErrInvalidSubscription
+	}
+	var err error
+	if sub.ID == "" {
+		if sub.ID, err = security.GenID(); err != nil {
+			return Subscription{}, err
+		}
+	}
+	if sub.Secret == "" {
+		if sub.Secret, err = NewSecret(); err != nil {
+			return Subscription{}, err
+		}
+	}
+	now := time.Now().UTC()
+	sub.Active, sub.CreatedAt, sub.UpdatedAt = true, now, now
+	_, err = s.db.ExecContext(ctx, `INSERT INTO webhook_subscriptions
+		(id, url, secret, events, active, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		sub.ID, sub.URL, sub.Secret, strings.Join(sub.Events, ","), sub.Active,
+		sub.CreatedAt.UnixNano(), sub.UpdatedAt.UnixNano())
+	if err != nil {
+		return Subscription{}, err
+	}
+	return sub, nil
+}
+
+// UpdateSubscription replaces url, secret, events and active of the subscription.
+func (s *Store) UpdateSubscription(ctx context.Context, sub Subscription) (Subscription, error) {
+	if sub.URL == "" || len(sub.Events) == 0 {
+		return Subscription{}, ErrInvalidSubscription
+	}
+	sub.UpdatedAt = time.Now().UTC()
+	res, err := s.db.ExecContext(ctx, `UPDATE webhook_subscriptions SET url = ?, secret = ?, events = ?,
+		active = ?, updated_at = ? WHERE id = ?`,
+		sub.URL, sub.Secret, strings.Join(sub.Events, ","), sub.Active, sub.UpdatedAt.UnixNano(), sub.ID)
+	if err != nil {
+		return Subscription{}, err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return Subscription{}, ErrSubscriptionNotFound
+	}
+	return s.Subscription(ctx, sub.ID)
+}
+
+// DeleteSubscription deletes the subscription and its deliveries.
+func (s *Store) DeleteSubscription(ctx context.Context, id string) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM webhook_subscriptions WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrSubscriptionNotFound
+	}
+	return nil
+}
+
+// Subscription returns the subscription.
+func (s *Store) Subscription(ctx context.Context, id string) (Subscription, error) {
+	subs, err := querySubscriptions(ctx, s.db, `WHERE id = ?`, id)
+	if err != nil {
+		return Subscription{}, err
+	}
+	if len(subs) == 0 {
+		return Subscription{}, ErrSubscriptionNotFound
+	}
+	return subs[0], nil
+}
+
+// Subscriptions returns all subscriptions.
+func (s *Store) Subscriptions(ctx context.Context) ([]Subscription, error) {
+	return querySubscriptions(ctx, s.db, `ORDER BY created_at`)
+}
+
+// Publish writes the event and one pending delivery per matching active subscription by ex. Pass the
+// *sql.Tx of business writes, so the event is committed or rolled back together with them, e.g.
+//
+//	tx, _ := db.BeginTx(ctx, nil)
+//	// ... business writes on tx
+//	if _, err := store.Publish(ctx, tx, "order.created", order); err != nil {
+//		return tx.Rollback()
+//	}
+//	return tx.Commit()
+func (s *Store) Publish(ctx context.Context, ex Execer, eventType string, payload any) (Event, error) {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return Event{}, err
+	}
+	id, err := security.GenID()
+	if err != nil {
+		return Event{}, err
+	}
+	now := time.Now().UTC()
+	event := Event{ID: id, Type: eventType, Payload: b, CreatedAt: now}
+	if _, err = ex.ExecContext(ctx, `INSERT INTO webhook_events (id, type, payload, created_at) VALUES (?, ?, ?, ?)`,
+		event.ID, event.Type, []byte(event.Payload), event.CreatedAt.UnixNano()); err != nil {
+		return Event{}, err
+	}
+	subs, err := querySubscriptions(ctx, ex, `WHERE active = 1`)
+	if err != nil {
+		return Event{}, err
+	}
+	for _, sub := range subs {
+		if !sub.Match(eventType) {
+			continue
+		}
+		deliveryID, err := security.GenID()
+		if err != nil {
+			return Event{}, err
+		}
+		if _, err = ex.ExecContext(ctx, `INSERT INTO webhook_deliveries
+			(id, event_id, subscription_id, status, next_attempt_at, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			deliveryID, event.ID, sub.ID, string(StatusPending), now.UnixNano(), now.UnixNano(), now.UnixNano()); err != nil {
+			return Event{}, err
+		}
+	}
+	return event, nil
+}
+
+// Event returns the event.
+func (s *Store) Event(ctx context.Context, id string) (Event, error) {
+	var (
+		event   Event
+		created int64
+	)
+	err := s.db.QueryRowContext(ctx, `SELECT id, type, payload, created_at FROM webhook_events WHERE id = ?`, id).
+		Scan(&event.ID, &event.Type, &event.Payload, &created)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Event{}, ErrEventNotFound
+	}
+	if err != nil {
+		return Event{}, err
+	}
+	event.CreatedAt = time.Unix(0, created).UTC()
+	return event, nil
+}
+
+// Delivery returns the delivery.
+func (s *Store) Delivery(ctx context.Context, id string) (Delivery, error) {
+	deliveries, err := s.queryDeliveries(ctx, `WHERE d.id = ?`, id)
+	if err != nil {
+		return Delivery{}, err
+	}
+	if len(deliveries) == 0 {
+		return Delivery{}, ErrDeliveryNotFound
+	}
+	return deliveries[0], nil
+}
+
+// Deliveries returns the deliveries of filter, the newest first.
+func (s *Store) Deliveries(ctx context.Context, filter DeliveryFilter) ([]Delivery, error) {
+	var (
+		where []string
+		args  []any
+	)
+	if filter.Status != "" {
+		where, args = append(where, "d.status = ?"), append(args, string(filter.Status))
+	}
+	if filter.EventID != "" {
+		where, args = append(where, "d.event_id = ?"), append(args, filter.EventID)
+	}
+	if filter.SubscriptionID != "" {
+		where, args = append(where, "d.subscription_id = ?"), append(args, filter.SubscriptionID)
+	}
+	query := ""
+	if len(where) > 0 {
+		query = "WHERE " + strings.Join(where, " AND ")
+	}
+	limit := filter.Limit
+	if limit <= 0 || limit > 1000 {
+		limit = 100
+	}
+	return s.queryDeliveries(ctx, query+` ORDER BY d.created_at DESC, d.id DESC LIMIT ?`, append(args, limit)...)
+}
+
+// Replay schedules the delivered or dead delivery again with fresh attempts.
+func (s *Store) Replay(ctx context.Context, id string) (Delivery, error) {
+	now := time.Now().UTC().UnixNano()
+	res, err := s.db.ExecContext(ctx, `UPDATE webhook_deliveries SET status = ?, attempts = 0, next_attempt_at = ?,
+		lease_until = 0, last_error = '', updated_at = ? WHERE id = ? AND status IN (?, ?)`,
+		string(StatusPending), now, now, id, string(StatusDelivered), string(StatusDead))
+	if err != nil {
+		return Delivery{}, err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		d, err := s.Delivery(ctx, id)
+		if err != nil {
+			return Delivery{}, err
+		}
+		return d, ErrDeliveryPending
+	}
+	return s.Delivery(ctx, id)
+}
+
+// ReplayEvent schedules the finished deliveries of event again, the pending ones are kept as is.
+func (s *Store) ReplayEvent(ctx context.Context, eventID string) ([]Delivery, error) {
+	if _, err := s.Event(ctx, eventID); err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC().UnixNano()
+	if _, err := s.db.ExecContext(ctx, `UPDATE webhook_deliveries SET status = ?, attempts = 0, next_attempt_at = ?,
+		lease_until = 0, last_error = '', updated_at = ? WHERE event_id = ? AND status IN (?, ?)`,
+		string(StatusPending), now, now, eventID, string(StatusDelivered), string(StatusDead)); err != nil {
+		return nil, err
+	}
+	return s.Deliveries(ctx, DeliveryFilter{EventID: eventID, Limit: 1000})
+}
+
+// DeleteDelivered deletes the events created before t whose deliveries are all finished.
+func (s *Store) DeleteDelivered(ctx context.Context, before time.Time) (int, error) {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM webhook_events WHERE created_at < ? AND NOT EXISTS (
+		SELECT 1 FROM webhook_deliveries d WHERE d.event_id = webhook_events.id AND d.status IN (?, ?))`,
+		before.UnixNano(), string(StatusPending), string(StatusRetrying))
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+// attempt is the claimed delivery with its event and subscription.
+type attempt struct {
+	Delivery     Delivery
+	Event        Event
+	Subscription Subscription
+	// Lease is the lease_until of claim, it guards the result of attempt.
+	Lease int64
+}
+
+// claim leases up to limit due deliveries until lease, so the other dispatchers skip them.
+// The delivery whose event or subscription can not be loaded is marked dead.
+func (s *Store) claim(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]attempt, error) {
+	until := now.Add(lease).UnixNano()
+	rows, err := s.db.QueryContext(ctx, `UPDATE webhook_deliveries SET lease_until = ? WHERE id IN (
+		SELECT id FROM webhook_deliveries WHERE status IN (?, ?) AND next_attempt_at <= ? AND lease_until <= ?
+		ORDER BY next_attempt_at LIMIT ?) RETURNING id`,
+		until, string(StatusPending), string(StatusRetrying), now.UnixNano(), now.UnixNano(), limit)
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err = rows.Scan(&id); err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	if err = rows.Close(); err != nil {
+		return nil, err
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	attempts := make([]attempt, 0, len(ids))
+	for _, id := range ids {
+		a, err := s.load(ctx, id)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			// the broken delivery would be claimed again forever.
+			if err = s.bury(ctx, id, until, err); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		a.Lease = until
+		attempts = append(attempts, a)
+	}
+	return attempts, nil
+}
+
+// load returns the delivery with its event and subscription.
+func (s *Store) load(ctx context.Context, id string) (attempt, error) {
+	var (
+		a       attempt
+		created int64
+		events  string
+		err     error
+	)
+	a.Delivery, err = s.Delivery(ctx, id)
+	if err != nil {
+		return attempt{}, err
+	}
+	err = s.db.QueryRowContext(ctx, `SELECT e.id, e.type, e.payload, e.created_at,
+		s.id, s.url, s.secret, s.events, s.active FROM webhook_deliveries d
+		JOIN webhook_events e ON e.id = d.event_id
+		JOIN webhook_subscriptions s ON s.id = d.subscription_id WHERE d.id = ?`, id).
+		Scan(&a.Event.ID, &a.Event.Type, &a.Event.Payload, &created,
+			&a.Subscription.ID, &a.Subscription.URL, &a.Subscription.Secret, &events, &a.Subscription.Active)
+	if errors.Is(err, sql.ErrNoRows) {
+		return attempt{}, ErrSubscriptionNotFound
+	}
+	if err != nil {
+		return attempt{}, err
+	}
+	a.Event.CreatedAt = time.Unix(0, created).UTC()
+	a.Subscription.Events = strings.Split(events, ",")
+	return a, nil
+}
+
+// bury marks the claimed delivery dead by cause.
+func (s *Store) bury(ctx context.Context, id string, lease int64, cause error) error {
+	now := time.Now().UTC().UnixNano()
+	_, err := s.db.ExecContext(ctx, `UPDATE webhook_deliveries SET status = ?, lease_until = 0, last_error = ?,
+		updated_at = ? WHERE id = ? AND lease_until = ?`, string(StatusDead), cause.Error(), now, id, lease)
+	return err
+}
+
+// record saves the attempt result of delivery and releases its lease, ErrLeaseExpired is returned
+// when the delivery is claimed again by another attempt.
+func (s *Store) record(ctx context.Context, d Delivery, lease int64) error {
+	res, err := s.db.ExecContext(ctx, `UPDATE webhook_deliveries SET status = ?, attempts = ?, next_attempt_at = ?,
+		lease_until = 0, last_status_code = ?, last_error = ?, delivered_at = ?, updated_at = ?
+		WHERE id = ? AND lease_until = ?`,
+		string(d.Status), d.Attempts, d.NextAttemptAt.UnixNano(), d.LastStatusCode, d.LastError,
+		unixNano(d.DeliveredAt), d.UpdatedAt.UnixNano(), d.ID, lease)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrLeaseExpired
+	}
+	return nil
+}
+
+func (s *Store) queryDeliveries(ctx context.Context, query string, args ...any) ([]Delivery, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+deliveryColumns+` FROM webhook_deliveries d
+		JOIN webhook_events e ON e.id = d.event_id `+query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	deliveries := make([]Delivery, 0)
+	for rows.Next() {
+		var (
+			d                     Delivery
+			status                string
+			next, created, update int64
+			delivered             sql.NullInt64
+		)
+		if err = rows.Scan(&d.ID, &d.EventID, &d.EventType, &d.SubscriptionID, &status, &d.Attempts, &next,
+			&d.LastStatusCode, &d.LastError, &delivered, &created, &update); err != nil {
+			return nil, err
+		}
+		d.Status = Status(status)
+		d.NextAttemptAt = time.Unix(0, next).UTC()
+		d.CreatedAt = time.Unix(0, created).UTC()
+		d.UpdatedAt = time.Unix(0, update).UTC()
+		if delivered.Valid {
+			t := time.Unix(0, delivered.Int64).UTC()
+			d.DeliveredAt = &t
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
+
+func querySubscriptions(ctx context.Context, ex Execer, query string, args ...any) ([]Subscription, error) {
+	rows, err := ex.QueryContext(ctx, `SELECT id, url, secret, events, active, created_at, updated_at
+		FROM webhook_subscriptions `+query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var subs []Subscription
+	for rows.Next() {
+		var (
+			sub              Subscription
+			events           string
+			created, updated int64
+		)
+		if err = rows.Scan(&sub.ID, &sub.URL, &sub.Secret, &events, &sub.Active, &created, &updated); err != nil {
+			return nil, err
+		}
+		sub.Events = strings.Split(events, ",")
+		sub.CreatedAt = time.Unix(0, created).UTC()
+		sub.UpdatedAt = time.Unix(0, updated).UTC()
+		subs = append(subs, sub)
+	}
+	return subs, rows.Err()
+}
+
+func unixNano(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return t.UnixNano()
+}
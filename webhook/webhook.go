@@ -0,0 +1,97 @@
+// Package webhook is implements the outbound webhook delivery of signed events.
+// # This manifest was generated by ymir. DO NOT EDIT.
+package webhook
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrSubscriptionNotFound is define error when the subscription is not found.
+	ErrSubscriptionNotFound = errors.New("webhook subscription is not found")
+	// ErrEventNotFound is define error when the event is not found.
+	ErrEventNotFound = errors.New("webhook event is not found")
+	// ErrDeliveryNotFound is define error when the delivery is not found.
+	ErrDeliveryNotFound = errors.New("webhook delivery is not found")
+	// ErrDeliveryPending is define error when the delivery is still scheduled and can not be replayed.
+	ErrDeliveryPending = errors.New("webhook delivery is still pending")
+	// ErrSubscriptionInactive is define error when the delivery targets the inactive subscription.
+	ErrSubscriptionInactive = errors.New("webhook subscription is inactive")
+	// ErrInvalidSubscription is define error of the subscription without url or events.
+	ErrInvalidSubscription = errors.New("webhook subscription requires url and events")
+	// ErrLeaseExpired is define error when the delivery is claimed again after its lease expired,
+	// the result of the stale attempt is dropped.
+	ErrLeaseExpired = errors.New("webhook delivery lease is expired")
+)
+
+// Status is the state of delivery.
+type Status string
+
+// Status of delivery.
+const (
+	// StatusPending is the delivery waiting for its first attempt.
+	StatusPending Status = "pending"
+	// StatusRetrying is the delivery waiting for the next attempt after a failure.
+	StatusRetrying Status = "retrying"
+	// StatusDelivered is the delivery acknowledged by 2xx response.
+	StatusDelivered Status = "delivered"
+	// StatusDead is the delivery which exhausts its attempts, it is sent again only by replay.
+	StatusDead Status = "dead"
+)
+
+// Done reports whether the delivery is not scheduled anymore.
+func (s Status) Done() bool {
+	return s == StatusDelivered || s == StatusDead
+}
+
+// Subscription is the endpoint receiving the events. Events are the event types, "*" matches every
+// type and "order.*" matches the types prefixed by "order.".
+type Subscription struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	Secret    string    `json:"-"`
+	Events    []string  `json:"events"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Match reports whether the subscription receives the event type.
+func (s Subscription) Match(eventType string) bool {
+	for _, pattern := range s.Events {
+		if pattern == "*" || pattern == eventType {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok && strings.HasPrefix(eventType, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Event is the published event, Payload is sent as data of the webhook body.
+type Event struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	Payload   json.RawMessage `json:"data"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// Delivery is the event sent to one subscription.
+type Delivery struct {
+	ID             string     `json:"id"`
+	EventID        string     `json:"event_id"`
+	EventType      string     `json:"event_type"`
+	SubscriptionID string     `json:"subscription_id"`
+	Status         Status     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at"`
+	LastStatusCode int        `json:"last_status_code,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
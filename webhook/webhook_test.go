@@ -0,0 +1,194 @@
+package webhook
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kubuskotak/asgard/rest"
+	"github.com/kubuskotak/asgard/rest/resttest"
+	_ "github.com/kubuskotak/asgard/sqlite"
+)
+
+func TestSignVerify(t *testing.T) {
+	is := assert.New(t)
+	body := []byte(`{"id":"1"}`)
+	header := Sign("new", time.Now(), body)
+	is.NoError(Verify(header, body, time.Minute, "old", "new"))
+	is.ErrorIs(Verify(header, body, time.Minute, "old"), ErrInvalidSignature)
+	is.ErrorIs(Verify(header, []byte(`{"id":"2"}`), time.Minute, "new"), ErrInvalidSignature)
+	is.ErrorIs(Verify(Sign("new", time.Now().Add(-time.Hour), body), body, time.Minute, "new"), ErrSignatureExpired)
+	is.ErrorIs(Verify("v1=abc", body, 0, "new"), ErrInvalidSignature)
+}
+
+func TestDispatcher(t *testing.T) {
+	is := assert.New(t)
+	ctx := context.Background()
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "webhook.db"))
+	is.NoError(err)
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	store, err := NewStore(ctx, db)
+	is.NoError(err)
+
+	var received atomic.Int32
+	ok, err := store.CreateSubscription(ctx, Subscription{Events: []string{"order.*"}})
+	is.ErrorIs(err, ErrInvalidSubscription)
+	okServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := Verify(r.Header.Get(HeaderSignature), body, time.Minute, ok.Secret); err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		received.Add(1)
+	}))
+	defer okServer.Close()
+	failServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failServer.Close()
+
+	ok, err = store.CreateSubscription(ctx, Subscription{URL: okServer.URL, Events: []string{"order.*"}})
+	is.NoError(err)
+	fail, err := store.CreateSubscription(ctx, Subscription{URL: failServer.URL, Events: []string{"*"}})
+	is.NoError(err)
+	_, err = store.CreateSubscription(ctx, Subscription{URL: okServer.URL, Events: []string{"invoice.paid"}})
+	is.NoError(err)
+
+	// the rolled back business write drops its event.
+	tx, err := db.BeginTx(ctx, nil)
+	is.NoError(err)
+	_, err = store.Publish(ctx, tx, "order.created", map[string]string{"id": "rolled-back"})
+	is.NoError(err)
+	is.NoError(tx.Rollback())
+	tx, err = db.BeginTx(ctx, nil)
+	is.NoError(err)
+	event, err := store.Publish(ctx, tx, "order.created", map[string]string{"id": "o-1"})
+	is.NoError(err)
+	is.NoError(tx.Commit())
+	deliveries, err := store.Deliveries(ctx, DeliveryFilter{})
+	is.NoError(err)
+	is.Len(deliveries, 2)
+	removed, err := store.DeleteDelivered(ctx, time.Now())
+	is.NoError(err)
+	is.Equal(0, removed)
+
+	d := NewDispatcher(store, WithBackoff(time.Millisecond, time.Millisecond), WithMaxAttempts(2))
+	n, err := d.RunOnce(ctx)
+	is.NoError(err)
+	is.Equal(2, n)
+	is.Equal(int32(1), received.Load())
+	retrying, _ := store.Deliveries(ctx, DeliveryFilter{SubscriptionID: fail.ID})
+	is.Equal(StatusRetrying, retrying[0].Status)
+	is.Equal(http.StatusInternalServerError, retrying[0].LastStatusCode)
+
+	time.Sleep(5 * time.Millisecond)
+	n, err = d.RunOnce(ctx)
+	is.NoError(err)
+	is.Equal(1, n)
+	n, _ = d.RunOnce(ctx)
+	is.Equal(0, n)
+
+	router := rest.NewRouter()
+	store.RegisterRoutes(router, "/webhooks")
+	h := resttest.New(t, router)
+	dead := resttest.Data[[]Delivery](h.Get("/webhooks/deliveries?status=dead").Do().Status(http.StatusOK))
+	is.Len(dead, 1)
+	is.Equal(fail.ID, dead[0].SubscriptionID)
+	is.Equal(2, dead[0].Attempts)
+	delivered := resttest.Data[[]Delivery](h.Get("/webhooks/deliveries?subscription_id=" + ok.ID).Do())
+	is.Equal(StatusDelivered, delivered[0].Status)
+	is.NotNil(delivered[0].DeliveredAt)
+	h.Get("/webhooks/deliveries?status=unknown").Do().ValidationError("Status")
+
+	replayed := resttest.Data[Delivery](h.Post("/webhooks/deliveries/" + dead[0].ID + "/replay").Do().Status(http.StatusOK))
+	is.Equal(StatusPending, replayed.Status)
+	is.Equal(0, replayed.Attempts)
+	h.Post("/webhooks/deliveries/" + dead[0].ID + "/replay").Do().Status(http.StatusConflict)
+	h.Post("/webhooks/deliveries/unknown/replay").Do().Status(http.StatusNotFound)
+
+	replayedEvent := resttest.Data[[]Delivery](h.Post("/webhooks/events/" + event.ID + "/replay").Do().Status(http.StatusOK))
+	is.Len(replayedEvent, 2)
+	h.Post("/webhooks/events/unknown/replay").Do().Status(http.StatusNotFound)
+	n, _ = d.RunOnce(ctx)
+	is.Equal(2, n)
+	is.Equal(int32(2), received.Load())
+
+	ok.Active = false
+	_, err = store.UpdateSubscription(ctx, ok)
+	is.NoError(err)
+	_, err = store.ReplayEvent(ctx, event.ID)
+	is.NoError(err)
+	_, _ = d.RunOnce(ctx)
+	inactive, _ := store.Deliveries(ctx, DeliveryFilter{SubscriptionID: ok.ID})
+	is.Equal(StatusDead, inactive[0].Status)
+	is.Equal(ErrSubscriptionInactive.Error(), inactive[0].LastError)
+
+	time.Sleep(5 * time.Millisecond)
+	_, _ = d.RunOnce(ctx)
+	removed, err = store.DeleteDelivered(ctx, time.Now())
+	is.NoError(err)
+	is.Equal(1, removed)
+	_, err = store.Event(ctx, event.ID)
+	is.ErrorIs(err, ErrEventNotFound)
+}
+
+func TestStoreLease(t *testing.T) {
+	is := assert.New(t)
+	ctx := context.Background()
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "webhook.db"))
+	is.NoError(err)
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	store, err := NewStore(ctx, db)
+	is.NoError(err)
+
+	sub, err := store.CreateSubscription(ctx, Subscription{URL: "http://localhost", Events: []string{"*"}})
+	is.NoError(err)
+	orphan, err := store.CreateSubscription(ctx, Subscription{URL: "http://localhost", Events: []string{"*"}})
+	is.NoError(err)
+	_, err = store.Publish(ctx, db, "order.created", map[string]string{"id": "o-1"})
+	is.NoError(err)
+	// the subscription is deleted without its deliveries.
+	_, err = db.ExecContext(ctx, `PRAGMA foreign_keys = OFF`)
+	is.NoError(err)
+	_, err = db.ExecContext(ctx, `DELETE FROM webhook_subscriptions WHERE id = ?`, orphan.ID)
+	is.NoError(err)
+
+	now := time.Now().UTC()
+	attempts, err := store.claim(ctx, now, time.Minute, 10)
+	is.NoError(err, "the broken delivery does not abort the batch")
+	is.Len(attempts, 1)
+	is.Equal(sub.ID, attempts[0].Subscription.ID)
+	dead, err := store.Deliveries(ctx, DeliveryFilter{SubscriptionID: orphan.ID})
+	is.NoError(err)
+	is.Len(dead, 1)
+	is.Equal(StatusDead, dead[0].Status)
+	is.Equal(ErrSubscriptionNotFound.Error(), dead[0].LastError)
+
+	// the expired lease is claimed again, the stale result is dropped.
+	stale := attempts[0]
+	attempts, err = store.claim(ctx, now.Add(2*time.Minute), time.Minute, 10)
+	is.NoError(err)
+	is.Len(attempts, 1)
+	stale.Delivery.Status, stale.Delivery.Attempts = StatusDelivered, 1
+	is.ErrorIs(store.record(ctx, stale.Delivery, stale.Lease), ErrLeaseExpired)
+	d, err := store.Delivery(ctx, stale.Delivery.ID)
+	is.NoError(err)
+	is.Equal(StatusPending, d.Status)
+
+	fresh := attempts[0]
+	fresh.Delivery.Status, fresh.Delivery.Attempts = StatusDelivered, 1
+	is.NoError(store.record(ctx, fresh.Delivery, fresh.Lease))
+	d, err = store.Delivery(ctx, fresh.Delivery.ID)
+	is.NoError(err)
+	is.Equal(StatusDelivered, d.Status)
+}